@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"time"
+
+	mainpb "practice/proto/gen"
+	userpb "practice/proto/gen/user"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func main() {
+	address := flag.String("addr", "localhost:50052", "server address")
+	userID := flag.String("user", "user-1", "user placing the orders")
+	flag.Parse()
+
+	conn, err := grpc.NewClient(*address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer conn.Close()
+	client := mainpb.NewGreeterClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	hello, err := client.SayHello(ctx, &mainpb.HelloRequest{Name: "Alice", Age: 30})
+	if err != nil {
+		log.Fatalln("SayHello:", err)
+	}
+	log.Println(hello.GetConfMessage())
+
+	user := &userpb.User{Id: *userID}
+	for _, city := range []string{"Berlin", "Paris", "Rome", "Madrid", "Vienna"} {
+		order, err := client.PlaceOrder(ctx, &mainpb.Order{
+			User:    user,
+			Address: &mainpb.Address{AddressLine: "Main Street 1", City: city, State: "EU"},
+		})
+		if err != nil {
+			log.Fatalln("PlaceOrder:", err)
+		}
+		log.Println("Placed", order.GetId(), "to", order.GetAddress().GetCity())
+	}
+
+	latest, err := client.Orders(ctx, user)
+	if err != nil {
+		log.Fatalln("Orders:", err)
+	}
+	log.Println("Latest order:", latest.GetId())
+
+	// Two per page, following next_page_token until it comes back empty
+	token := ""
+	for page := 1; ; page++ {
+		res, err := client.ListOrders(ctx, &mainpb.ListOrdersRequest{User: user, PageSize: 2, PageToken: token})
+		if err != nil {
+			log.Fatalln("ListOrders:", err)
+		}
+		for _, order := range res.GetOrders() {
+			log.Printf("Page %d: %s to %s", page, order.GetId(), order.GetAddress().GetCity())
+		}
+		if token = res.GetNextPageToken(); token == "" {
+			break
+		}
+	}
+
+	stream, err := client.StreamOrders(ctx, user)
+	if err != nil {
+		log.Fatalln("StreamOrders:", err)
+	}
+	for {
+		order, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalln("StreamOrders:", err)
+		}
+		log.Println("Streamed", order.GetId(), "to", order.GetAddress().GetCity())
+	}
+}
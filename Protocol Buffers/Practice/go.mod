@@ -0,0 +1,18 @@
+module practice
+
+go 1.24.2
+
+require (
+	google.golang.org/grpc v1.74.2
+	google.golang.org/protobuf v1.36.8
+	pkg v0.0.0-00010101000000-000000000000
+)
+
+require (
+	golang.org/x/net v0.40.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	golang.org/x/text v0.25.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250818200422-3122310a409c // indirect
+)
+
+replace pkg => ../../pkg
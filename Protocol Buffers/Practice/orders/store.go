@@ -0,0 +1,120 @@
+package orders
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	mainpb "practice/proto/gen"
+
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	ErrNoUser           = errors.New("orders: order has no user ID")
+	ErrInvalidPageToken = errors.New("orders: invalid page token")
+)
+
+// Store keeps the orders of every user in the order they were placed, it is safe for concurrent use
+type Store struct {
+	mu     sync.RWMutex
+	byUser map[string][]*mainpb.Order
+	nextID int
+}
+
+func NewStore() *Store {
+	return &Store{byUser: make(map[string][]*mainpb.Order)}
+}
+
+// Place stores a copy of order with a new ID under its user and returns that copy
+func (s *Store) Place(order *mainpb.Order) (*mainpb.Order, error) {
+	userID := order.GetUser().GetId()
+	if userID == "" {
+		return nil, ErrNoUser
+	}
+
+	placed := proto.Clone(order).(*mainpb.Order)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	placed.Id = fmt.Sprintf("order-%d", s.nextID)
+	s.byUser[userID] = append(s.byUser[userID], placed)
+	return proto.Clone(placed).(*mainpb.Order), nil
+}
+
+// Latest returns the order userID placed last
+func (s *Store) Latest(userID string) (*mainpb.Order, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	orders := s.byUser[userID]
+	if len(orders) == 0 {
+		return nil, false
+	}
+	return proto.Clone(orders[len(orders)-1]).(*mainpb.Order), true
+}
+
+// All returns the orders of userID, oldest first
+func (s *Store) All(userID string) []*mainpb.Order {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return cloneAll(s.byUser[userID])
+}
+
+/*
+Page returns up to size orders of userID, oldest first.
+- token is "" for the first page and the returned next for every later one, next is "" after the last page
+- Tokens only work for the user they were issued to
+- Orders are never deleted, so a token stays valid while new orders are placed
+*/
+func (s *Store) Page(userID string, size int, token string) (page []*mainpb.Order, next string, err error) {
+	offset := 0
+	if token != "" {
+		if offset, err = decodeToken(userID, token); err != nil {
+			return nil, "", err
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	orders := s.byUser[userID]
+	if offset > len(orders) {
+		return nil, "", ErrInvalidPageToken
+	}
+	end := min(offset+size, len(orders))
+	if end < len(orders) {
+		next = encodeToken(userID, end)
+	}
+	return cloneAll(orders[offset:end]), next, nil
+}
+
+func cloneAll(orders []*mainpb.Order) []*mainpb.Order {
+	clones := make([]*mainpb.Order, len(orders))
+	for i, order := range orders {
+		clones[i] = proto.Clone(order).(*mainpb.Order)
+	}
+	return clones
+}
+
+// Tokens are opaque to clients, inside they are the user ID and the offset of the next page
+func encodeToken(userID string, offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(userID + "\x00" + strconv.Itoa(offset)))
+}
+
+func decodeToken(userID, token string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, ErrInvalidPageToken
+	}
+	owner, offset, ok := strings.Cut(string(data), "\x00")
+	if !ok || owner != userID {
+		return 0, ErrInvalidPageToken
+	}
+	n, err := strconv.Atoi(offset)
+	if err != nil || n < 0 {
+		return 0, ErrInvalidPageToken
+	}
+	return n, nil
+}
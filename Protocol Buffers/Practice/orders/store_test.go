@@ -0,0 +1,71 @@
+package orders
+
+import (
+	"errors"
+	"testing"
+
+	mainpb "practice/proto/gen"
+	userpb "practice/proto/gen/user"
+)
+
+func place(t *testing.T, s *Store, userID, city string) *mainpb.Order {
+	t.Helper()
+	order, err := s.Place(&mainpb.Order{User: &userpb.User{Id: userID}, Address: &mainpb.Address{City: city}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+func TestStorePages(t *testing.T) {
+	s := NewStore()
+	for _, city := range []string{"Berlin", "Paris", "Rome", "Madrid", "Vienna"} {
+		place(t, s, "alice", city)
+	}
+	place(t, s, "bob", "Oslo")
+
+	var cities []string
+	token, pages := "", 0
+	for {
+		page, next, err := s.Page("alice", 2, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages++
+		for _, order := range page {
+			cities = append(cities, order.GetAddress().GetCity())
+		}
+		if next == "" {
+			break
+		}
+		token = next
+	}
+	if pages != 3 || len(cities) != 5 || cities[0] != "Berlin" || cities[4] != "Vienna" {
+		t.Errorf("got %v in %d pages; want alice's 5 orders oldest first in 3", cities, pages)
+	}
+
+	_, next, _ := s.Page("alice", 2, "")
+	if _, _, err := s.Page("bob", 2, next); !errors.Is(err, ErrInvalidPageToken) {
+		t.Errorf("alice's token for bob err = %v; want ErrInvalidPageToken", err)
+	}
+	if _, _, err := s.Page("alice", 2, "not a token"); !errors.Is(err, ErrInvalidPageToken) {
+		t.Errorf("garbage token err = %v; want ErrInvalidPageToken", err)
+	}
+}
+
+func TestStorePlaceCopies(t *testing.T) {
+	s := NewStore()
+	if _, err := s.Place(&mainpb.Order{}); !errors.Is(err, ErrNoUser) {
+		t.Errorf("Place without user err = %v; want ErrNoUser", err)
+	}
+
+	order := place(t, s, "alice", "Berlin")
+	order.Address.City = "changed"
+	latest, ok := s.Latest("alice")
+	if !ok || latest.GetId() != order.GetId() || latest.GetAddress().GetCity() != "Berlin" {
+		t.Errorf("Latest = %v, %v; want the stored order unaffected by the caller", latest, ok)
+	}
+	if _, ok := s.Latest("bob"); ok {
+		t.Error("Latest for a user without orders reported one")
+	}
+}
@@ -1,15 +1,15 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.36.6
-// 	protoc        v6.31.1
+// 	protoc-gen-go v1.36.7
+// 	protoc        v6.32.0
 // source: main.proto
 
 package mainpb
 
 import (
-	user "/proto/gen/user"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	user "practice/proto/gen/user"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -183,11 +183,17 @@ const file_main_proto_rawDesc = "" +
 	"\fconf_message\x18\x01 \x01(\tR\vconfMessage\"?\n" +
 	"\vUserProfile\x12\x1a\n" +
 	"\busername\x18\x01 \x01(\tR\busername\x12\x14\n" +
-	"\x05email\x18\x02 \x01(\tR\x05email2a\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email2\xf5\x01\n" +
 	"\aGreeter\x123\n" +
 	"\bSayHello\x12\x12.main.HelloRequest\x1a\x13.main.HelloResponse\x12!\n" +
 	"\x06Orders\x12\n" +
-	".user.User\x1a\v.main.OrderB\x13Z\x11/proto/gen;mainpbb\x06proto3"
+	".user.User\x1a\v.main.Order\x12&\n" +
+	"\n" +
+	"PlaceOrder\x12\v.main.Order\x1a\v.main.Order\x12?\n" +
+	"\n" +
+	"ListOrders\x12\x17.main.ListOrdersRequest\x1a\x18.main.ListOrdersResponse\x12)\n" +
+	"\fStreamOrders\x12\n" +
+	".user.User\x1a\v.main.Order0\x01B\x1bZ\x19practice/proto/gen;mainpbb\x06proto3"
 
 var (
 	file_main_proto_rawDescOnce sync.Once
@@ -203,19 +209,27 @@ func file_main_proto_rawDescGZIP() []byte {
 
 var file_main_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
 var file_main_proto_goTypes = []any{
-	(*HelloRequest)(nil),  // 0: main.HelloRequest
-	(*HelloResponse)(nil), // 1: main.HelloResponse
-	(*UserProfile)(nil),   // 2: main.UserProfile
-	(*user.User)(nil),     // 3: user.User
-	(*Order)(nil),         // 4: main.Order
+	(*HelloRequest)(nil),       // 0: main.HelloRequest
+	(*HelloResponse)(nil),      // 1: main.HelloResponse
+	(*UserProfile)(nil),        // 2: main.UserProfile
+	(*user.User)(nil),          // 3: user.User
+	(*Order)(nil),              // 4: main.Order
+	(*ListOrdersRequest)(nil),  // 5: main.ListOrdersRequest
+	(*ListOrdersResponse)(nil), // 6: main.ListOrdersResponse
 }
 var file_main_proto_depIdxs = []int32{
 	0, // 0: main.Greeter.SayHello:input_type -> main.HelloRequest
 	3, // 1: main.Greeter.Orders:input_type -> user.User
-	1, // 2: main.Greeter.SayHello:output_type -> main.HelloResponse
-	4, // 3: main.Greeter.Orders:output_type -> main.Order
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
+	4, // 2: main.Greeter.PlaceOrder:input_type -> main.Order
+	5, // 3: main.Greeter.ListOrders:input_type -> main.ListOrdersRequest
+	3, // 4: main.Greeter.StreamOrders:input_type -> user.User
+	1, // 5: main.Greeter.SayHello:output_type -> main.HelloResponse
+	4, // 6: main.Greeter.Orders:output_type -> main.Order
+	4, // 7: main.Greeter.PlaceOrder:output_type -> main.Order
+	6, // 8: main.Greeter.ListOrders:output_type -> main.ListOrdersResponse
+	4, // 9: main.Greeter.StreamOrders:output_type -> main.Order
+	5, // [5:10] is the sub-list for method output_type
+	0, // [0:5] is the sub-list for method input_type
 	0, // [0:0] is the sub-list for extension type_name
 	0, // [0:0] is the sub-list for extension extendee
 	0, // [0:0] is the sub-list for field type_name
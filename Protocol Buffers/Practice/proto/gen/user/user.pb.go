@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.36.6
-// 	protoc        v6.31.1
+// 	protoc-gen-go v1.36.7
+// 	protoc        v6.32.0
 // source: user/user.proto
 
 package userpb
@@ -71,7 +71,7 @@ const file_user_user_proto_rawDesc = "" +
 	"\n" +
 	"\x0fuser/user.proto\x12\x04user\"\x16\n" +
 	"\x04User\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02idB\x18Z\x16/proto/gen/user;userpbb\x06proto3"
+	"\x02id\x18\x01 \x01(\tR\x02idB Z\x1epractice/proto/gen/user;userpbb\x06proto3"
 
 var (
 	file_user_user_proto_rawDescOnce sync.Once
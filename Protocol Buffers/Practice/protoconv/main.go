@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	_ "practice/proto/gen" // registers main.* and user.* with protoregistry.GlobalTypes
+	"practice/protoxml"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+/*
+protoconv converts a practice message between JSON, XML and binary protobuf.
+
+	echo '{"id":"1","user":{"id":"alice"}}' | go run ./protoconv -type main.Order -to xml
+	go run ./protoconv -type main.Order -from hex -to json capture.hex
+	go run ./protoconv -type main.Order -from binary -grpc -to json stream.bin
+
+- -type is the full proto name, looked up in the proto registry, -list prints the known ones
+- json is protojson, xml the protoxml mapping, binary the wire format, hex and base64 the wire format as text (Wireshark's "copy as hex stream")
+- -grpc reads and writes the wire format in gRPC framing, a 5 byte header per message, as captured from a connection, JSON then has one message per line
+*/
+func main() {
+	typeName := flag.String("type", "", "full name of the message type, like main.Order")
+	from := flag.String("from", "json", "input format: json, xml, binary, hex or base64")
+	to := flag.String("to", "json", "output format: json, xml, binary, hex or base64")
+	grpcFraming := flag.Bool("grpc", false, "binary input and output are length-prefixed gRPC messages")
+	output := flag.String("o", "", "output file (default stdout)")
+	list := flag.Bool("list", false, "list the known message types")
+	flag.Parse()
+
+	if *list {
+		fmt.Println(strings.Join(knownTypes(), "\n"))
+		return
+	}
+	if *typeName == "" || flag.NArg() > 1 {
+		log.Fatalln("usage: protoconv -type main.Order [-from json] [-to xml] [-grpc] [-o out] [file]")
+	}
+	messageType, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(*typeName))
+	if err != nil {
+		log.Fatalf("unknown message type %s, known ones: %s", *typeName, strings.Join(knownTypes(), ", "))
+	}
+
+	in := io.Reader(os.Stdin)
+	if flag.NArg() == 1 {
+		file, err := os.Open(flag.Arg(0))
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer file.Close()
+		in = file
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	messages, err := read(messageType, *from, data, *grpcFraming)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	for i, msg := range messages {
+		if unknown := len(msg.ProtoReflect().GetUnknown()); unknown > 0 {
+			log.Printf("message %d has %d bytes of fields %s doesn't know, encoded by a newer version?", i, unknown, *typeName)
+		}
+	}
+	out, err := write(*to, messages, *grpcFraming)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	if *output == "" {
+		os.Stdout.Write(out)
+		return
+	}
+	if err := os.WriteFile(*output, out, 0o644); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func read(messageType protoreflect.MessageType, format string, data []byte, grpcFraming bool) ([]proto.Message, error) {
+	var messages []proto.Message
+	switch format {
+	case "json":
+		// A stream of JSON objects, one per message
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			msg := messageType.New().Interface()
+			if err := protojson.Unmarshal(raw, msg); err != nil {
+				return nil, err
+			}
+			messages = append(messages, msg)
+		}
+	case "xml":
+		msg := messageType.New().Interface()
+		if err := protoxml.Unmarshal(data, msg); err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	default:
+		wire, err := decodeWire(format, data)
+		if err != nil {
+			return nil, err
+		}
+		payloads := [][]byte{wire}
+		if grpcFraming {
+			if payloads, err = splitFrames(wire); err != nil {
+				return nil, err
+			}
+		}
+		for _, payload := range payloads {
+			msg := messageType.New().Interface()
+			if err := proto.Unmarshal(payload, msg); err != nil {
+				return nil, err
+			}
+			messages = append(messages, msg)
+		}
+	}
+	return messages, nil
+}
+
+func write(format string, messages []proto.Message, grpcFraming bool) ([]byte, error) {
+	var out bytes.Buffer
+	switch format {
+	case "json":
+		options := protojson.MarshalOptions{}
+		if len(messages) == 1 {
+			options.Indent = "  "
+		}
+		for _, msg := range messages {
+			data, err := options.Marshal(msg)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(data)
+			out.WriteByte('\n')
+		}
+	case "xml":
+		// An XML document has a single root, protoxml.Unmarshal couldn't read a second message back
+		if len(messages) > 1 {
+			return nil, errors.New("xml holds a single message, convert several to json")
+		}
+		for _, msg := range messages {
+			data, err := protoxml.Marshal(msg)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(data)
+			out.WriteByte('\n')
+		}
+	case "binary", "hex", "base64":
+		if len(messages) > 1 && !grpcFraming {
+			return nil, errors.New("several messages need -grpc framing in the wire format")
+		}
+		var wire []byte
+		for _, msg := range messages {
+			data, err := proto.Marshal(msg)
+			if err != nil {
+				return nil, err
+			}
+			if grpcFraming {
+				wire = append(wire, 0)
+				wire = binary.BigEndian.AppendUint32(wire, uint32(len(data)))
+			}
+			wire = append(wire, data...)
+		}
+		switch format {
+		case "hex":
+			return []byte(hex.EncodeToString(wire) + "\n"), nil
+		case "base64":
+			return []byte(base64.StdEncoding.EncodeToString(wire) + "\n"), nil
+		}
+		return wire, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+	return out.Bytes(), nil
+}
+
+// decodeWire turns binary, hex or base64 input into wire format bytes, hex may be spaced or colon separated
+func decodeWire(format string, data []byte) ([]byte, error) {
+	switch format {
+	case "binary":
+		return data, nil
+	case "hex":
+		cleaned := strings.Map(func(r rune) rune {
+			if r == ':' || r == ' ' || r == '\n' || r == '\r' || r == '\t' {
+				return -1
+			}
+			return r
+		}, string(data))
+		return hex.DecodeString(cleaned)
+	case "base64":
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	}
+	return nil, fmt.Errorf("unknown input format %q", format)
+}
+
+// splitFrames cuts gRPC framed messages, each is a compressed flag byte, a big endian uint32 length and the message
+func splitFrames(data []byte) ([][]byte, error) {
+	var payloads [][]byte
+	for len(data) > 0 {
+		if len(data) < 5 {
+			return nil, errors.New("truncated gRPC frame header")
+		}
+		if data[0] != 0 {
+			return nil, errors.New("compressed gRPC frames are not supported, capture without grpc-encoding")
+		}
+		size := binary.BigEndian.Uint32(data[1:5])
+		if uint64(len(data)-5) < uint64(size) {
+			return nil, fmt.Errorf("gRPC frame of %d bytes truncated to %d", size, len(data)-5)
+		}
+		payloads = append(payloads, data[5:5+size])
+		data = data[5+size:]
+	}
+	return payloads, nil
+}
+
+// knownTypes lists the registered messages outside google.*
+func knownTypes() []string {
+	var names []string
+	protoregistry.GlobalTypes.RangeMessages(func(mt protoreflect.MessageType) bool {
+		if name := string(mt.Descriptor().FullName()); !strings.HasPrefix(name, "google.") {
+			names = append(names, name)
+		}
+		return true
+	})
+	slices.Sort(names)
+	return names
+}
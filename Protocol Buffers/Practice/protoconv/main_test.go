@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+
+	mainpb "practice/proto/gen"
+	userpb "practice/proto/gen/user"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestConvertThroughEveryFormat(t *testing.T) {
+	order := &mainpb.Order{Id: "order-1", User: &userpb.User{Id: "alice"}, Address: &mainpb.Address{City: "Berlin"}}
+	messageType := order.ProtoReflect().Type()
+
+	for _, format := range []string{"json", "xml", "binary", "hex", "base64"} {
+		data, err := write(format, []proto.Message{order}, false)
+		if err != nil {
+			t.Fatalf("write %s: %v", format, err)
+		}
+		got, err := read(messageType, format, data, false)
+		if err != nil || len(got) != 1 || !proto.Equal(got[0], order) {
+			t.Errorf("%s round trip = %v, %v", format, got, err)
+		}
+	}
+}
+
+func TestGRPCFraming(t *testing.T) {
+	messages := []proto.Message{&mainpb.Order{Id: "1"}, &mainpb.Order{Id: "2"}}
+	if _, err := write("binary", messages, false); err == nil {
+		t.Error("several messages without framing succeeded")
+	}
+	if _, err := write("xml", messages, true); err == nil {
+		t.Error("several messages written as one xml document")
+	}
+
+	data, err := write("hex", messages, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Flag 0, length 3, then the message, per order
+	if string(data) != "00000000030a013100000000030a0132\n" {
+		t.Errorf("framed hex = %q", data)
+	}
+	got, err := read((&mainpb.Order{}).ProtoReflect().Type(), "hex", data, true)
+	if err != nil || len(got) != 2 || !proto.Equal(got[1], messages[1]) {
+		t.Errorf("framed read = %v, %v", got, err)
+	}
+
+	for name, frames := range map[string]string{
+		"truncated header":  "000000",
+		"truncated message": "00000000050a01",
+		"compressed":        "01000000030a0131",
+	} {
+		if _, err := read((&mainpb.Order{}).ProtoReflect().Type(), "hex", []byte(frames), true); err == nil {
+			t.Errorf("%s frame read without error", name)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+package protoxml
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+/*
+Marshal writes m as indented XML, read back by Unmarshal.
+- The root element is the message name, every set field an element named like the field in the .proto
+- Repeated fields repeat their element, map entries carry their key as attribute: <counts key="a">1</counts>
+- Scalars are written like protojson writes them, bytes in base64 and enums by their value name
+- Unset fields are left out, like proto3 JSON does, the rest follow in .proto order and map entries by key
+*/
+func Marshal(m proto.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	msg := m.ProtoReflect()
+	if err := encodeMessage(enc, xml.StartElement{Name: xml.Name{Local: string(msg.Descriptor().Name())}}, msg); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeMessage(enc *xml.Encoder, start xml.StartElement, msg protoreflect.Message) error {
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	fields := msg.Descriptor().Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if !msg.Has(fd) {
+			continue
+		}
+		if err := encodeField(enc, fd, msg.Get(fd)); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+func encodeField(enc *xml.Encoder, fd protoreflect.FieldDescriptor, v protoreflect.Value) error {
+	start := xml.StartElement{Name: xml.Name{Local: string(fd.Name())}}
+	switch {
+	case fd.IsMap():
+		for _, key := range sortedKeys(v.Map()) {
+			entry := start.Copy()
+			entry.Attr = []xml.Attr{{Name: xml.Name{Local: "key"}, Value: scalarText(fd.MapKey(), key.Value())}}
+			if err := encodeValue(enc, entry, fd.MapValue(), v.Map().Get(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case fd.IsList():
+		list := v.List()
+		for i := range list.Len() {
+			if err := encodeValue(enc, start, fd, list.Get(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return encodeValue(enc, start, fd, v)
+}
+
+// sortedKeys orders map keys the way their type compares, so equal messages give equal XML
+func sortedKeys(m protoreflect.Map) []protoreflect.MapKey {
+	var keys []protoreflect.MapKey
+	m.Range(func(key protoreflect.MapKey, _ protoreflect.Value) bool {
+		keys = append(keys, key)
+		return true
+	})
+	slices.SortFunc(keys, func(a, b protoreflect.MapKey) int {
+		switch a.Interface().(type) {
+		case string:
+			return cmp.Compare(a.String(), b.String())
+		case bool:
+			return cmp.Compare(boolInt(a.Bool()), boolInt(b.Bool()))
+		case int32, int64:
+			return cmp.Compare(a.Int(), b.Int())
+		}
+		return cmp.Compare(a.Uint(), b.Uint())
+	})
+	return keys
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func encodeValue(enc *xml.Encoder, start xml.StartElement, fd protoreflect.FieldDescriptor, v protoreflect.Value) error {
+	if fd.Message() != nil {
+		return encodeMessage(enc, start, v.Message())
+	}
+	return enc.EncodeElement(scalarText(fd, v), start)
+}
+
+func scalarText(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return strconv.FormatBool(v.Bool())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return strconv.FormatInt(v.Int(), 10)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return strconv.FormatUint(v.Uint(), 10)
+	case protoreflect.FloatKind:
+		return formatFloat(v.Float(), 32)
+	case protoreflect.DoubleKind:
+		return formatFloat(v.Float(), 64)
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(v.Bytes())
+	case protoreflect.EnumKind:
+		if value := fd.Enum().Values().ByNumber(v.Enum()); value != nil {
+			return string(value.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	}
+	return v.String()
+}
+
+// formatFloat spells the special values like protojson does
+func formatFloat(f float64, bitSize int) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return strconv.FormatFloat(f, 'g', -1, bitSize)
+}
+
+/*
+Unmarshal reads XML written by Marshal into m, which is reset first.
+- The root element has to be named after m's message, fields may also be named by their JSON name
+- Unknown elements are an error, XML comments and processing instructions are skipped
+- An XML document has one root, anything but whitespace after it, like a second message, is an error
+*/
+func Unmarshal(data []byte, m proto.Message) error {
+	proto.Reset(m)
+	msg := m.ProtoReflect()
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return errors.New("protoxml: no root element")
+		}
+		if err != nil {
+			return err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if want := string(msg.Descriptor().Name()); start.Name.Local != want {
+			return fmt.Errorf("protoxml: root element <%s>, want <%s>", start.Name.Local, want)
+		}
+		if err := decodeMessage(dec, msg); err != nil {
+			return err
+		}
+		return expectEnd(dec)
+	}
+}
+
+// expectEnd fails on content after the root element, XML holds a single message
+func expectEnd(dec *xml.Decoder) error {
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			return fmt.Errorf("protoxml: <%s> after the root element, one document holds one message", tok.Name.Local)
+		case xml.CharData:
+			if len(bytes.TrimSpace(tok)) > 0 {
+				return fmt.Errorf("protoxml: text %q after the root element", strings.TrimSpace(string(tok)))
+			}
+		}
+	}
+}
+
+// decodeMessage reads the fields of msg up to the end of its element
+func decodeMessage(dec *xml.Decoder, msg protoreflect.Message) error {
+	fields := msg.Descriptor().Fields()
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok := tok.(type) {
+		case xml.EndElement:
+			return nil
+		case xml.CharData:
+			if len(bytes.TrimSpace(tok)) > 0 {
+				return fmt.Errorf("protoxml: text %q in %s, want field elements", strings.TrimSpace(string(tok)), msg.Descriptor().FullName())
+			}
+		case xml.StartElement:
+			fd := fields.ByName(protoreflect.Name(tok.Name.Local))
+			if fd == nil {
+				fd = fields.ByJSONName(tok.Name.Local)
+			}
+			if fd == nil {
+				return fmt.Errorf("protoxml: %s has no field %s", msg.Descriptor().FullName(), tok.Name.Local)
+			}
+			if err := decodeField(dec, tok, msg, fd); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func decodeField(dec *xml.Decoder, start xml.StartElement, msg protoreflect.Message, fd protoreflect.FieldDescriptor) error {
+	switch {
+	case fd.IsMap():
+		keyText, ok := attr(start, "key")
+		if !ok {
+			return fmt.Errorf("protoxml: map entry %s without key attribute", fd.FullName())
+		}
+		key, err := parseScalar(fd.MapKey(), keyText)
+		if err != nil {
+			return err
+		}
+		value, err := decodeValue(dec, fd.MapValue(), msg.Mutable(fd).Map().NewValue)
+		if err != nil {
+			return err
+		}
+		msg.Mutable(fd).Map().Set(key.MapKey(), value)
+	case fd.IsList():
+		list := msg.Mutable(fd).List()
+		value, err := decodeValue(dec, fd, list.NewElement)
+		if err != nil {
+			return err
+		}
+		list.Append(value)
+	default:
+		value, err := decodeValue(dec, fd, func() protoreflect.Value { return msg.NewField(fd) })
+		if err != nil {
+			return err
+		}
+		msg.Set(fd, value)
+	}
+	return nil
+}
+
+// decodeValue reads one element's value, newMessage makes the value a message field is decoded into
+func decodeValue(dec *xml.Decoder, fd protoreflect.FieldDescriptor, newMessage func() protoreflect.Value) (protoreflect.Value, error) {
+	if fd.Message() != nil {
+		value := newMessage()
+		return value, decodeMessage(dec, value.Message())
+	}
+	text, err := elementText(dec, fd)
+	if err != nil {
+		return protoreflect.Value{}, err
+	}
+	return parseScalar(fd, text)
+}
+
+// elementText reads the text of a scalar field's element up to its end
+func elementText(dec *xml.Decoder, fd protoreflect.FieldDescriptor) (string, error) {
+	var text strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		switch tok := tok.(type) {
+		case xml.CharData:
+			text.Write(tok)
+		case xml.StartElement:
+			return "", fmt.Errorf("protoxml: element <%s> in scalar field %s", tok.Name.Local, fd.FullName())
+		case xml.EndElement:
+			return text.String(), nil
+		}
+	}
+}
+
+func attr(start xml.StartElement, name string) (string, bool) {
+	for _, a := range start.Attr {
+		if a.Name.Local == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+func parseScalar(fd protoreflect.FieldDescriptor, text string) (protoreflect.Value, error) {
+	text = strings.TrimSpace(text)
+	var (
+		v   protoreflect.Value
+		err error
+	)
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(text), nil
+	case protoreflect.BoolKind:
+		var b bool
+		b, err = strconv.ParseBool(text)
+		v = protoreflect.ValueOfBool(b)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		var n int64
+		n, err = strconv.ParseInt(text, 10, 32)
+		v = protoreflect.ValueOfInt32(int32(n))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		var n int64
+		n, err = strconv.ParseInt(text, 10, 64)
+		v = protoreflect.ValueOfInt64(n)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		var n uint64
+		n, err = strconv.ParseUint(text, 10, 32)
+		v = protoreflect.ValueOfUint32(uint32(n))
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		var n uint64
+		n, err = strconv.ParseUint(text, 10, 64)
+		v = protoreflect.ValueOfUint64(n)
+	case protoreflect.FloatKind:
+		var f float64
+		f, err = parseFloat(text, 32)
+		v = protoreflect.ValueOfFloat32(float32(f))
+	case protoreflect.DoubleKind:
+		var f float64
+		f, err = parseFloat(text, 64)
+		v = protoreflect.ValueOfFloat64(f)
+	case protoreflect.BytesKind:
+		var b []byte
+		b, err = base64.StdEncoding.DecodeString(text)
+		v = protoreflect.ValueOfBytes(b)
+	case protoreflect.EnumKind:
+		if value := fd.Enum().Values().ByName(protoreflect.Name(text)); value != nil {
+			return protoreflect.ValueOfEnum(value.Number()), nil
+		}
+		var n int64
+		n, err = strconv.ParseInt(text, 10, 32)
+		v = protoreflect.ValueOfEnum(protoreflect.EnumNumber(n))
+	default:
+		return v, fmt.Errorf("protoxml: %s has unsupported kind %s", fd.FullName(), fd.Kind())
+	}
+	if err != nil {
+		return v, fmt.Errorf("protoxml: %s: %w", fd.FullName(), err)
+	}
+	return v, nil
+}
+
+func parseFloat(text string, bitSize int) (float64, error) {
+	switch text {
+	case "NaN":
+		return math.NaN(), nil
+	case "Infinity":
+		return math.Inf(1), nil
+	case "-Infinity":
+		return math.Inf(-1), nil
+	}
+	return strconv.ParseFloat(text, bitSize)
+}
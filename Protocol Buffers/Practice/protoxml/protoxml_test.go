@@ -0,0 +1,97 @@
+package protoxml
+
+import (
+	"strings"
+	"testing"
+
+	mainpb "practice/proto/gen"
+	userpb "practice/proto/gen/user"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestOrderRoundTrip(t *testing.T) {
+	res := &mainpb.ListOrdersResponse{
+		Orders: []*mainpb.Order{
+			{Id: "order-1", User: &userpb.User{Id: "alice"}, Address: &mainpb.Address{AddressLine: "Main Street 1", City: "Berlin"}},
+			{Id: "order-2", User: &userpb.User{Id: "alice"}, Address: &mainpb.Address{City: "Paris & Co <3>"}},
+		},
+		NextPageToken: "abc",
+	}
+
+	data, err := Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<ListOrdersResponse>
+  <orders>
+    <id>order-1</id>
+    <user>
+      <id>alice</id>
+    </user>
+    <address>
+      <address_line>Main Street 1</address_line>
+      <city>Berlin</city>
+    </address>
+  </orders>`
+	if !strings.HasPrefix(string(data), want) {
+		t.Errorf("Marshal() =\n%s\nwant it to start with\n%s", data, want)
+	}
+
+	var decoded mainpb.ListOrdersResponse
+	if err := Unmarshal(data, &decoded); err != nil || !proto.Equal(res, &decoded) {
+		t.Errorf("round trip = %v, %v; want %v", &decoded, err, res)
+	}
+}
+
+// structpb covers what the practice messages don't: maps, oneofs, enums, doubles, bools and nested lists
+func TestStructRoundTrip(t *testing.T) {
+	s, err := structpb.NewStruct(map[string]any{
+		"name":    "Alice",
+		"age":     30.5,
+		"admin":   true,
+		"nothing": nil,
+		"tags":    []any{"a", 1.0, map[string]any{"deep": false}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `<fields key="nothing">`) || !strings.Contains(string(data), "<null_value>NULL_VALUE</null_value>") {
+		t.Errorf("Marshal() =\n%s\nwant map keys as attributes and enums by name", data)
+	}
+
+	var decoded structpb.Struct
+	if err := Unmarshal(data, &decoded); err != nil || !proto.Equal(s, &decoded) {
+		t.Errorf("round trip = %v, %v; want %v", &decoded, err, s)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := map[string]string{
+		"wrong root":     `<Address><city>Berlin</city></Address>`,
+		"unknown field":  `<Order><colour>red</colour></Order>`,
+		"stray text":     `<Order>hello<id>1</id></Order>`,
+		"nested in text": `<Order><id><city>x</city></id></Order>`,
+		"two messages":   `<Order><id>1</id></Order><Order><id>2</id></Order>`,
+		"trailing text":  `<Order><id>1</id></Order>done`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := Unmarshal([]byte(data), &mainpb.Order{}); err == nil {
+				t.Errorf("Unmarshal(%s) succeeded", data)
+			}
+		})
+	}
+
+	// JSON names work as well
+	var order mainpb.Order
+	if err := Unmarshal([]byte(`<?xml version="1.0"?><Order><address><addressLine>x</addressLine></address></Order>`), &order); err != nil || order.GetAddress().GetAddressLine() != "x" {
+		t.Errorf("Unmarshal with JSON name = %v, %v", &order, err)
+	}
+}
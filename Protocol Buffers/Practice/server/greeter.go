@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"practice/orders"
+	mainpb "practice/proto/gen"
+	userpb "practice/proto/gen/user"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
+)
+
+type server struct {
+	mainpb.UnimplementedGreeterServer
+	store *orders.Store
+}
+
+func (s *server) SayHello(ctx context.Context, req *mainpb.HelloRequest) (*mainpb.HelloResponse, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	return &mainpb.HelloResponse{ConfMessage: fmt.Sprintf("Hello %s, you are %d.", req.GetName(), req.GetAge())}, nil
+}
+
+func (s *server) PlaceOrder(ctx context.Context, req *mainpb.Order) (*mainpb.Order, error) {
+	order, err := s.store.Place(req)
+	if errors.Is(err, orders.ErrNoUser) {
+		return nil, status.Error(codes.InvalidArgument, "user.id is required")
+	}
+	return order, err
+}
+
+// Orders is the original single-order method, it answers with the latest order
+func (s *server) Orders(ctx context.Context, req *userpb.User) (*mainpb.Order, error) {
+	order, ok := s.store.Latest(req.GetId())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "user %q has no orders", req.GetId())
+	}
+	return order, nil
+}
+
+func (s *server) ListOrders(ctx context.Context, req *mainpb.ListOrdersRequest) (*mainpb.ListOrdersResponse, error) {
+	size := int(req.GetPageSize())
+	switch {
+	case size < 0:
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	case size == 0:
+		size = defaultPageSize
+	case size > maxPageSize:
+		size = maxPageSize
+	}
+
+	page, next, err := s.store.Page(req.GetUser().GetId(), size, req.GetPageToken())
+	if errors.Is(err, orders.ErrInvalidPageToken) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &mainpb.ListOrdersResponse{Orders: page, NextPageToken: next}, nil
+}
+
+// StreamOrders sends the orders the user had when the call started, oldest first
+func (s *server) StreamOrders(req *userpb.User, stream grpc.ServerStreamingServer[mainpb.Order]) error {
+	for _, order := range s.store.All(req.GetId()) {
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		if err := stream.Send(order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"practice/orders"
+	mainpb "practice/proto/gen"
+	userpb "practice/proto/gen/user"
+
+	"pkg/grpctest"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func newBufconnClient(t *testing.T) mainpb.GreeterClient {
+	t.Helper()
+
+	srv := grpctest.Start(t, func(s *grpc.Server) {
+		mainpb.RegisterGreeterServer(s, &server{store: orders.NewStore()})
+	})
+	return grpctest.NewClient(srv, mainpb.NewGreeterClient)
+}
+
+func newOrder(userID, city string) *mainpb.Order {
+	return &mainpb.Order{
+		User:    &userpb.User{Id: userID},
+		Address: &mainpb.Address{AddressLine: "Main Street 1", City: city, State: "EU"},
+	}
+}
+
+// Order lives in package main of order.proto, its user in package user of user/user.proto
+func TestOrderRoundTrip(t *testing.T) {
+	order := newOrder("alice", "Berlin")
+	order.Id = "order-1"
+
+	data, err := proto.Marshal(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded mainpb.Order
+	if err := proto.Unmarshal(data, &decoded); err != nil || !proto.Equal(order, &decoded) {
+		t.Errorf("binary round trip = %v, %v; want %v", &decoded, err, order)
+	}
+
+	data, err = protojson.Marshal(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded.Reset()
+	if err := protojson.Unmarshal(data, &decoded); err != nil || !proto.Equal(order, &decoded) {
+		t.Errorf("JSON round trip of %s = %v, %v", data, &decoded, err)
+	}
+
+	client := newBufconnClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	placed, err := client.PlaceOrder(ctx, newOrder("alice", "Berlin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest, err := client.Orders(ctx, &userpb.User{Id: "alice"})
+	if err != nil || !proto.Equal(placed, latest) || !proto.Equal(latest.GetUser(), order.GetUser()) || !proto.Equal(latest.GetAddress(), order.GetAddress()) {
+		t.Errorf("Orders(alice) = %v, %v; want the placed %v", latest, err, placed)
+	}
+}
+
+func TestOrdersPagesAndStreams(t *testing.T) {
+	client := newBufconnClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := client.Orders(ctx, &userpb.User{Id: "alice"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Orders without any err = %v; want NotFound", err)
+	}
+	if _, err := client.PlaceOrder(ctx, &mainpb.Order{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("PlaceOrder without user err = %v; want InvalidArgument", err)
+	}
+
+	var placed []*mainpb.Order
+	for _, city := range []string{"Berlin", "Paris", "Rome"} {
+		order, err := client.PlaceOrder(ctx, newOrder("alice", city))
+		if err != nil {
+			t.Fatal(err)
+		}
+		placed = append(placed, order)
+	}
+	client.PlaceOrder(ctx, newOrder("bob", "Oslo"))
+
+	var listed []*mainpb.Order
+	req := &mainpb.ListOrdersRequest{User: &userpb.User{Id: "alice"}, PageSize: 2}
+	for {
+		res, err := client.ListOrders(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		listed = append(listed, res.GetOrders()...)
+		if res.GetNextPageToken() == "" {
+			break
+		}
+		req.PageToken = res.GetNextPageToken()
+	}
+	checkOrders(t, "ListOrders", listed, placed)
+
+	_, err := client.ListOrders(ctx, &mainpb.ListOrdersRequest{User: &userpb.User{Id: "bob"}, PageToken: req.PageToken})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ListOrders with alice's token for bob err = %v; want InvalidArgument", err)
+	}
+
+	stream, err := client.StreamOrders(ctx, &userpb.User{Id: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var streamed []*mainpb.Order
+	for {
+		order, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		streamed = append(streamed, order)
+	}
+	checkOrders(t, "StreamOrders", streamed, placed)
+}
+
+func checkOrders(t *testing.T, method string, got, want []*mainpb.Order) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s returned %d orders; want %d", method, len(got), len(want))
+	}
+	for i := range want {
+		if !proto.Equal(got[i], want[i]) {
+			t.Errorf("%s order %d = %v; want %v", method, i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"practice/orders"
+	mainpb "practice/proto/gen"
+
+	"pkg/health"
+	"pkg/interceptors"
+
+	"google.golang.org/grpc"
+)
+
+func main() {
+	address := flag.String("addr", "localhost:50052", "listen address")
+	flag.Parse()
+
+	lis, err := net.Listen("tcp", *address)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	healthServer := health.NewServer(mainpb.Greeter_ServiceDesc.ServiceName)
+	grpcServer := grpc.NewServer(interceptors.ServerOptions(interceptors.Config{
+		Logger:       slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+		UnaryTimeout: 10 * time.Second,
+	})...)
+	mainpb.RegisterGreeterServer(grpcServer, &server{store: orders.NewStore()})
+	healthServer.Register(grpcServer)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go healthServer.Run(ctx, 10*time.Second)
+
+	go func() {
+		log.Println("Running gRPC server at:", *address)
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Fatalln(err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down, draining in-flight calls")
+	healthServer.Shutdown(grpcServer, time.Second, 10*time.Second)
+}
@@ -3,6 +3,20 @@ module restapi
 go 1.24.2
 
 require (
-	golang.org/x/net v0.41.0 // indirect
+	github.com/coder/websocket v1.8.13
+	github.com/klauspost/compress v1.18.0
+	golang.org/x/net v0.41.0
+	google.golang.org/protobuf v1.36.8
+	pkg v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/golang-jwt/jwt/v5 v5.3.1 // indirect
+	golang.org/x/sys v0.33.0 // indirect
 	golang.org/x/text v0.26.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250818200422-3122310a409c // indirect
+	google.golang.org/grpc v1.74.2 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace pkg => ../pkg
@@ -0,0 +1,68 @@
+package codecs
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type acceptRange struct {
+	typ     string
+	subtype string
+	q       float64
+}
+
+func (ar acceptRange) matches(mediaType string) bool {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	if ar.typ != "*" && ar.typ != typ {
+		return false
+	}
+	return ar.subtype == "*" || ar.subtype == subtype
+}
+
+// specificity ranks "type/subtype" above "type/*" above "*/*" for ranges with equal quality.
+func (ar acceptRange) specificity() int {
+	switch {
+	case ar.typ == "*":
+		return 0
+	case ar.subtype == "*":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// parseAccept splits an Accept header into media ranges sorted by quality, then specificity.
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		mediaRange := strings.ToLower(strings.TrimSpace(fields[0]))
+		typ, subtype, ok := strings.Cut(mediaRange, "/")
+		if !ok || typ == "" || subtype == "" {
+			continue
+		}
+
+		ar := acceptRange{typ: typ, subtype: subtype, q: 1}
+		for _, param := range fields[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.ToLower(key) != "q" {
+				continue
+			}
+			q, err := strconv.ParseFloat(value, 64)
+			if err != nil || q < 0 || q > 1 {
+				q = 0
+			}
+			ar.q = q
+		}
+		ranges = append(ranges, ar)
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].q != ranges[j].q {
+			return ranges[i].q > ranges[j].q
+		}
+		return ranges[i].specificity() > ranges[j].specificity()
+	})
+	return ranges
+}
@@ -0,0 +1,117 @@
+package codecs
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrNotAcceptable        = errors.New("codecs: no codec satisfies the Accept header")
+	ErrUnsupportedMediaType = errors.New("codecs: unsupported Content-Type")
+)
+
+/*
+Codec reads and writes resources for one media type.
+MediaTypes returns every media type the codec answers to, the first one being
+the canonical type written back in the Content-Type response header.
+*/
+type Codec interface {
+	MediaTypes() []string
+	Encode(w io.Writer, v any) error
+	Decode(r io.Reader, v any) error
+}
+
+// Registry holds the codecs a server can negotiate between, in order of preference.
+type Registry struct {
+	mu      sync.RWMutex
+	codecs  []Codec
+	byMedia map[string]Codec
+}
+
+func NewRegistry(codecs ...Codec) *Registry {
+	r := &Registry{byMedia: make(map[string]Codec)}
+	for _, c := range codecs {
+		r.Register(c)
+	}
+	return r
+}
+
+// Default returns a registry negotiating JSON (preferred), XML and Protobuf.
+func Default() *Registry {
+	return NewRegistry(JSON{}, XML{}, Protobuf{})
+}
+
+// Register adds a codec; a codec registered later replaces earlier ones for the media types they share.
+func (r *Registry) Register(c Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.codecs = append(r.codecs, c)
+	for _, mt := range c.MediaTypes() {
+		r.byMedia[strings.ToLower(mt)] = c
+	}
+}
+
+/*
+Negotiate picks the codec for a response from the request's Accept header, along with the media type
+to answer with.
+- An empty header means the client accepts anything, so the first registered codec wins
+- Media ranges are tried from the highest quality down, wildcard ranges included
+- A range with q=0 explicitly refuses that media type
+*/
+func (r *Registry) Negotiate(accept string) (Codec, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.codecs) == 0 {
+		return nil, "", ErrNotAcceptable
+	}
+	if strings.TrimSpace(accept) == "" {
+		return r.codecs[0], r.codecs[0].MediaTypes()[0], nil
+	}
+
+	ranges := parseAccept(accept)
+	for _, ar := range ranges {
+		if ar.q == 0 {
+			continue
+		}
+		for _, c := range r.codecs {
+			for _, mt := range c.MediaTypes() {
+				if ar.matches(mt) && !refused(ranges, mt) {
+					return c, mt, nil
+				}
+			}
+		}
+	}
+	return nil, "", ErrNotAcceptable
+}
+
+// ForContentType returns the codec able to decode a request body of the given Content-Type.
+func (r *Registry) ForContentType(contentType string) (Codec, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, ErrUnsupportedMediaType
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.byMedia[mediaType]
+	if !ok {
+		return nil, ErrUnsupportedMediaType
+	}
+	return c, nil
+}
+
+// refused reports whether the client sent an exact q=0 range for the media type.
+func refused(ranges []acceptRange, mediaType string) bool {
+	for _, ar := range ranges {
+		if ar.q == 0 && ar.typ+"/"+ar.subtype == mediaType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package codecs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	registry := Default()
+	tests := []struct {
+		accept string
+		want   string // media type answered with, "" for 406
+	}{
+		{"", "application/json"},
+		{"   ", "application/json"},
+		{"*/*", "application/json"},
+		{"application/json", "application/json"},
+		{"application/xml", "application/xml"},
+		{"text/xml", "text/xml"},
+		{"APPLICATION/X-PROTOBUF", "application/x-protobuf"},
+		{"application/vnd.google.protobuf", "application/vnd.google.protobuf"},
+
+		// quality
+		{"application/json;q=0.5, application/xml", "application/xml"},
+		{"application/json; q=0.9, application/x-protobuf; q=0.95", "application/x-protobuf"},
+		{"*/*;q=0.1, application/xml", "application/xml"},
+
+		// wildcards fall back to the registry's preference order
+		{"text/*", "text/xml"},
+		{"application/*", "application/json"},
+
+		// specificity breaks ties of equal quality, then the client's order
+		{"application/*, application/xml", "application/xml"},
+		{"*/*, text/xml", "text/xml"},
+		{"application/xml, application/json", "application/xml"},
+		{"application/json, application/xml", "application/json"},
+
+		// q=0 refuses a type even when a wildcard would match it
+		{"application/json;q=0, */*", "application/xml"},
+		{"application/json;q=0, application/xml;q=0, text/xml;q=0, application/*", "application/x-protobuf"},
+		{"*/*;q=0", ""},
+		{"application/json;q=0", ""},
+
+		// invalid quality values count as q=0
+		{"application/json;q=abc", ""},
+		{"application/json;q=2", ""},
+
+		{"image/png", ""},
+		{"nonsense, text/html", ""},
+	}
+	for _, tt := range tests {
+		codec, mediaType, err := registry.Negotiate(tt.accept)
+		if tt.want == "" {
+			if !errors.Is(err, ErrNotAcceptable) {
+				t.Errorf("Negotiate(%q) = %q, %v; want ErrNotAcceptable", tt.accept, mediaType, err)
+			}
+			continue
+		}
+		if err != nil || mediaType != tt.want {
+			t.Errorf("Negotiate(%q) = %q, %v; want %q", tt.accept, mediaType, err, tt.want)
+			continue
+		}
+		if got, _ := registry.ForContentType(mediaType); got != codec {
+			t.Errorf("Negotiate(%q) returned %T, which is not the codec registered for %s", tt.accept, codec, mediaType)
+		}
+	}
+
+	if _, _, err := NewRegistry().Negotiate(""); !errors.Is(err, ErrNotAcceptable) {
+		t.Errorf("empty registry: err = %v; want ErrNotAcceptable", err)
+	}
+}
+
+func TestForContentType(t *testing.T) {
+	registry := Default()
+	tests := []struct {
+		contentType string
+		want        Codec // nil for 415
+	}{
+		{"application/json", JSON{}},
+		{"application/json; charset=utf-8", JSON{}},
+		{"Application/JSON", JSON{}},
+		{"text/xml; charset=iso-8859-1", XML{}},
+		{"application/xml", XML{}},
+		{"application/protobuf", Protobuf{}},
+		{"application/x-protobuf", Protobuf{}},
+		{"", nil},
+		{"text/plain", nil},
+		{"application/json; charset", nil},
+		{"*/*", nil},
+	}
+	for _, tt := range tests {
+		codec, err := registry.ForContentType(tt.contentType)
+		if tt.want == nil {
+			if !errors.Is(err, ErrUnsupportedMediaType) {
+				t.Errorf("ForContentType(%q) = %T, %v; want ErrUnsupportedMediaType", tt.contentType, codec, err)
+			}
+			continue
+		}
+		if err != nil || codec != tt.want {
+			t.Errorf("ForContentType(%q) = %T, %v; want %T", tt.contentType, codec, err, tt.want)
+		}
+	}
+}
+
+// upperJSON claims application/json to check that later registrations win
+type upperJSON struct{ JSON }
+
+func TestRegisterReplacesMediaTypes(t *testing.T) {
+	registry := NewRegistry(JSON{}, XML{})
+	registry.Register(upperJSON{})
+
+	if codec, _ := registry.ForContentType("application/json"); codec != (upperJSON{}) {
+		t.Errorf("ForContentType = %T; want the codec registered last", codec)
+	}
+	if codec, _ := registry.ForContentType("application/xml"); codec != (XML{}) {
+		t.Errorf("ForContentType = %T; other media types must keep their codec", codec)
+	}
+}
@@ -0,0 +1,69 @@
+package codecs
+
+import (
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/proto"
+)
+
+/*
+ProtoModel is implemented by models that have a protobuf representation.
+ToProto on a zero value must still return a message of the right type, Decode relies on that to
+know what to unmarshal into.
+*/
+type ProtoModel interface {
+	ToProto() proto.Message
+	FromProto(m proto.Message) error
+}
+
+type Protobuf struct{}
+
+func (Protobuf) MediaTypes() []string {
+	return []string{"application/x-protobuf", "application/protobuf", "application/vnd.google.protobuf"}
+}
+
+func (Protobuf) Encode(w io.Writer, v any) error {
+	msg, err := toProto(v)
+	if err != nil {
+		return err
+	}
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (Protobuf) Decode(r io.Reader, v any) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if msg, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, msg)
+	}
+	model, ok := v.(ProtoModel)
+	if !ok {
+		return fmt.Errorf("codecs: %T has no protobuf representation", v)
+	}
+	msg := model.ToProto()
+	proto.Reset(msg)
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return err
+	}
+	return model.FromProto(msg)
+}
+
+func toProto(v any) (proto.Message, error) {
+	switch m := v.(type) {
+	case proto.Message:
+		return m, nil
+	case ProtoModel:
+		return m.ToProto(), nil
+	default:
+		return nil, fmt.Errorf("codecs: %T has no protobuf representation", v)
+	}
+}
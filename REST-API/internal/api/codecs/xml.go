@@ -0,0 +1,24 @@
+package codecs
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// XML encodes using the struct tags on the models, so attributes such as id="..." survive for legacy clients.
+type XML struct{}
+
+func (XML) MediaTypes() []string {
+	return []string{"application/xml", "text/xml"}
+}
+
+func (XML) Encode(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(v)
+}
+
+func (XML) Decode(r io.Reader, v any) error {
+	return xml.NewDecoder(r).Decode(v)
+}
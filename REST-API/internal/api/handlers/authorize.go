@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"pkg/auth"
+	"pkg/rbac"
+)
+
+// Authorizer checks the rbac policy for the caller put in the context by the auth middleware.
+type Authorizer struct {
+	Policy *rbac.Engine
+}
+
+// Allow answers 403 and returns false when the caller may not perform action on res.
+func (a Authorizer) Allow(w http.ResponseWriter, r *http.Request, action string, res rbac.Resource) bool {
+	p, _ := auth.FromContext(r.Context())
+	if !a.Policy.Can(p, action, res) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// Visible reports, without answering the request, whether the caller may read res. Used to filter lists.
+func (a Authorizer) Visible(r *http.Request, res rbac.Resource) bool {
+	p, _ := auth.FromContext(r.Context())
+	return a.Policy.Can(p, "read", res)
+}
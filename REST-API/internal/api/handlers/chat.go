@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"pkg/chat"
+
+	"github.com/coder/websocket"
+)
+
+type chatEvent struct {
+	ID       uint64    `json:"id"`
+	Room     string    `json:"room"`
+	Sender   string    `json:"sender"`
+	Event    string    `json:"event"` // "message", "join" or "leave"
+	Text     string    `json:"text,omitempty"`
+	SentAt   time.Time `json:"sent_at"`
+	Replayed bool      `json:"replayed,omitempty"`
+}
+
+/*
+ChatWebSocket joins the socket to a room of the chat broker, the same broker logic as the gRPC Chat stream.
+/chat?room=<room>&name=<name>, every text frame received is published to the room and every room
+event is sent back as a JSON text frame.
+- The server pings every Heartbeat and gives up on a client that doesn't pong within WriteTimeout
+- A client too slow to drain its broker buffer is evicted and closed with 1008 (policy violation)
+- Closing the socket or the request context stops both the reader and the writer
+*/
+type ChatWebSocket struct {
+	Broker       *chat.Broker
+	Heartbeat    time.Duration
+	WriteTimeout time.Duration
+}
+
+func (h *ChatWebSocket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	roomName, name := r.URL.Query().Get("room"), r.URL.Query().Get("name")
+	if roomName == "" {
+		roomName = "lobby"
+	}
+	if name == "" {
+		name = "anonymous"
+	}
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		log.Println("WebSocket upgrade failed:", err)
+		return
+	}
+	defer conn.CloseNow()
+
+	sub, err := h.Broker.Join(roomName, name)
+	if errors.Is(err, chat.ErrTooManyRooms) {
+		conn.Close(websocket.StatusTryAgainLater, err.Error())
+		return
+	}
+	if err != nil {
+		conn.Close(websocket.StatusPolicyViolation, err.Error())
+		return
+	}
+	defer sub.Leave()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go h.writeLoop(ctx, cancel, conn, sub)
+
+	for {
+		msgType, data, err := conn.Read(ctx)
+		if err != nil {
+			if websocket.CloseStatus(err) != websocket.StatusNormalClosure && !errors.Is(err, context.Canceled) {
+				log.Println("WebSocket read:", err)
+			}
+			return
+		}
+		if msgType != websocket.MessageText {
+			conn.Close(websocket.StatusUnsupportedData, "text messages only")
+			return
+		}
+		h.Broker.Publish(roomName, name, string(data))
+	}
+}
+
+func (h *ChatWebSocket) writeLoop(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn, sub *chat.Subscription) {
+	defer cancel()
+
+	heartbeat := time.NewTicker(h.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-sub.C:
+			if !ok {
+				if errors.Is(sub.Err(), chat.ErrSlowConsumer) {
+					conn.Close(websocket.StatusPolicyViolation, "client is reading too slowly")
+				}
+				return
+			}
+			data, err := json.Marshal(toChatEvent(msg))
+			if err != nil {
+				log.Println("Encoding chat event:", err)
+				continue
+			}
+
+			writeCtx, done := context.WithTimeout(ctx, h.WriteTimeout)
+			err = conn.Write(writeCtx, websocket.MessageText, data)
+			done()
+			if err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			pingCtx, done := context.WithTimeout(ctx, h.WriteTimeout)
+			err := conn.Ping(pingCtx)
+			done()
+			if err != nil {
+				conn.Close(websocket.StatusGoingAway, "heartbeat timed out")
+				return
+			}
+		}
+	}
+}
+
+func toChatEvent(m chat.Message) chatEvent {
+	event := "message"
+	switch m.Kind {
+	case chat.KindJoin:
+		event = "join"
+	case chat.KindLeave:
+		event = "leave"
+	}
+	return chatEvent{ID: m.ID, Room: m.Room, Sender: m.Sender, Event: event, Text: m.Text, SentAt: m.SentAt, Replayed: m.Replayed}
+}
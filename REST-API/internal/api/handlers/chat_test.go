@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"pkg/chat"
+
+	"github.com/coder/websocket"
+)
+
+func startChat(t *testing.T, h *ChatWebSocket) (url string, done <-chan struct{}) {
+	t.Helper()
+	finished := make(chan struct{}, 16)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() { finished <- struct{}{} }()
+		h.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http"), finished
+}
+
+func dialChat(t *testing.T, url, room, name string) *websocket.Conn {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, url+"?room="+room+"&name="+name, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetReadLimit(-1)
+	t.Cleanup(func() { conn.CloseNow() })
+	return conn
+}
+
+// readEvent reads the next event, failing the test after a second without one
+func readEvent(t *testing.T, conn *websocket.Conn) chatEvent {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, data, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var ev chatEvent
+	if err := json.Unmarshal(data, &ev); err != nil {
+		t.Fatal(err)
+	}
+	return ev
+}
+
+// waitFor polls cond, the broker state follows the sockets asynchronously
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
+
+func TestChatWebSocketRelaysRoomEvents(t *testing.T) {
+	broker := chat.NewBroker(chat.BrokerConfig{BufferSize: 16, HistorySize: 10})
+	url, _ := startChat(t, &ChatWebSocket{Broker: broker, Heartbeat: time.Hour, WriteTimeout: time.Second})
+
+	alice := dialChat(t, url, "go", "alice")
+	if ev := readEvent(t, alice); ev.Event != "join" || ev.Sender != "alice" {
+		t.Fatalf("alice's first event = %+v; want her own join", ev)
+	}
+	bob := dialChat(t, url, "go", "bob")
+	if ev := readEvent(t, alice); ev.Event != "join" || ev.Sender != "bob" {
+		t.Fatalf("alice got %+v; want bob's join", ev)
+	}
+	readEvent(t, bob)
+
+	if err := bob.Write(context.Background(), websocket.MessageText, []byte("hi")); err != nil {
+		t.Fatal(err)
+	}
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		if ev := readEvent(t, conn); ev.Event != "message" || ev.Sender != "bob" || ev.Text != "hi" || ev.Room != "go" {
+			t.Errorf("event = %+v; want bob's message", ev)
+		}
+	}
+
+	bob.Close(websocket.StatusNormalClosure, "")
+	if ev := readEvent(t, alice); ev.Event != "leave" || ev.Sender != "bob" {
+		t.Errorf("alice got %+v; want bob's leave", ev)
+	}
+}
+
+func TestChatWebSocketRejectsBinaryFrames(t *testing.T) {
+	broker := chat.NewBroker(chat.BrokerConfig{BufferSize: 16})
+	url, _ := startChat(t, &ChatWebSocket{Broker: broker, Heartbeat: time.Hour, WriteTimeout: time.Second})
+
+	conn := dialChat(t, url, "go", "alice")
+	readEvent(t, conn)
+	if err := conn.Write(context.Background(), websocket.MessageBinary, []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, _, err := conn.Read(ctx); websocket.CloseStatus(err) != websocket.StatusUnsupportedData {
+		t.Errorf("read after a binary frame = %v; want close 1003", err)
+	}
+}
+
+func TestChatWebSocketLeavesWhenClientDisconnects(t *testing.T) {
+	broker := chat.NewBroker(chat.BrokerConfig{BufferSize: 16})
+	url, done := startChat(t, &ChatWebSocket{Broker: broker, Heartbeat: time.Hour, WriteTimeout: time.Second})
+
+	conn := dialChat(t, url, "go", "alice")
+	readEvent(t, conn)
+	if members := broker.Members("go"); !slices.Equal(members, []string{"alice"}) {
+		t.Fatalf("members = %v; want alice", members)
+	}
+
+	// Gone without a close handshake, the way a dropped connection looks
+	conn.CloseNow()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the handler kept running after the client went away")
+	}
+	if members := broker.Members("go"); len(members) != 0 {
+		t.Errorf("members = %v; want the room empty", members)
+	}
+}
+
+func TestChatWebSocketEvictsSlowConsumer(t *testing.T) {
+	broker := chat.NewBroker(chat.BrokerConfig{BufferSize: 4})
+	url, _ := startChat(t, &ChatWebSocket{Broker: broker, Heartbeat: time.Hour, WriteTimeout: 10 * time.Second})
+
+	slow := dialChat(t, url, "go", "slow")
+	readEvent(t, slow)
+
+	// slow stops reading: once the socket buffers are full the writer blocks, the broker buffer
+	// fills up behind it and the next publish evicts the subscriber instead of waiting for it
+	text := strings.Repeat("x", 64<<10)
+	waitFor(t, "the slow consumer to be evicted", func() bool {
+		if _, err := broker.Publish("go", "fast", text); err != nil {
+			t.Fatal(err)
+		}
+		return !slices.Contains(broker.Members("go"), "slow")
+	})
+
+	// Draining now reaches the close frame queued behind the buffered messages
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for {
+		_, _, err := slow.Read(ctx)
+		if err == nil {
+			continue
+		}
+		if websocket.CloseStatus(err) != websocket.StatusPolicyViolation {
+			t.Errorf("slow consumer ended with %v; want close 1008", err)
+		}
+		return
+	}
+}
+
+func TestChatWebSocketTooManyRooms(t *testing.T) {
+	broker := chat.NewBroker(chat.BrokerConfig{BufferSize: 16, MaxRooms: 1})
+	url, _ := startChat(t, &ChatWebSocket{Broker: broker, Heartbeat: time.Hour, WriteTimeout: time.Second})
+
+	readEvent(t, dialChat(t, url, "one", "alice"))
+
+	conn := dialChat(t, url, "two", "bob")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, _, err := conn.Read(ctx); websocket.CloseStatus(err) != websocket.StatusTryAgainLater {
+		t.Errorf("joining a room past MaxRooms = %v; want close 1013", err)
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pkg/calculator"
+)
+
+const maxFibonacciCount = 10000
+
+/*
+FibonacciSSE streams calculator.Fibonacci as Server-Sent Events, one "number" event per value.
+- Interval paces the events like the gRPC GenerateFibonacci stream does
+- A ": ping" comment goes out every Heartbeat so proxies keep the idle connection open
+- Writes block on a slow client instead of buffering, after WriteTimeout without progress the client is dropped
+- The request context ends the stream as soon as the client disconnects
+*/
+type FibonacciSSE struct {
+	Interval     time.Duration
+	Heartbeat    time.Duration
+	WriteTimeout time.Duration
+}
+
+func (h *FibonacciSSE) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+	if err != nil || count < 1 || count > maxFibonacciCount {
+		http.Error(w, fmt.Sprintf("count must be between 1 and %d", maxFibonacciCount), http.StatusBadRequest)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// send writes one chunk and flushes it, failing if the client doesn't read it within WriteTimeout
+	send := func(chunk string) error {
+		if err := rc.SetWriteDeadline(time.Now().Add(h.WriteTimeout)); err != nil && err != http.ErrNotSupported {
+			return err
+		}
+		if _, err := fmt.Fprint(w, chunk); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	pace := time.NewTicker(h.Interval)
+	defer pace.Stop()
+	heartbeat := time.NewTicker(h.Heartbeat)
+	defer heartbeat.Stop()
+
+	for index, number := range calculator.Fibonacci(count) {
+		// Decimal string, the values outgrow any JavaScript number long before count does
+		if err := send(fmt.Sprintf("id: %d\nevent: number\ndata: %s\n\n", index, number)); err != nil {
+			log.Println("SSE client dropped:", err)
+			return
+		}
+		if index == count-1 {
+			break
+		}
+
+	wait:
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-heartbeat.C:
+				if err := send(": ping\n\n"); err != nil {
+					log.Println("SSE client dropped:", err)
+					return
+				}
+			case <-pace.C:
+				break wait
+			}
+		}
+	}
+
+	send("event: done\ndata: {}\n\n")
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sseEvent is one event of a text/event-stream, comments like ": ping" come as an event of their own
+type sseEvent struct {
+	id, event, data, comment string
+}
+
+// readEvents parses the stream until it ends or stop returns true for an event
+func readEvents(t *testing.T, res *http.Response, stop func(sseEvent) bool) []sseEvent {
+	t.Helper()
+	var events []sseEvent
+	var ev sseEvent
+	lines := bufio.NewScanner(res.Body)
+	for lines.Scan() {
+		line := lines.Text()
+		switch {
+		case line == "":
+			events = append(events, ev)
+			if stop != nil && stop(ev) {
+				return events
+			}
+			ev = sseEvent{}
+		case strings.HasPrefix(line, ":"):
+			ev.comment = strings.TrimSpace(line[1:])
+		case strings.HasPrefix(line, "id: "):
+			ev.id = line[len("id: "):]
+		case strings.HasPrefix(line, "event: "):
+			ev.event = line[len("event: "):]
+		case strings.HasPrefix(line, "data: "):
+			ev.data = line[len("data: "):]
+		default:
+			t.Fatalf("unexpected line %q", line)
+		}
+	}
+	return events
+}
+
+func getSSE(t *testing.T, ctx context.Context, url string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { res.Body.Close() })
+	if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("GET %s = %d %s; want 200 text/event-stream", url, res.StatusCode, res.Header.Get("Content-Type"))
+	}
+	return res
+}
+
+func TestFibonacciSSERejectsBadCount(t *testing.T) {
+	h := &FibonacciSSE{Interval: time.Millisecond, Heartbeat: time.Second, WriteTimeout: time.Second}
+	for _, count := range []string{"", "0", "-1", "10001", "ten"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fibonacci?count="+count, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("count=%q: status = %d; want 400", count, rec.Code)
+		}
+	}
+}
+
+func TestFibonacciSSEStreamsNumbers(t *testing.T) {
+	srv := httptest.NewServer(&FibonacciSSE{Interval: time.Millisecond, Heartbeat: time.Hour, WriteTimeout: time.Second})
+	defer srv.Close()
+
+	var numbers []string
+	var last sseEvent
+	for _, ev := range readEvents(t, getSSE(t, context.Background(), srv.URL+"?count=7"), nil) {
+		if ev.event == "number" {
+			numbers = append(numbers, ev.data)
+		}
+		last = ev
+	}
+	if want := []string{"0", "1", "1", "2", "3", "5", "8"}; !slices.Equal(numbers, want) {
+		t.Errorf("numbers = %v; want %v", numbers, want)
+	}
+	if last.event != "done" {
+		t.Errorf("last event = %+v; want done", last)
+	}
+}
+
+func TestFibonacciSSEHeartbeat(t *testing.T) {
+	srv := httptest.NewServer(&FibonacciSSE{Interval: 200 * time.Millisecond, Heartbeat: 10 * time.Millisecond, WriteTimeout: time.Second})
+	defer srv.Close()
+
+	// The pings fill the pause between the two numbers
+	events := readEvents(t, getSSE(t, context.Background(), srv.URL+"?count=2"), nil)
+	var pings int
+	for _, ev := range events[1 : len(events)-2] {
+		if ev.comment != "ping" {
+			t.Fatalf("event between the numbers = %+v; want only pings", ev)
+		}
+		pings++
+	}
+	if pings < 3 {
+		t.Errorf("%d pings in 200ms with a 10ms heartbeat; want several", pings)
+	}
+}
+
+func TestFibonacciSSEStopsWhenClientDisconnects(t *testing.T) {
+	done := make(chan struct{})
+	h := &FibonacciSSE{Interval: time.Hour, Heartbeat: time.Hour, WriteTimeout: time.Second}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		h.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	res := getSSE(t, ctx, srv.URL+"?count=100")
+	readEvents(t, res, func(ev sseEvent) bool { return ev.event == "number" })
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the handler kept streaming after the client went away")
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+
+	"restapi/internal/models"
+	"restapi/internal/repositories"
+
+	"pkg/rbac"
+)
+
+type OrdersHandler struct {
+	Negotiator
+	Authorizer
+	Store *repositories.MemoryStore
+}
+
+// List only returns the orders the caller is allowed to read.
+func (h *OrdersHandler) List(w http.ResponseWriter, r *http.Request) {
+	var orders []models.Order
+	for _, o := range h.Store.ListOrders() {
+		if h.Visible(r, orderResource(o)) {
+			orders = append(orders, o)
+		}
+	}
+	h.Respond(w, r, http.StatusOK, &models.OrderList{Orders: orders})
+}
+
+/*
+Get answers 404 both for a missing order and for one the caller may not read.
+Ownership is only known after the lookup, so a 403 would tell callers which order IDs exist.
+*/
+func (h *OrdersHandler) Get(w http.ResponseWriter, r *http.Request) {
+	order, err := h.Store.GetOrder(r.PathValue("id"))
+	if err != nil || !h.Visible(r, orderResource(order)) {
+		http.Error(w, "Order not found", http.StatusNotFound)
+		return
+	}
+	h.Respond(w, r, http.StatusOK, &order)
+}
+
+func (h *OrdersHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var order models.Order
+	if !h.Bind(w, r, &order) {
+		return
+	}
+	if order.UserID == "" || order.Item == "" || order.Quantity <= 0 {
+		http.Error(w, "user_id, item and a positive quantity are required", http.StatusBadRequest)
+		return
+	}
+	if !h.Allow(w, r, "create", orderResource(order)) {
+		return
+	}
+	if _, err := h.Store.GetUser(order.UserID); err != nil {
+		http.Error(w, "Unknown user_id", http.StatusBadRequest)
+		return
+	}
+
+	created := h.Store.CreateOrder(order)
+	h.Respond(w, r, http.StatusCreated, &created)
+}
+
+func orderResource(o models.Order) rbac.Resource {
+	return rbac.Resource{Type: "orders", ID: o.ID, OwnerID: o.UserID}
+}
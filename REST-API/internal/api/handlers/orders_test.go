@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"restapi/internal/api/codecs"
+	"restapi/internal/repositories"
+
+	"pkg/auth"
+	"pkg/rbac"
+)
+
+const testPolicy = `
+roles:
+  admin:
+    permissions: ["orders:*"]
+  customer:
+    permissions: ["orders:read:own"]
+`
+
+func newOrdersHandler(t *testing.T) *OrdersHandler {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(testPolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := rbac.NewEngine(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &OrdersHandler{
+		Negotiator: Negotiator{Codecs: codecs.Default()},
+		Authorizer: Authorizer{Policy: policy},
+		Store:      repositories.NewMemoryStore(),
+	}
+}
+
+func TestOrdersGetHidesOtherUsersOrders(t *testing.T) {
+	h := newOrdersHandler(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orders/{id}", h.Get)
+
+	tests := []struct {
+		name string
+		p    *auth.Principal
+		id   string
+		code int
+	}{
+		{"owner", &auth.Principal{Subject: "u1", Roles: []string{"customer"}}, "o1", http.StatusOK},
+		{"someone else's order", &auth.Principal{Subject: "u1", Roles: []string{"customer"}}, "o2", http.StatusNotFound},
+		{"missing order", &auth.Principal{Subject: "u1", Roles: []string{"customer"}}, "o404", http.StatusNotFound},
+		{"no role", &auth.Principal{Subject: "u1"}, "o1", http.StatusNotFound},
+		{"admin", &auth.Principal{Subject: "root", Roles: []string{"admin"}}, "o2", http.StatusOK},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/orders/"+tt.id, nil)
+		r = r.WithContext(auth.NewContext(r.Context(), tt.p))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, r)
+		if rec.Code != tt.code {
+			t.Errorf("%s: status = %d; want %d", tt.name, rec.Code, tt.code)
+		}
+		if tt.code == http.StatusNotFound && rec.Body.String() != "Order not found\n" {
+			t.Errorf("%s: body = %q; forbidden and missing must look the same", tt.name, rec.Body.String())
+		}
+	}
+}
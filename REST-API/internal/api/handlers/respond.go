@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"restapi/internal/api/codecs"
+)
+
+// maxBodyBytes caps request bodies regardless of the codec used to read them.
+const maxBodyBytes = 1 << 20
+
+// Negotiator encodes responses from the Accept header and decodes requests from the Content-Type header.
+type Negotiator struct {
+	Codecs *codecs.Registry
+}
+
+/*
+Respond writes v with the codec chosen from the Accept header.
+If no registered codec is acceptable the client gets 406 Not Acceptable before anything is written.
+*/
+func (n Negotiator) Respond(w http.ResponseWriter, r *http.Request, status int, v any) {
+	codec, mediaType, err := n.Codecs.Negotiate(r.Header.Get("Accept"))
+	w.Header().Add("Vary", "Accept")
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+		return
+	}
+
+	w.Header().Set("Content-Type", mediaType)
+	w.WriteHeader(status)
+	if err := codec.Encode(w, v); err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+/*
+Bind decodes the request body into v with the codec registered for its Content-Type.
+On failure it has already written 415 Unsupported Media Type or 400 Bad Request and returns false.
+*/
+func (n Negotiator) Bind(w http.ResponseWriter, r *http.Request, v any) bool {
+	codec, err := n.Codecs.ForContentType(r.Header.Get("Content-Type"))
+	if errors.Is(err, codecs.ErrUnsupportedMediaType) {
+		http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+		return false
+	}
+
+	if err := codec.Decode(http.MaxBytesReader(w, r.Body, maxBodyBytes), v); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"restapi/internal/api/codecs"
+	"restapi/internal/models"
+)
+
+func TestRespond(t *testing.T) {
+	n := Negotiator{Codecs: codecs.Default()}
+	user := &models.User{ID: "u1", Name: "John", Email: "john@email.com"}
+
+	tests := []struct {
+		accept      string
+		code        int
+		contentType string
+		body        string // substring of the body
+	}{
+		{"", http.StatusCreated, "application/json", `"name":"John"`},
+		{"application/xml", http.StatusCreated, "application/xml", `<?xml`},
+		{"application/json;q=0, text/*", http.StatusCreated, "text/xml", `John`},
+		{"application/x-protobuf", http.StatusCreated, "application/x-protobuf", "John"},
+		{"image/png", http.StatusNotAcceptable, "text/plain; charset=utf-8", "Not Acceptable"},
+		{"*/*;q=0", http.StatusNotAcceptable, "text/plain; charset=utf-8", "Not Acceptable"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/users/u1", nil)
+		r.Header.Set("Accept", tt.accept)
+		rec := httptest.NewRecorder()
+		n.Respond(rec, r, http.StatusCreated, user)
+
+		if rec.Code != tt.code || rec.Header().Get("Content-Type") != tt.contentType {
+			t.Errorf("Accept %q: %d %s; want %d %s", tt.accept, rec.Code, rec.Header().Get("Content-Type"), tt.code, tt.contentType)
+		}
+		if !strings.Contains(rec.Body.String(), tt.body) {
+			t.Errorf("Accept %q: body %q does not contain %q", tt.accept, rec.Body.String(), tt.body)
+		}
+		if rec.Header().Get("Vary") != "Accept" {
+			t.Errorf("Accept %q: Vary = %q; want Accept on every answer", tt.accept, rec.Header().Get("Vary"))
+		}
+	}
+}
+
+func TestBind(t *testing.T) {
+	n := Negotiator{Codecs: codecs.Default()}
+
+	tests := []struct {
+		contentType string
+		body        string
+		ok          bool
+		code        int
+	}{
+		{"application/json", `{"name": "Jane"}`, true, http.StatusOK},
+		{"application/json; charset=utf-8", `{"name": "Jane"}`, true, http.StatusOK},
+		{"application/xml", `<user><name>Jane</name></user>`, true, http.StatusOK},
+		{"text/plain", `Jane`, false, http.StatusUnsupportedMediaType},
+		{"", `{"name": "Jane"}`, false, http.StatusUnsupportedMediaType},
+		{"application/json", `{"name": `, false, http.StatusBadRequest},
+		{"application/json", `{"name": "` + strings.Repeat("a", maxBodyBytes) + `"}`, false, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(tt.body))
+		r.Header.Set("Content-Type", tt.contentType)
+		rec := httptest.NewRecorder()
+
+		var user models.User
+		ok := n.Bind(rec, r, &user)
+		if ok != tt.ok || rec.Code != tt.code {
+			t.Errorf("Content-Type %q: ok = %v, status %d; want %v, %d", tt.contentType, ok, rec.Code, tt.ok, tt.code)
+		}
+		if ok && user.Name != "Jane" {
+			t.Errorf("Content-Type %q: decoded %+v", tt.contentType, user)
+		}
+	}
+}
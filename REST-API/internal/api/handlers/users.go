@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+
+	"restapi/internal/models"
+	"restapi/internal/repositories"
+
+	"pkg/rbac"
+)
+
+type UsersHandler struct {
+	Negotiator
+	Authorizer
+	Store *repositories.MemoryStore
+}
+
+// List only returns the users the caller is allowed to read.
+func (h *UsersHandler) List(w http.ResponseWriter, r *http.Request) {
+	var users []models.User
+	for _, u := range h.Store.ListUsers() {
+		if h.Visible(r, userResource(u.ID)) {
+			users = append(users, u)
+		}
+	}
+	h.Respond(w, r, http.StatusOK, &models.UserList{Users: users})
+}
+
+func (h *UsersHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !h.Allow(w, r, "read", userResource(id)) {
+		return
+	}
+
+	user, err := h.Store.GetUser(id)
+	if err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	h.Respond(w, r, http.StatusOK, &user)
+}
+
+func (h *UsersHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if !h.Allow(w, r, "create", rbac.Resource{Type: "users"}) {
+		return
+	}
+
+	var user models.User
+	if !h.Bind(w, r, &user) {
+		return
+	}
+	if user.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+
+	created := h.Store.CreateUser(user)
+	h.Respond(w, r, http.StatusCreated, &created)
+}
+
+// A user record is owned by the user itself.
+func userResource(id string) rbac.Resource {
+	return rbac.Resource{Type: "users", ID: id, OwnerID: id}
+}
@@ -0,0 +1,286 @@
+package middlewares
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+type CompressionConfig struct {
+	// Responses smaller than MinSize are sent as is, compressing them costs more than it saves.
+	MinSize int
+	// ContentTypes lists the media types worth compressing, an entry ending in "/" matches a whole
+	// family such as "text/". Images, archives and protobuf are usually already dense.
+	ContentTypes []string
+	// Zstd adds zstd to the negotiable encodings, it is preferred over gzip when the client accepts both.
+	Zstd bool
+}
+
+func DefaultCompressionConfig() CompressionConfig {
+	return CompressionConfig{
+		MinSize:      1024,
+		ContentTypes: []string{"text/", "application/json", "application/xml"},
+	}
+}
+
+/*
+Compression negotiates gzip, deflate (and zstd if enabled) from the Accept-Encoding header.
+  - The body is buffered up to MinSize before deciding, so small responses keep their Content-Length
+  - Range requests, 206 responses and responses that already set Content-Encoding pass through untouched,
+    their byte offsets refer to the identity encoding
+  - Flush is honoured, buffered and compressed bytes are pushed to the client so streaming keeps working
+
+Compressors are recycled through a sync.Pool per encoding.
+*/
+func Compression(cfg CompressionConfig) func(http.Handler) http.Handler {
+	encodings := []string{"gzip", "deflate"}
+	if cfg.Zstd {
+		encodings = append([]string{"zstd"}, encodings...)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), encodings)
+			if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, cfg: &cfg, encoding: encoding, status: http.StatusOK}
+			defer cw.Close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding returns the first of supported (in server preference order) with a non-zero q value.
+func negotiateEncoding(header string, supported []string) string {
+	accepted := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if key, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(key) == "q" {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		accepted[strings.ToLower(name)] = q
+	}
+
+	for _, enc := range supported {
+		q, ok := accepted[enc]
+		if !ok {
+			q, ok = accepted["*"]
+		}
+		if ok && q > 0 {
+			return enc
+		}
+	}
+	return ""
+}
+
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// zstdCompressor adapts zstd.Encoder, whose Reset returns an error, to the compressor interface.
+type zstdCompressor struct{ *zstd.Encoder }
+
+func (z zstdCompressor) Reset(w io.Writer) { z.Encoder.Reset(w) }
+
+var compressorPools = map[string]*sync.Pool{
+	"gzip": {New: func() any {
+		return gzip.NewWriter(io.Discard)
+	}},
+	"deflate": {New: func() any {
+		fw, _ := flate.NewWriter(io.Discard, flate.DefaultCompression)
+		return fw
+	}},
+	// A single-threaded zstd.Encoder encodes synchronously in Write/Flush/Close and owns no goroutines,
+	// so pooled encoders the pool drops are simply garbage collected without ever calling Close on them.
+	"zstd": {New: func() any {
+		enc, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderLevel(zstd.SpeedDefault), zstd.WithEncoderConcurrency(1))
+		return zstdCompressor{enc}
+	}},
+}
+
+type compressWriter struct {
+	http.ResponseWriter
+	cfg      *CompressionConfig
+	encoding string
+
+	status      int
+	wroteHeader bool // WriteHeader was called by the handler
+	decided     bool // headers have been sent to the client
+	buf         []byte
+	comp        compressor
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = status
+
+	switch {
+	case status == http.StatusSwitchingProtocols:
+		// Protocol upgrade, whatever follows is not an HTTP body
+		cw.decided = true
+		cw.ResponseWriter.WriteHeader(status)
+	case status >= 100 && status < 200:
+		// Informational responses go straight out, the real status comes later
+		cw.wroteHeader = false
+		cw.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.decided {
+		if cw.comp != nil {
+			return cw.comp.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.cfg.MinSize {
+		if err := cw.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush sends everything written so far, streaming handlers never wait for MinSize.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if !cw.wroteHeader {
+			cw.WriteHeader(http.StatusOK)
+		}
+		if err := cw.decide(true); err != nil {
+			return
+		}
+	}
+	if cw.comp != nil {
+		cw.comp.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close finishes the response once the handler returned and hands the compressor back to its pool.
+func (cw *compressWriter) Close() error {
+	if !cw.decided {
+		if !cw.wroteHeader {
+			cw.WriteHeader(http.StatusOK)
+		}
+		// Whole body is buffered and under MinSize, unless the handler flushed it is not worth compressing
+		if err := cw.decide(len(cw.buf) >= cw.cfg.MinSize); err != nil {
+			return err
+		}
+	}
+	if cw.comp == nil {
+		return nil
+	}
+
+	err := cw.comp.Close()
+	cw.comp.Reset(io.Discard)
+	compressorPools[cw.encoding].Put(cw.comp)
+	cw.comp = nil
+	return err
+}
+
+// decide sends the headers, with or without Content-Encoding, and writes out the buffered bytes.
+func (cw *compressWriter) decide(bigEnough bool) error {
+	cw.decided = true
+	h := cw.Header()
+
+	if bigEnough && cw.compressible() {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", cw.encoding)
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+		cw.comp = compressorPools[cw.encoding].Get().(compressor)
+		cw.comp.Reset(cw.ResponseWriter)
+	} else if h.Get("Content-Length") == "" && !cw.wroteBodyless() {
+		h.Set("Content-Length", strconv.Itoa(len(cw.buf)))
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if cw.comp != nil {
+		_, err := cw.comp.Write(buf)
+		return err
+	}
+	_, err := cw.ResponseWriter.Write(buf)
+	return err
+}
+
+func (cw *compressWriter) compressible() bool {
+	h := cw.Header()
+	if cw.wroteBodyless() || cw.status == http.StatusPartialContent {
+		return false
+	}
+	if h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" {
+		return false
+	}
+
+	contentType := h.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(cw.buf)
+		h.Set("Content-Type", contentType)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range cw.cfg.ContentTypes {
+		if mediaType == allowed || (strings.HasSuffix(allowed, "/") && strings.HasPrefix(mediaType, allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cw *compressWriter) wroteBodyless() bool {
+	return cw.status == http.StatusNoContent || cw.status == http.StatusNotModified
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// Hijack is needed for protocol upgrades, the connection is handed over uncompressed.
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := cw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	cw.decided = true
+	return hj.Hijack()
+}
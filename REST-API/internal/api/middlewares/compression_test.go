@@ -0,0 +1,207 @@
+package middlewares
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	supported := []string{"zstd", "gzip", "deflate"}
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", "gzip"},
+		{"deflate, gzip", "gzip"},
+		{"GZIP", "gzip"},
+		{"gzip;q=0, deflate", "deflate"},
+		{"gzip; q=0.0, deflate;q=0", ""},
+		{"zstd;q=0.1, gzip", "zstd"}, // server preference wins over q values above zero
+		{"*", "zstd"},
+		{"*;q=0", ""},
+		{"zstd;q=0, *", "gzip"},
+		{"br", ""},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.header, supported); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q; want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+var largeJSON = `{"items": "` + strings.Repeat("gopher ", 500) + `"}`
+
+// serve runs handler behind Compression(cfg) for a GET with the given request headers
+func serve(cfg CompressionConfig, handler http.HandlerFunc, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for k, v := range header {
+		r.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	Compression(cfg)(handler).ServeHTTP(rec, r)
+	return rec
+}
+
+func writeJSON(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}
+}
+
+func decode(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+	var r io.Reader
+	var err error
+	switch encoding {
+	case "gzip":
+		r, err = gzip.NewReader(bytes.NewReader(body))
+	case "deflate":
+		r = flate.NewReader(bytes.NewReader(body))
+	case "zstd":
+		var dec *zstd.Decoder
+		dec, err = zstd.NewReader(bytes.NewReader(body))
+		if err == nil {
+			defer dec.Close()
+			r = dec
+		}
+	default:
+		return string(body)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("%s body: %v", encoding, err)
+	}
+	return string(out)
+}
+
+func TestCompressionNegotiates(t *testing.T) {
+	cfg := DefaultCompressionConfig()
+	cfg.Zstd = true
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		handler        http.HandlerFunc
+		want           string
+	}{
+		{"gzip", "gzip", writeJSON(http.StatusOK, largeJSON), "gzip"},
+		{"deflate", "deflate", writeJSON(http.StatusOK, largeJSON), "deflate"},
+		{"zstd preferred", "gzip, zstd", writeJSON(http.StatusOK, largeJSON), "zstd"},
+		{"q=0 refuses", "zstd;q=0, gzip;q=0, deflate;q=0", writeJSON(http.StatusOK, largeJSON), ""},
+		{"no header", "", writeJSON(http.StatusOK, largeJSON), ""},
+		{"below MinSize", "gzip", writeJSON(http.StatusOK, `{"small": true}`), ""},
+		{"not compressible", "gzip", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			io.WriteString(w, largeJSON)
+		}, ""},
+		{"already encoded", "gzip", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Encoding", "br")
+			io.WriteString(w, largeJSON)
+		}, "br"},
+		{"206", "gzip", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Range", "bytes 0-99/5000")
+			w.WriteHeader(http.StatusPartialContent)
+			io.WriteString(w, largeJSON)
+		}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(cfg, tt.handler, http.Header{"Accept-Encoding": {tt.acceptEncoding}})
+			if got := rec.Header().Get("Content-Encoding"); got != tt.want {
+				t.Fatalf("Content-Encoding = %q; want %q", got, tt.want)
+			}
+			if got := rec.Header().Values("Vary"); len(got) != 1 || got[0] != "Accept-Encoding" {
+				t.Errorf("Vary = %v; want Accept-Encoding whether or not the body was compressed", got)
+			}
+			if tt.want != "br" {
+				if body := decode(t, tt.want, rec.Body.Bytes()); body != largeJSON && body != `{"small": true}` {
+					t.Errorf("body does not round trip: %.40q", body)
+				}
+			}
+		})
+	}
+}
+
+func TestSmallResponsesKeepContentLength(t *testing.T) {
+	body := `{"small": true}`
+	rec := serve(DefaultCompressionConfig(), writeJSON(http.StatusOK, body), http.Header{"Accept-Encoding": {"gzip"}})
+	if got := rec.Header().Get("Content-Length"); got != "15" {
+		t.Errorf("Content-Length = %q; want the buffered body's length 15", got)
+	}
+}
+
+func TestCompressionSkipsRangeRequests(t *testing.T) {
+	rec := serve(DefaultCompressionConfig(), writeJSON(http.StatusOK, largeJSON), http.Header{
+		"Accept-Encoding": {"gzip"},
+		"Range":           {"bytes=0-99"},
+	})
+	if got := rec.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("Content-Encoding = %q; byte ranges refer to the identity encoding", got)
+	}
+	if rec.Body.String() != largeJSON {
+		t.Error("range request body was changed")
+	}
+}
+
+func TestCompressionFlushPassesThrough(t *testing.T) {
+	const event = "data: 1\n\n"
+	var flushed bool
+	var partial string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, event)
+		w.(http.Flusher).Flush()
+
+		// the event is far below MinSize, Flush still has to push it to the client, compressed
+		rec := w.(*compressWriter).ResponseWriter.(*httptest.ResponseRecorder)
+		flushed = rec.Flushed
+		zr, err := gzip.NewReader(bytes.NewReader(rec.Body.Bytes()))
+		if err != nil {
+			t.Errorf("flushed bytes are not a gzip stream: %v", err)
+			return
+		}
+		buf := make([]byte, len(event))
+		n, _ := io.ReadFull(zr, buf)
+		partial = string(buf[:n])
+
+		io.WriteString(w, "data: 2\n\n")
+	}
+
+	rec := serve(DefaultCompressionConfig(), handler, http.Header{"Accept-Encoding": {"gzip"}})
+	if !flushed || partial != event {
+		t.Errorf("flushed = %v, client saw %q before the handler returned; want %q", flushed, partial, event)
+	}
+	if got := decode(t, rec.Header().Get("Content-Encoding"), rec.Body.Bytes()); got != event+"data: 2\n\n" {
+		t.Errorf("full body = %q", got)
+	}
+}
+
+func TestCompressorsAreReused(t *testing.T) {
+	cfg := DefaultCompressionConfig()
+	cfg.Zstd = true
+	for range 3 {
+		for _, enc := range []string{"zstd", "gzip", "deflate"} {
+			rec := serve(cfg, writeJSON(http.StatusOK, largeJSON), http.Header{"Accept-Encoding": {enc}})
+			if got := decode(t, rec.Header().Get("Content-Encoding"), rec.Body.Bytes()); got != largeJSON {
+				t.Fatalf("%s: a pooled compressor produced a broken body", enc)
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"restapi/internal/api/codecs"
+	"restapi/internal/api/handlers"
+	"restapi/internal/repositories"
+
+	"pkg/auth"
+	"pkg/chat"
+	"pkg/rbac"
+)
+
+/*
+Router wires the handlers.
+  - /users and /orders require an authenticated caller and are checked against policy
+  - /fibonacci (SSE) and /chat (WebSocket) are public like the gRPC streams they mirror, browsers can't
+    attach an Authorization header to EventSource or WebSocket requests anyway
+*/
+func Router(store *repositories.MemoryStore, registry *codecs.Registry, authenticator *auth.Authenticator, policy *rbac.Engine, broker *chat.Broker) http.Handler {
+	negotiator := handlers.Negotiator{Codecs: registry}
+	authorizer := handlers.Authorizer{Policy: policy}
+	users := &handlers.UsersHandler{Negotiator: negotiator, Authorizer: authorizer, Store: store}
+	orders := &handlers.OrdersHandler{Negotiator: negotiator, Authorizer: authorizer, Store: store}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /users", users.List)
+	mux.HandleFunc("POST /users", users.Create)
+	mux.HandleFunc("GET /users/{id}", users.Get)
+
+	mux.HandleFunc("GET /orders", orders.List)
+	mux.HandleFunc("POST /orders", orders.Create)
+	mux.HandleFunc("GET /orders/{id}", orders.Get)
+
+	root := http.NewServeMux()
+	root.Handle("GET /fibonacci", &handlers.FibonacciSSE{
+		Interval:     time.Second,
+		Heartbeat:    15 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	})
+	root.Handle("GET /chat", &handlers.ChatWebSocket{
+		Broker:       broker,
+		Heartbeat:    15 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	})
+	root.Handle("/", auth.Middleware(authenticator)(mux))
+
+	return root
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	restapipb "restapi/proto/gen"
+
+	"google.golang.org/protobuf/proto"
+)
+
+type Order struct {
+	XMLName  xml.Name `json:"-" xml:"order"`
+	ID       string   `json:"id" xml:"id,attr"`
+	UserID   string   `json:"user_id" xml:"user_id,attr"`
+	Item     string   `json:"item" xml:"item"`
+	Quantity int32    `json:"quantity" xml:"quantity"`
+}
+
+func (o *Order) ToProto() proto.Message {
+	return &restapipb.Order{Id: o.ID, UserId: o.UserID, Item: o.Item, Quantity: o.Quantity}
+}
+
+func (o *Order) FromProto(m proto.Message) error {
+	pbOrder, ok := m.(*restapipb.Order)
+	if !ok {
+		return fmt.Errorf("models: expected *restapipb.Order, got %T", m)
+	}
+	o.ID, o.UserID, o.Item, o.Quantity = pbOrder.GetId(), pbOrder.GetUserId(), pbOrder.GetItem(), pbOrder.GetQuantity()
+	return nil
+}
+
+type OrderList struct {
+	XMLName xml.Name `json:"-" xml:"orders"`
+	Orders  []Order  `json:"orders" xml:"order"`
+}
+
+func (l *OrderList) ToProto() proto.Message {
+	pbList := &restapipb.OrderList{}
+	for i := range l.Orders {
+		pbList.Orders = append(pbList.Orders, l.Orders[i].ToProto().(*restapipb.Order))
+	}
+	return pbList
+}
+
+func (l *OrderList) FromProto(m proto.Message) error {
+	pbList, ok := m.(*restapipb.OrderList)
+	if !ok {
+		return fmt.Errorf("models: expected *restapipb.OrderList, got %T", m)
+	}
+	l.Orders = make([]Order, len(pbList.GetOrders()))
+	for i, pbOrder := range pbList.GetOrders() {
+		if err := l.Orders[i].FromProto(pbOrder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	restapipb "restapi/proto/gen"
+
+	"google.golang.org/protobuf/proto"
+)
+
+type User struct {
+	XMLName xml.Name `json:"-" xml:"user"`
+	ID      string   `json:"id" xml:"id,attr"` // attribute, so legacy XML clients see <user id="...">
+	Name    string   `json:"name" xml:"name"`
+	Email   string   `json:"email,omitempty" xml:"email,omitempty"`
+}
+
+func (u *User) ToProto() proto.Message {
+	return &restapipb.User{Id: u.ID, Name: u.Name, Email: u.Email}
+}
+
+func (u *User) FromProto(m proto.Message) error {
+	pbUser, ok := m.(*restapipb.User)
+	if !ok {
+		return fmt.Errorf("models: expected *restapipb.User, got %T", m)
+	}
+	u.ID, u.Name, u.Email = pbUser.GetId(), pbUser.GetName(), pbUser.GetEmail()
+	return nil
+}
+
+type UserList struct {
+	XMLName xml.Name `json:"-" xml:"users"`
+	Users   []User   `json:"users" xml:"user"`
+}
+
+func (l *UserList) ToProto() proto.Message {
+	pbList := &restapipb.UserList{}
+	for i := range l.Users {
+		pbList.Users = append(pbList.Users, l.Users[i].ToProto().(*restapipb.User))
+	}
+	return pbList
+}
+
+func (l *UserList) FromProto(m proto.Message) error {
+	pbList, ok := m.(*restapipb.UserList)
+	if !ok {
+		return fmt.Errorf("models: expected *restapipb.UserList, got %T", m)
+	}
+	l.Users = make([]User, len(pbList.GetUsers()))
+	for i, pbUser := range pbList.GetUsers() {
+		if err := l.Users[i].FromProto(pbUser); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"restapi/internal/models"
+)
+
+var ErrNotFound = errors.New("repositories: not found")
+
+// MemoryStore keeps users and orders in memory, good enough until a real database is wired in.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	users  map[string]models.User
+	orders map[string]models.Order
+	nextID int
+}
+
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{
+		users:  make(map[string]models.User),
+		orders: make(map[string]models.Order),
+	}
+	s.users["u1"] = models.User{ID: "u1", Name: "John", Email: "john@email.com"}
+	s.users["u2"] = models.User{ID: "u2", Name: "Jane", Email: "jane@email.com"}
+	s.orders["o1"] = models.Order{ID: "o1", UserID: "u1", Item: "Go BootCamp", Quantity: 1}
+	s.orders["o2"] = models.Order{ID: "o2", UserID: "u2", Item: "Gopher Plush", Quantity: 2}
+	return s
+}
+
+func (s *MemoryStore) ListUsers() []models.User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]models.User, 0, len(s.users))
+	for _, u := range s.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users
+}
+
+func (s *MemoryStore) GetUser(id string) (models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.users[id]
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+	return u, nil
+}
+
+func (s *MemoryStore) CreateUser(u models.User) models.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u.ID = s.newID("u")
+	s.users[u.ID] = u
+	return u
+}
+
+func (s *MemoryStore) ListOrders() []models.Order {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	orders := make([]models.Order, 0, len(s.orders))
+	for _, o := range s.orders {
+		orders = append(orders, o)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	return orders
+}
+
+func (s *MemoryStore) GetOrder(id string) (models.Order, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	o, ok := s.orders[id]
+	if !ok {
+		return models.Order{}, ErrNotFound
+	}
+	return o, nil
+}
+
+func (s *MemoryStore) CreateOrder(o models.Order) models.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o.ID = s.newID("o")
+	s.orders[o.ID] = o
+	return o
+}
+
+// newID must be called with the write lock held.
+func (s *MemoryStore) newID(prefix string) string {
+	for {
+		s.nextID++
+		id := fmt.Sprintf("%s%d", prefix, s.nextID)
+		if _, taken := s.users[id]; taken {
+			continue
+		}
+		if _, taken := s.orders[id]; taken {
+			continue
+		}
+		return id
+	}
+}
@@ -1,10 +1,22 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"time"
+
+	"restapi/internal/api"
+	"restapi/internal/api/codecs"
+	"restapi/internal/api/middlewares"
+	"restapi/internal/repositories"
+
+	"pkg/auth"
+	"pkg/chat"
+	"pkg/rbac"
 
 	"golang.org/x/net/http2"
 )
@@ -21,15 +33,25 @@ import (
 
 func main() {
 
-	http.HandleFunc("/orders", func(w http.ResponseWriter, r *http.Request) {
-		logRequestDetails(r)
-		fmt.Fprintf(w, "Handling incoming orders.")
-	})
+	/*
+		Responses are encoded from the Accept header and request bodies decoded from the Content-Type header.
+		JSON, XML and Protobuf are registered by default, more codecs can be added with registry.Register
+	*/
+	registry := codecs.Default()
+	policy, err := rbac.NewEngine("policy.yaml", rbac.NewJSONLogger(os.Stdout))
+	if err != nil {
+		log.Fatalln("Couldn't load access policy:", err)
+	}
+	go policy.WatchFile(context.Background(), 5*time.Second)
 
-	http.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
-		logRequestDetails(r)
-		fmt.Fprintf(w, "Handling users.")
-	})
+	// /chat rooms, same broker as the gRPC Chat stream
+	broker := chat.NewBroker(chat.BrokerConfig{BufferSize: 64, HistorySize: 50})
+
+	router := api.Router(repositories.NewMemoryStore(), registry, loadAuthenticator(), policy, broker)
+
+	// Negotiated gzip/deflate compression, zstd only when COMPRESSION_ZSTD=1
+	compression := middlewares.DefaultCompressionConfig()
+	compression.Zstd = os.Getenv("COMPRESSION_ZSTD") == "1"
 
 	serveAddr := "127.0.0.1:3000"
 
@@ -58,16 +80,25 @@ func main() {
 	// Create a custom server
 	server := &http.Server{
 		Addr:      serveAddr,
-		Handler:   nil,
+		Handler:   logRequests(middlewares.Compression(compression)(router)),
 		TLSConfig: tlsConfig,
 	}
 
-	// Enable http2
-	http2.ConfigureServer(server, &http2.Server{})
+	/*
+		Enable http2
+		- MaxConcurrentStreams bounds how many requests one client connection can have in flight
+		- Server push is left off, browsers dropped support for it; clients should rely on preload links instead
+		- IdleTimeout closes connections nobody has used for a while so they don't pin memory
+	*/
+	http2.ConfigureServer(server, &http2.Server{
+		MaxConcurrentStreams: 250,
+		MaxReadFrameSize:     1 << 20,
+		IdleTimeout:          2 * time.Minute,
+	})
 
 	fmt.Println("Server is running on:", serveAddr)
 
-	err := server.ListenAndServeTLS(cert, key)
+	err = server.ListenAndServeTLS(cert, key)
 	if err != nil {
 		log.Fatalln("Couldn't start the server:", err)
 	}
@@ -79,6 +110,39 @@ func main() {
 
 }
 
+/*
+Callers authenticate with either
+- Authorization: Bearer <jwt> signed by a key in keys/ (HS256 *.hmac, RS256/EdDSA *.pem) for audience "restapi"
+- X-API-Key: <key> whose sha256 hash is listed in apikeys.json
+Neither is checked in, generate them inside pkg/ before the first start:
+
+	go run ./cmd/mint-token -new-hmac ../REST-API/keys/dev.hmac
+	go run ./cmd/mint-token -new-api-key ../REST-API/apikeys.json -id dev-admin -sub admin -roles admin
+*/
+func loadAuthenticator() *auth.Authenticator {
+	keys, err := auth.LoadKeySet("keys")
+	if err != nil {
+		log.Fatalln("Couldn't load JWT keys:", err)
+	}
+
+	apiKeys, err := auth.LoadAPIKeys("apikeys.json")
+	if err != nil {
+		log.Fatalln("Couldn't load API keys:", err)
+	}
+
+	return &auth.Authenticator{
+		JWT:     &auth.Verifier{Keys: keys, Audience: "restapi", Leeway: 30 * time.Second},
+		APIKeys: apiKeys,
+	}
+}
+
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logRequestDetails(r)
+		next.ServeHTTP(w, r)
+	})
+}
+
 func logRequestDetails(r *http.Request) {
 	httpVersion := r.Proto
 	fmt.Println("Received request with HTTP Version:", httpVersion)
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "simplegPRCServer/proto/gen"
+
+	"pkg/auth"
+	"pkg/balancer"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// backend is an in-process Calculate server counting the calls it gets
+type backend struct {
+	addr  string
+	calls atomic.Int64
+	delay time.Duration
+	fail  atomic.Bool
+}
+
+// startCalculators runs n Calculate servers on loopback ports, without TLS
+func startCalculators(t *testing.T, n int) []*backend {
+	t.Helper()
+
+	backends := make([]*backend, n)
+	for i := range backends {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := &backend{addr: lis.Addr().String()}
+		backends[i] = b
+
+		grpcServer := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			b.calls.Add(1)
+			time.Sleep(b.delay)
+			if b.fail.Load() {
+				return nil, status.Error(codes.Unavailable, "injected fault")
+			}
+			// Stands in for the auth interceptors, Add logs the caller
+			return handler(auth.NewContext(ctx, &auth.Principal{Subject: "balancer-test"}), req)
+		}))
+		pb.RegisterCalculateServer(grpcServer, &server{})
+		go grpcServer.Serve(lis)
+		t.Cleanup(grpcServer.Stop)
+	}
+	return backends
+}
+
+func writeBackends(t *testing.T, path string, backends []*backend) {
+	t.Helper()
+
+	var addrs []string
+	for _, b := range backends {
+		addrs = append(addrs, b.addr)
+	}
+	if err := os.WriteFile(path, []byte("# calculators\n"+strings.Join(addrs, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func dialBackends(t *testing.T, policy string, backends []*backend) (pb.CalculateClient, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "backends.txt")
+	writeBackends(t, path, backends)
+
+	conn, err := grpc.NewClient("backends:///"+path,
+		grpc.WithResolvers(balancer.NewResolverBuilder(20*time.Millisecond)),
+		grpc.WithDefaultServiceConfig(balancer.ServiceConfig(policy)),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewCalculateClient(conn), path
+}
+
+// warmUp calls until every backend got a call, so all of them are ready, then resets the counters
+func warmUp(t *testing.T, client pb.CalculateClient, backends []*backend) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		client.Add(context.Background(), &pb.AddRequest{A: 1, B: 2})
+		all := true
+		for _, b := range backends {
+			all = all && b.calls.Load() > 0
+		}
+		if all {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("not every backend became ready")
+		}
+	}
+	for _, b := range backends {
+		b.calls.Store(0)
+	}
+}
+
+func TestRoundRobinSpreadsCallsEvenly(t *testing.T) {
+	backends := startCalculators(t, 3)
+	client, _ := dialBackends(t, balancer.RoundRobin, backends)
+	warmUp(t, client, backends)
+
+	for range 300 {
+		if _, err := client.Add(context.Background(), &pb.AddRequest{A: 1, B: 2}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, b := range backends {
+		if got := b.calls.Load(); got < 95 || got > 105 {
+			t.Errorf("backend %s got %d of 300 calls; want about 100", b.addr, got)
+		}
+	}
+}
+
+func TestLeastOutstandingAvoidsSlowBackend(t *testing.T) {
+	backends := startCalculators(t, 3)
+	client, _ := dialBackends(t, balancer.LeastOutstanding, backends)
+	warmUp(t, client, backends)
+	backends[0].delay = 50 * time.Millisecond
+
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				client.Add(context.Background(), &pb.AddRequest{A: 1, B: 2})
+			}
+		}()
+	}
+	wg.Wait()
+
+	slow := backends[0].calls.Load()
+	for _, b := range backends[1:] {
+		if fast := b.calls.Load(); slow*2 > fast {
+			t.Errorf("slow backend got %d calls, fast %s got %d; want the slow one well behind", slow, b.addr, fast)
+		}
+	}
+}
+
+func TestOutlierEjection(t *testing.T) {
+	backends := startCalculators(t, 3)
+	client, _ := dialBackends(t, balancer.RoundRobin, backends)
+	warmUp(t, client, backends)
+	backends[0].fail.Store(true)
+
+	// DefaultOutlierConfig ejects after 5 consecutive failures, call until that happened
+	add := func() error {
+		_, err := client.Add(context.Background(), &pb.AddRequest{A: 1, B: 2})
+		return err
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for ok := 0; ok < 20; {
+		if time.Now().After(deadline) {
+			t.Fatal("failing backend was never ejected")
+		}
+		if add() != nil {
+			ok = 0
+		} else {
+			ok++
+		}
+	}
+
+	// The ejection lasts 30s, nothing more reaches the failing backend for now
+	before := backends[0].calls.Load()
+	for range 30 {
+		if err := add(); err != nil {
+			t.Fatalf("call after the ejection failed: %v", err)
+		}
+	}
+	if got := backends[0].calls.Load(); got != before {
+		t.Errorf("failing backend got %d calls after its ejection; want none", got-before)
+	}
+}
+
+func TestResolverPicksUpNewBackends(t *testing.T) {
+	backends := startCalculators(t, 2)
+	client, path := dialBackends(t, balancer.RoundRobin, backends[:1])
+	warmUp(t, client, backends[:1])
+
+	writeBackends(t, path, backends)
+	deadline := time.Now().Add(5 * time.Second)
+	for backends[1].calls.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("added backend never got a call")
+		}
+		client.Add(context.Background(), &pb.AddRequest{A: 1, B: 2})
+		time.Sleep(10 * time.Millisecond)
+	}
+}
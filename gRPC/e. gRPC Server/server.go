@@ -2,18 +2,30 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"net"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
 
 	pb "simplegPRCServer/proto/gen"
 	farewellpb "simplegPRCServer/proto/gen/farewell"
 
+	"pkg/auth"
+	"pkg/calculator"
+	"pkg/compression"
+	"pkg/health"
+	"pkg/interceptors"
+	"pkg/mtls"
+	"pkg/rbac"
+
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/metadata"
-
-	_ "google.golang.org/grpc/encoding/gzip" // compression in server
 )
 
 type server struct {
@@ -23,28 +35,19 @@ type server struct {
 }
 
 func (s *server) Add(ctx context.Context, req *pb.AddRequest) (*pb.AddResponse, error) {
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		log.Println("no metadata recieved.")
-	}
-	log.Println("Metadata: ", md)
-	val, ok := md["authorization"]
-	if !ok {
-		log.Println("No value with authorization key in metadata.")
-	} else {
-		log.Println(val)
-	}
-
-	// Set response headers
-	responseHeaders := metadata.Pairs("test", "test-value", "test2", "test-value2")
-	err := grpc.SendHeader(ctx, responseHeaders)
-	if err != nil {
+	// The auth interceptors already verified the authorization metadata and the TLS handshake the client certificate,
+	// the token says which user calls, the certificate which program
+	principal, _ := auth.FromContext(ctx)
+	identity, _ := mtls.PeerIdentity(ctx)
+	log.Printf("Add called by %s (%s) through client %q", principal.Subject, principal.Method, identity.CommonName)
+
+	// Response headers go out with the response, the interceptors suite adds request ID and server timing trailers
+	header := calculator.TestHeader.Pairs("test-value")
+	calculator.Test2Header.Set(header, "test-value2")
+	if err := grpc.SetHeader(ctx, header); err != nil {
 		return nil, err
 	}
-
-	trailer := metadata.Pairs("test-trailer", "test-trailer-value")
-	err = grpc.SetTrailer(ctx, trailer)
-	if err != nil {
+	if err := calculator.TestTrailer.SetTrailer(ctx, "test-trailer-value"); err != nil {
 		return nil, err
 	}
 	return &pb.AddResponse{Sum: req.A + req.B}, nil
@@ -59,9 +62,13 @@ func (s *server) BidGoodBye(ctx context.Context, req *farewellpb.GoodByeRequest)
 }
 
 func main() {
+	debugAddress := flag.String("debug-addr", "localhost:6061", "serve expvar metrics at /debug/vars, empty disables it")
+	flag.Parse()
 
-	cert := "cert.pem"
-	key := "key.pem"
+	// Generated with cmd/gencerts in pkg/, see commands.txt
+	cert := "certs/server.pem"
+	key := "certs/server-key.pem"
+	ca := "certs/ca.pem"
 
 	serveAddress := "127.0.0.1:50001"
 
@@ -70,23 +77,131 @@ func main() {
 		log.Fatal("Failed to listen.", err)
 	}
 
-	cred, err := credentials.NewServerTLSFromFile(cert, key)
+	// Mutual TLS: clients need a certificate issued by our CA on top of their token
+	tlsConfig, err := mtls.ServerConfig(cert, key, ca)
 	if err != nil {
 		log.Fatal("Error loading credential.", err)
 	}
+	cred := credentials.NewTLS(tlsConfig)
 
-	grpcServer := grpc.NewServer(grpc.Creds(cred))
+	reflectionMode, err := health.ParseReflectionMode(os.Getenv("GRPC_REFLECTION"))
+	if err != nil {
+		log.Fatal("Invalid GRPC_REFLECTION.", err)
+	}
+
+	authenticator := loadAuthenticator()
+
+	policy, err := rbac.NewEngine("policy.yaml", rbac.NewJSONLogger(os.Stdout))
+	if err != nil {
+		log.Fatal("Error loading access policy.", err)
+	}
+	go policy.WatchFile(context.Background(), 5*time.Second)
+
+	// Health checks are open to load balancers, reflection is open only in public mode and needs an admin otherwise
+	publicMethods := slices.Clone(health.Methods)
+	switch reflectionMode {
+	case health.ReflectionPublic:
+		publicMethods = append(publicMethods, health.ReflectionMethods...)
+	case health.ReflectionAuthenticated:
+		for _, method := range health.ReflectionMethods {
+			accessRules[method] = rbac.MethodRule{Action: "reflect", ResourceType: "server"}
+		}
+	}
 
+	metrics := interceptors.NewMetrics()
+	metrics.Publish("grpc_server")
+	if *debugAddress != "" {
+		go func() {
+			log.Println("Debug server stopped:", interceptors.ServeDebug(*debugAddress))
+		}()
+	}
+
+	serverOptions := interceptorOptions(interceptors.Config{
+		Logger:       slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+		Metrics:      metrics,
+		UnaryTimeout: 10 * time.Second,
+	}, authenticator, policy, publicMethods)
+	serverOptions = append(serverOptions, compression.NewServer(compressionPolicy, messageLimits).ServerOptions()...)
+	grpcServer := grpc.NewServer(append(serverOptions, grpc.Creds(cred))...)
+	register(grpcServer)
+
+	healthServer := health.NewServer(
+		pb.Calculate_ServiceDesc.ServiceName,
+		pb.Greeter_ServiceDesc.ServiceName,
+		farewellpb.AufWiedersehen_ServiceDesc.ServiceName,
+	)
+	healthServer.AddCheck("certificate", health.CertificateCheck(cert, key, 0))
+	healthServer.Register(grpcServer)
+	health.RegisterReflection(grpcServer, reflectionMode)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go healthServer.Run(ctx, 10*time.Second)
+
+	go func() {
+		log.Println("Server is running at", serveAddress)
+		if err := grpcServer.Serve(list); err != nil {
+			log.Fatal("Failed to server.", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down, draining in-flight calls")
+	healthServer.Shutdown(grpcServer, 2*time.Second, 15*time.Second)
+}
+
+// interceptorOptions chains auth and rbac inside the interceptor suite, so their rejections are logged and counted too
+func interceptorOptions(cfg interceptors.Config, authenticator *auth.Authenticator, policy *rbac.Engine, publicMethods []string) []grpc.ServerOption {
+	return append(interceptors.ServerOptions(cfg),
+		grpc.ChainUnaryInterceptor(
+			auth.UnaryServerInterceptor(authenticator, auth.WithPublicMethods(publicMethods...)),
+			rbac.UnaryServerInterceptor(policy, accessRules, publicMethods...),
+		),
+		grpc.ChainStreamInterceptor(
+			auth.StreamServerInterceptor(authenticator, auth.WithPublicMethods(publicMethods...)),
+			rbac.StreamServerInterceptor(policy, accessRules, publicMethods...),
+		),
+	)
+}
+
+func register(grpcServer *grpc.Server) {
 	pb.RegisterCalculateServer(grpcServer, &server{})
 	pb.RegisterGreeterServer(grpcServer, &server{})
 	farewellpb.RegisterAufWiedersehenServer(grpcServer, &server{})
+}
 
-	// skipping something
+// compressionPolicy gzips messages from 1 KiB on, calculator messages are smaller, see BenchmarkAddCompression for why
+var compressionPolicy = compression.DefaultPolicy
 
-	log.Println("Server is running at", serveAddress)
-	err = grpcServer.Serve(list)
+// messageLimits rejects anything near them with ResourceExhausted, calculator messages are a few bytes
+var messageLimits = compression.Limits{MaxRecvBytes: 64 << 10, MaxSendBytes: 64 << 10}
+
+// accessRules maps each RPC onto a policy.yaml permission, methods missing here are denied.
+var accessRules = map[string]rbac.MethodRule{
+	pb.Calculate_Add_FullMethodName:                     {Action: "add", ResourceType: "calculator"},
+	pb.Greeter_Greet_FullMethodName:                     {Action: "greet", ResourceType: "greeter"},
+	farewellpb.AufWiedersehen_BidGoodBye_FullMethodName: {Action: "bid", ResourceType: "farewell"},
+}
+
+/*
+Every RPC needs either
+- authorization: Bearer <jwt> signed by a key in keys/ for audience "calculator"
+- x-api-key: <key> whose sha256 hash is listed in apikeys.json
+Neither is checked in, see commands.txt for generating them.
+*/
+func loadAuthenticator() *auth.Authenticator {
+	keys, err := auth.LoadKeySet("keys")
 	if err != nil {
-		log.Fatal("Failed to server.", err)
+		log.Fatal("Error loading JWT keys.", err)
 	}
 
+	apiKeys, err := auth.LoadAPIKeys("apikeys.json")
+	if err != nil {
+		log.Fatal("Error loading API keys.", err)
+	}
+
+	return &auth.Authenticator{
+		JWT:     &auth.Verifier{Keys: keys, Audience: "calculator", Leeway: 30 * time.Second},
+		APIKeys: apiKeys,
+	}
 }
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	pb "simplegPRCServer/proto/gen"
+	farewellpb "simplegPRCServer/proto/gen/farewell"
+
+	"pkg/auth"
+	"pkg/calculator"
+	"pkg/compression"
+	"pkg/grpctest"
+	"pkg/interceptors"
+	"pkg/mdkit"
+	"pkg/rbac"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testAPIKey = "test-key"
+
+// startServer serves all three services behind mutual TLS, the real auth and rbac chain and the compress policy
+func startServer(t testing.TB, compress compression.Policy, opts ...grpctest.Option) *grpctest.Server {
+	t.Helper()
+
+	apiKeys, err := auth.NewAPIKeyStore(auth.APIKey{ID: "test", Hash: auth.HashAPIKey(testAPIKey), Subject: "calculator-client", Roles: []string{"client"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := rbac.NewEngine("policy.yaml", rbac.NewJSONLogger(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := interceptorOptions(interceptors.Config{Logger: slog.New(slog.DiscardHandler)}, &auth.Authenticator{APIKeys: apiKeys}, policy, nil)
+	options = append(options, compression.NewServer(compress, messageLimits).ServerOptions()...)
+	return grpctest.Start(t, register, append([]grpctest.Option{grpctest.WithMutualTLS(t, "test-client"), grpctest.WithServerOptions(options...)}, opts...)...)
+}
+
+func withAPIKey() context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", testAPIKey)
+}
+
+func TestAdd(t *testing.T) {
+	client := grpctest.NewClient(startServer(t, compressionPolicy), pb.NewCalculateClient)
+
+	var header, trailer metadata.MD
+	res, err := client.Add(withAPIKey(), &pb.AddRequest{A: 2, B: 40}, grpc.Header(&header), grpc.Trailer(&trailer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpctest.Golden(t, "add", res)
+
+	if got, err := calculator.Test2Header.Get(header); got != "test-value2" {
+		t.Errorf("header test2 = %q, %v; want test-value2", got, err)
+	}
+	if got, err := calculator.TestTrailer.Get(trailer); got != "test-trailer-value" {
+		t.Errorf("trailer test-trailer = %q, %v; want test-trailer-value", got, err)
+	}
+	if _, err := mdkit.ServerTiming.Get(trailer); err != nil {
+		t.Errorf("server timing trailer: %v", err)
+	}
+
+	_, err = client.Add(context.Background(), &pb.AddRequest{A: 2, B: 40})
+	grpctest.Golden(t, "add_unauthenticated", status.Convert(err).Proto())
+}
+
+func TestGreet(t *testing.T) {
+	client := grpctest.NewClient(startServer(t, compressionPolicy), pb.NewGreeterClient)
+
+	res, err := client.Greet(withAPIKey(), &pb.HelloRequest{Name: "Alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpctest.Golden(t, "greet", res)
+}
+
+func TestBidGoodBye(t *testing.T) {
+	client := grpctest.NewClient(startServer(t, compressionPolicy), farewellpb.NewAufWiedersehenClient)
+
+	res, err := client.BidGoodBye(withAPIKey(), &farewellpb.GoodByeRequest{Name: "Alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpctest.Golden(t, "bid_good_bye", res)
+}
+
+func TestMessageLimits(t *testing.T) {
+	client := grpctest.NewClient(startServer(t, compressionPolicy), pb.NewGreeterClient)
+
+	_, err := client.Greet(withAPIKey(), &pb.HelloRequest{Name: strings.Repeat("a", 64<<10)})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("err = %v; want ResourceExhausted for a name over the receive limit", err)
+	}
+}
+
+/*
+BenchmarkAddCompression compares the CPU and bytes of Add with and without gzip.
+- wire-B/op counts request and response as sent, compressed and framed
+- gzip's header and checksum make Add's messages larger, the reason for the 1 KiB threshold of compressionPolicy
+*/
+func BenchmarkAddCompression(b *testing.B) {
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	for _, bench := range []struct {
+		name   string
+		policy compression.Policy
+	}{
+		{"identity", compression.Policy{}},
+		{"gzip", compression.Policy{Default: compression.Rule{Compressor: gzip.Name}}},
+		{"gzip-from-1KiB", compressionPolicy},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			meter := &compression.Meter{}
+			dialOptions := append(compression.NewClient(bench.policy, compression.Limits{}).DialOptions(), grpc.WithStatsHandler(meter))
+			client := grpctest.NewClient(startServer(b, bench.policy, grpctest.WithDialOptions(dialOptions...)), pb.NewCalculateClient)
+			ctx := withAPIKey()
+			req := &pb.AddRequest{A: 123456, B: 654321}
+
+			// The first response tells the client which encodings the server accepts
+			if _, err := client.Add(ctx, req); err != nil {
+				b.Fatal(err)
+			}
+			before := meter.Sent() + meter.Received()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				if _, err := client.Add(ctx, req); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(meter.Sent()+meter.Received()-before)/float64(b.N), "wire-B/op")
+		})
+	}
+}
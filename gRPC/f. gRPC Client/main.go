@@ -5,24 +5,79 @@ import (
 	mainapipb "grpcClient/proto/gen"
 	farewellpb "grpcClient/proto/gen/farewell"
 	"log"
+	"log/slog"
+	"os"
 	"time"
 
+	"pkg/balancer"
+	"pkg/budget"
+	"pkg/calculator"
+	"pkg/compression"
+	"pkg/mdkit"
+	"pkg/mtls"
+	"pkg/resilience"
+
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/metadata"
 )
 
-func main() {
+// Every call here is idempotent, so all of them can be retried, Greet is cheap enough to hedge.
+// Only UNAVAILABLE is retried: RESOURCE_EXHAUSTED also comes from message size limits, resending won't help.
+// With several backends calls go to the one with the fewest calls in flight.
+const serviceConfig = `{
+"loadBalancingConfig": [{"least_outstanding": {}}],
+"methodConfig": [
+	{
+		"name": [{"service": "calculator.Calculate"}, {"service": "farewell.AufWiedersehen"}],
+		"retryPolicy": {
+			"maxAttempts": 4,
+			"initialBackoff": "0.1s",
+			"maxBackoff": "1s",
+			"backoffMultiplier": 2,
+			"retryableStatusCodes": ["UNAVAILABLE"]
+		}
+	},
+	{
+		"name": [{"service": "calculator.Greeter"}],
+		"hedgingPolicy": {
+			"maxAttempts": 2,
+			"hedgingDelay": "0.2s",
+			"nonFatalStatusCodes": ["UNAVAILABLE"]
+		}
+	}
+]}`
 
-	cert := "cert.pem"
+// callBudget logs the budget each call consumed and doesn't send calls with less than 100ms left
+var callBudget = budget.Config{MinCall: 100 * time.Millisecond, Logger: slog.Default()}
 
-	creds, err := credentials.NewClientTLSFromFile(cert, "")
+func main() {
+
+	// The server only talks to clients with a certificate from its CA, see commands.txt of the server
+	tlsConfig, err := mtls.ClientConfig("certs/client.pem", "certs/client-key.pem", "certs/ca.pem", "")
 	if err != nil {
 		log.Fatalln("Error loading TLS Certification:", err)
 	}
+	creds := credentials.NewTLS(tlsConfig)
 
-	conn, err := grpc.NewClient("127.0.0.1:50001", grpc.WithTransportCredentials(creds), grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
+	// Five failures in a row stop calls to that method for 10 seconds instead of piling onto a struggling server
+	resilient, err := resilience.New(serviceConfig, resilience.BreakerConfig{FailureThreshold: 5, OpenTimeout: 10 * time.Second})
+	if err != nil {
+		log.Fatalln("Invalid service config:", err)
+	}
+
+	// Requests of 1 KiB and more are gzipped once the server advertised it, the size check comes first so
+	// an oversized request fails right away with RESOURCE_EXHAUSTED before anything is sent
+	compressor := compression.NewClient(compression.DefaultPolicy, compression.Limits{MaxRecvBytes: 64 << 10, MaxSendBytes: 64 << 10})
+	// The budget interceptor goes first, its deadline covers all attempts of a call, retries and hedges included
+	opts := []grpc.DialOption{grpc.WithChainUnaryInterceptor(callBudget.UnaryClientInterceptor())}
+	opts = append(opts, compressor.DialOptions()...)
+	opts = append(opts, resilient.DialOptions()...)
+	opts = append(opts,
+		grpc.WithResolvers(balancer.NewResolverBuilder(5*time.Second)),
+		grpc.WithTransportCredentials(creds),
+	)
+	conn, err := grpc.NewClient(target(), opts...)
 	if err != nil {
 		log.Fatalln("Did not connect:", err)
 	}
@@ -32,41 +87,96 @@ func main() {
 	client2 := mainapipb.NewGreeterClient(conn)
 	client3 := farewellpb.NewAufWiedersehenClient(conn)
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
+	// Token minted with: go run ./cmd/mint-token -key "../gRPC/e. gRPC Server/keys/dev.hmac" -sub john -roles client -aud calculator (from pkg/)
+	token := os.Getenv("AUTH_TOKEN")
+	if token == "" {
+		log.Fatalln("AUTH_TOKEN is not set")
+	}
+
+	md := metadata.Pairs("authorization", "Bearer "+token, "test", "testing", "test2", "testing2")
+	baseCtx := metadata.NewOutgoingContext(context.Background(), md)
+	failed := false
 
-	md := metadata.Pairs("authorization", "Bearer=somerandomstring", "test", "testing", "test2", "testing2")
-	ctx = metadata.NewOutgoingContext(ctx, md)
-	req := &mainapipb.AddRequest{A: 10, B: 12}
+	// One budget for all three calls, so the later ones see what the earlier ones left instead of a fresh timeout.
+	// Each call may use up to 3 seconds of it, a call the budget has too little left for is skipped and reported as failed.
+	calls, cancelCalls := context.WithTimeout(baseCtx, 5*time.Second)
+	defer cancelCalls()
 
 	var responseHeader metadata.MD
 	var responseTrailer metadata.MD
-	res, err := client.Add(ctx, req, grpc.UseCompressor(gzip.Name), grpc.Header(&responseHeader), grpc.Trailer(&responseTrailer))
-	if err != nil {
-		log.Fatalln("Could not add", err)
+	var attempts int
+	if err := withBudget(calls, func(ctx context.Context) error {
+		res, err := client.Add(ctx, &mainapipb.AddRequest{A: 10, B: 12}, grpc.Header(&responseHeader), grpc.Trailer(&responseTrailer), resilience.Attempts(&attempts))
+		if err != nil {
+			return err
+		}
+		log.Println("Sum : ", res.Sum)
+		test, _ := calculator.TestHeader.Get(responseHeader)
+		requestID, _ := mdkit.RequestID.Get(responseHeader)
+		log.Printf("Response header test: %q, request ID %s", test, requestID)
+		testTrailer, _ := calculator.TestTrailer.Get(responseTrailer)
+		took, _ := mdkit.ServerTiming.Get(responseTrailer)
+		attemptsTrailer, _ := mdkit.Attempts.Get(responseTrailer)
+		log.Printf("Response trailer test-trailer: %q, server took %v, attempts: %d", testTrailer, took, attemptsTrailer)
+		return nil
+	}); err != nil {
+		log.Printf("Could not add after %d attempts: %v", attempts, err)
+		failed = true
 	}
-	log.Println("Sum : ", res.Sum)
-	log.Println("Response Header", responseHeader)
-	log.Println(responseHeader["test"])
-	log.Println("Response Trailer", responseTrailer)
-	log.Println(responseTrailer["test-trailer"])
-
-	req2 := &mainapipb.HelloRequest{
-		Name: "John",
+
+	attempts = 0
+	if err := withBudget(calls, func(ctx context.Context) error {
+		res, err := client2.Greet(ctx, &mainapipb.HelloRequest{Name: "John"}, resilience.Attempts(&attempts))
+		if err != nil {
+			return err
+		}
+		log.Println("Greeting message : ", res.Message)
+		return nil
+	}); err != nil {
+		log.Printf("Could not greet after %d attempts: %v", attempts, err)
+		failed = true
 	}
-	res2, err := client2.Greet(ctx, req2)
-	if err != nil {
-		log.Fatalln("Could not greet", err)
+
+	attempts = 0
+	if err := withBudget(calls, func(ctx context.Context) error {
+		res, err := client3.BidGoodBye(ctx, &farewellpb.GoodByeRequest{Name: "Sulabh"}, resilience.Attempts(&attempts))
+		if err != nil {
+			return err
+		}
+		log.Println("Goodbye message : ", res.Message)
+		return nil
+	}); err != nil {
+		log.Printf("Could not bid goodbye after %d attempts: %v", attempts, err)
+		failed = true
 	}
-	log.Println("Greeting message : ", res2.Message)
 
-	req3 := &farewellpb.GoodByeRequest{
-		Name: "Sulabh",
+	if failed {
+		os.Exit(1)
 	}
-	res3, err := client3.BidGoodBye(ctx, req3)
+}
+
+// withBudget runs call with up to 3 seconds of the calls budget, it is skipped with the budget's error when too little is left
+func withBudget(calls context.Context, call func(ctx context.Context) error) error {
+	ctx, cancel, err := callBudget.Call(calls, 3*time.Second)
+	defer cancel()
 	if err != nil {
-		log.Fatalln("Could not bid goodbye", err)
+		return err
 	}
-	log.Println("Goodbye message : ", res3.Message)
+	return call(ctx)
+}
 
+/*
+target picks the backends to call
+- CALCULATOR_BACKENDS_FILE: a file listing one address per line, re-read when it changes
+- CALCULATOR_BACKENDS: a comma separated address list
+- otherwise the single local server
+*/
+func target() string {
+	if file := os.Getenv("CALCULATOR_BACKENDS_FILE"); file != "" {
+		return balancer.Scheme + ":" + file
+	}
+	if os.Getenv("CALCULATOR_BACKENDS") != "" {
+		return balancer.Scheme + ":env:CALCULATOR_BACKENDS"
+	}
+	return "127.0.0.1:50001"
 }
@@ -2,12 +2,17 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"log"
+	"strconv"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	main_pb "grpc_server_consumer_clinet/proto/gen"
 )
@@ -25,7 +30,8 @@ func main() {
 	// Server side streaming
 	ctx := context.Background()
 	req := &main_pb.FibonacciRequest{
-		Count: 10,
+		Count:     100, // Well past int32, terms come as decimal strings
+		BatchSize: 10,
 	}
 
 	stream, err := client.GenerateFibonacci(ctx, req)
@@ -42,24 +48,24 @@ func main() {
 		if err != nil {
 			log.Fatalln("Error receiving GenerateFibonacci func", err)
 		}
-		log.Println(resp.GetNumber())
+		for _, term := range resp.GetTerms() {
+			log.Println(term.GetIndex(), term.GetDecimal())
+		}
 	}
 
-	// Client side streaming
-	stream1, err := client.SendNumbers(ctx)
-	if err != nil {
-		log.Fatalln("Error creating stream:", err)
-	}
+	// Client side streaming, resumable: a dropped connection picks up after the last number the server checkpointed
+	numbers := []int32{0, 1, 2, 3, 4, 5, 6, 7, 8}
+	sessionID := fmt.Sprintf("numbers-%d", time.Now().UnixNano())
 
-	for num := range 9 {
-		err = stream1.Send(&main_pb.NumberRequest{Number: int32(num)})
-		if err != nil {
-			log.Fatalln("Error sending number to stream:", err)
+	var res *main_pb.NumberResponse
+	for attempt := 1; ; attempt++ {
+		res, err = sendNumbers(ctx, client, sessionID, numbers)
+		if status.Code(err) != codes.Unavailable || attempt == 5 {
+			break
 		}
+		log.Println("SendNumbers interrupted, resuming:", err)
 		time.Sleep(time.Second)
 	}
-
-	res, err := stream1.CloseAndRecv()
 	if err != nil {
 		log.Fatalln("Error receiving response:", err)
 	}
@@ -75,6 +81,12 @@ func main() {
 
 	// Send messages in a separate goroutine
 	go func() {
+		// The first message picks the room and our name, the server ignores them on later messages
+		err := chatStream.Send(&main_pb.ChatMessage{Room: "lobby", Sender: "consumer-client", Event: main_pb.ChatEvent_CHAT_EVENT_JOIN})
+		if err != nil {
+			log.Fatalln("Error joining chat room:", err)
+		}
+
 		messages := []string{"Hello", "How are you?", "Goodbye"}
 		for _, msg := range messages {
 			err := chatStream.Send(&main_pb.ChatMessage{Message: msg})
@@ -97,10 +109,38 @@ func main() {
 			if err != nil {
 				log.Fatalln("Error receiving message:", err)
 			}
-			log.Println("Received message:", res.GetMessage())
+			log.Printf("[%s] %s %s: %s", res.GetRoom(), res.GetEvent(), res.GetSender(), res.GetMessage())
 		}
 		close(waitCh)
 	}()
 
 	<-waitCh
 }
+
+// sendNumbers streams numbers as session seqs 1..len(numbers), starting after the server's last-seq header
+func sendNumbers(ctx context.Context, client main_pb.CalculatorClient, sessionID string, numbers []int32) (*main_pb.NumberResponse, error) {
+	stream, err := client.SendNumbers(metadata.AppendToOutgoingContext(ctx, "session-id", sessionID))
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := stream.Header()
+	if err != nil {
+		return nil, err
+	}
+	var lastSeq uint64
+	if values := header.Get("last-seq"); len(values) > 0 {
+		lastSeq, _ = strconv.ParseUint(values[0], 10, 64)
+	}
+
+	for seq := lastSeq + 1; seq <= uint64(len(numbers)); seq++ {
+		err = stream.Send(&main_pb.NumberRequest{Number: numbers[seq-1], Seq: seq})
+		if err != nil {
+			// The real error comes with the status from CloseAndRecv
+			break
+		}
+		time.Sleep(time.Second)
+	}
+
+	return stream.CloseAndRecv()
+}
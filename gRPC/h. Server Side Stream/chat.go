@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log"
+
+	main_pb "grpcstreams/proto/gen"
+
+	"pkg/chat"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const defaultRoom = "lobby"
+
+/*
+Chat joins the stream to a room of the chat broker.
+- The first message picks the room and sender name, if it also carries text that text is published
+- Every later message is published to the room, CHAT_EVENT_LEAVE or closing the send side leaves it
+- Messages of all members, history replayed on join included, are streamed back
+- A client that doesn't read fast enough is evicted with ResourceExhausted
+*/
+func (s *server) Chat(stream main_pb.Calculator_ChatServer) error {
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	roomName, sender := first.GetRoom(), first.GetSender()
+	if roomName == "" {
+		roomName = defaultRoom
+	}
+	if sender == "" {
+		sender = "anonymous"
+	}
+
+	sub, err := s.broker.Join(roomName, sender)
+	if errors.Is(err, chat.ErrTooManyRooms) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	defer sub.Leave()
+	log.Printf("%s joined room %s", sender, roomName)
+
+	if first.GetEvent() == main_pb.ChatEvent_CHAT_EVENT_MESSAGE && first.GetMessage() != "" {
+		s.broker.Publish(roomName, sender, first.GetMessage())
+	}
+
+	recvDone := make(chan error, 1)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err == io.EOF || req.GetEvent() == main_pb.ChatEvent_CHAT_EVENT_LEAVE {
+				recvDone <- nil
+				return
+			}
+			if err != nil {
+				recvDone <- err
+				return
+			}
+			s.broker.Publish(roomName, sender, req.GetMessage())
+		}
+	}()
+
+	for {
+		select {
+		case msg, ok := <-sub.C:
+			if !ok {
+				if errors.Is(sub.Err(), chat.ErrSlowConsumer) {
+					return status.Error(codes.ResourceExhausted, sub.Err().Error())
+				}
+				return nil
+			}
+			if err := stream.Send(toChatMessage(msg)); err != nil {
+				return err
+			}
+
+		case err := <-recvDone:
+			if err != nil {
+				return err
+			}
+			// The client is done sending: leave, then deliver what is already queued for it
+			sub.Leave()
+			for msg := range sub.C {
+				if err := stream.Send(toChatMessage(msg)); err != nil {
+					return err
+				}
+			}
+			log.Printf("%s left room %s", sender, roomName)
+			return nil
+
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+	}
+}
+
+func toChatMessage(m chat.Message) *main_pb.ChatMessage {
+	event := main_pb.ChatEvent_CHAT_EVENT_MESSAGE
+	switch m.Kind {
+	case chat.KindJoin:
+		event = main_pb.ChatEvent_CHAT_EVENT_JOIN
+	case chat.KindLeave:
+		event = main_pb.ChatEvent_CHAT_EVENT_LEAVE
+	}
+
+	return &main_pb.ChatMessage{
+		Message:      m.Text,
+		Room:         m.Room,
+		Sender:       m.Sender,
+		Event:        event,
+		Id:           m.ID,
+		SentAtUnixMs: m.SentAt.UnixMilli(),
+		Replayed:     m.Replayed,
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	main_pb "grpcstreams/proto/gen"
+
+	"pkg/grpctest"
+
+	"google.golang.org/grpc"
+)
+
+func newBufconnClient(t *testing.T) main_pb.CalculatorClient {
+	t.Helper()
+
+	srv := grpctest.Start(t, func(s *grpc.Server) {
+		main_pb.RegisterCalculatorServer(s, newServer(0, newMemoryCheckpoints()))
+	})
+	return grpctest.NewClient(srv, main_pb.NewCalculatorClient)
+}
+
+func recvUntil(t *testing.T, stream main_pb.Calculator_ChatClient, match func(*main_pb.ChatMessage) bool) *main_pb.ChatMessage {
+	t.Helper()
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		if match(msg) {
+			return msg
+		}
+	}
+}
+
+func TestChatRoomFanOutAndHistory(t *testing.T) {
+	client := newBufconnClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	alice, err := client.Chat(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice.Send(&main_pb.ChatMessage{Room: "gophers", Sender: "alice", Message: "first!"})
+	recvUntil(t, alice, func(m *main_pb.ChatMessage) bool { return m.GetMessage() == "first!" })
+
+	bob, err := client.Chat(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob.Send(&main_pb.ChatMessage{Room: "gophers", Sender: "bob", Event: main_pb.ChatEvent_CHAT_EVENT_JOIN})
+
+	replayed := recvUntil(t, bob, func(m *main_pb.ChatMessage) bool { return m.GetEvent() == main_pb.ChatEvent_CHAT_EVENT_MESSAGE })
+	if !replayed.GetReplayed() || replayed.GetMessage() != "first!" || replayed.GetSender() != "alice" {
+		t.Errorf("bob's first message = %v; want alice's first! replayed from history", replayed)
+	}
+	recvUntil(t, alice, func(m *main_pb.ChatMessage) bool {
+		return m.GetEvent() == main_pb.ChatEvent_CHAT_EVENT_JOIN && m.GetSender() == "bob"
+	})
+
+	bob.Send(&main_pb.ChatMessage{Message: "hi alice"})
+	got := recvUntil(t, alice, func(m *main_pb.ChatMessage) bool { return m.GetEvent() == main_pb.ChatEvent_CHAT_EVENT_MESSAGE })
+	if got.GetMessage() != "hi alice" || got.GetSender() != "bob" || got.GetRoom() != "gophers" || got.GetReplayed() {
+		t.Errorf("alice got %v; want live hi alice from bob in gophers", got)
+	}
+
+	// Closing the send side leaves the room and ends bob's stream once his queue is drained
+	bob.CloseSend()
+	recvUntil(t, alice, func(m *main_pb.ChatMessage) bool {
+		return m.GetEvent() == main_pb.ChatEvent_CHAT_EVENT_LEAVE && m.GetSender() == "bob"
+	})
+	for {
+		if _, err := bob.Recv(); err != nil {
+			break
+		}
+	}
+}
+
+func TestChatRoomsAreIsolated(t *testing.T) {
+	client := newBufconnClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	a, _ := client.Chat(ctx)
+	a.Send(&main_pb.ChatMessage{Room: "a", Sender: "x", Event: main_pb.ChatEvent_CHAT_EVENT_JOIN})
+	recvUntil(t, a, func(m *main_pb.ChatMessage) bool { return m.GetEvent() == main_pb.ChatEvent_CHAT_EVENT_JOIN })
+
+	b, _ := client.Chat(ctx)
+	b.Send(&main_pb.ChatMessage{Room: "b", Sender: "y", Message: "only for b"})
+	recvUntil(t, b, func(m *main_pb.ChatMessage) bool { return m.GetMessage() == "only for b" })
+
+	a.Send(&main_pb.ChatMessage{Message: "only for a"})
+	got := recvUntil(t, a, func(m *main_pb.ChatMessage) bool { return m.GetEvent() == main_pb.ChatEvent_CHAT_EVENT_MESSAGE })
+	if got.GetMessage() != "only for a" {
+		t.Errorf("room a got %q; want only its own message", got.GetMessage())
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Checkpoint is the running aggregate of a SendNumbers session, everything up to LastSeq is acknowledged
+type Checkpoint struct {
+	SessionID string    `json:"session_id"`
+	LastSeq   uint64    `json:"last_seq"`
+	Count     uint64    `json:"count"`
+	Sum       int32     `json:"sum"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CheckpointStore keeps SendNumbers checkpoints across streams, a missing session loads as the zero Checkpoint
+type CheckpointStore interface {
+	Load(sessionID string) (Checkpoint, error)
+	Save(cp Checkpoint) error
+	// Delete forgets a completed session, the same ID starts from scratch afterwards
+	Delete(sessionID string) error
+}
+
+const (
+	// checkpointTTL is how long a session that is neither resumed nor finished keeps its checkpoint
+	checkpointTTL = 24 * time.Hour
+	// maxCheckpoints caps the open sessions, a new one past it evicts the least recently updated
+	maxCheckpoints = 10000
+)
+
+/*
+memoryCheckpoints keeps checkpoints in a map bounded by ttl and max.
+- A client that gives up mid-stream leaves its checkpoint behind, it expires ttl after its last update
+- Expired checkpoints load as missing and are swept when a new session is saved
+- At max sessions a new one evicts the least recently updated, that client starts over when it resumes
+*/
+type memoryCheckpoints struct {
+	ttl time.Duration
+	max int
+	now func() time.Time
+
+	mu          sync.Mutex
+	checkpoints map[string]Checkpoint
+}
+
+func newMemoryCheckpoints() *memoryCheckpoints {
+	return &memoryCheckpoints{ttl: checkpointTTL, max: maxCheckpoints, now: time.Now, checkpoints: make(map[string]Checkpoint)}
+}
+
+func (m *memoryCheckpoints) Load(sessionID string) (Checkpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cp, ok := m.checkpoints[sessionID]
+	if !ok || m.expired(cp) {
+		return Checkpoint{SessionID: sessionID}, nil
+	}
+	return cp, nil
+}
+
+func (m *memoryCheckpoints) Save(cp Checkpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.saveLocked(cp)
+	return nil
+}
+
+func (m *memoryCheckpoints) Delete(sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.checkpoints, sessionID)
+	return nil
+}
+
+func (m *memoryCheckpoints) expired(cp Checkpoint) bool {
+	return m.now().Sub(cp.UpdatedAt) > m.ttl
+}
+
+// saveLocked stores cp, a new session first sweeps the expired ones and makes room below max
+func (m *memoryCheckpoints) saveLocked(cp Checkpoint) {
+	if _, ok := m.checkpoints[cp.SessionID]; !ok {
+		var oldest string
+		for id, other := range m.checkpoints {
+			if m.expired(other) {
+				delete(m.checkpoints, id)
+				continue
+			}
+			if oldest == "" || other.UpdatedAt.Before(m.checkpoints[oldest].UpdatedAt) {
+				oldest = id
+			}
+		}
+		if len(m.checkpoints) >= m.max {
+			delete(m.checkpoints, oldest)
+		}
+	}
+	m.checkpoints[cp.SessionID] = cp
+}
+
+/*
+fileCheckpoints is a memoryCheckpoints persisted to a JSON file so sessions survive a server restart.
+- Save and Delete only change memory, Run writes the file when something changed, at most once per interval
+- The file is written to a synced temp file and renamed over the old one, so a crash never leaves it half written
+- A crash loses at most the last interval, clients then resume from the older last-seq and resend the rest
+- The same ttl and max bound the file, expired checkpoints read back from it are swept like any others
+- Fine for a demo's handful of sessions, a real deployment would use a database
+*/
+type fileCheckpoints struct {
+	*memoryCheckpoints
+	path  string
+	dirty bool // guarded by memoryCheckpoints.mu
+
+	writeMu sync.Mutex // one file write at a time
+}
+
+func newFileCheckpoints(path string) (*fileCheckpoints, error) {
+	f := &fileCheckpoints{memoryCheckpoints: newMemoryCheckpoints(), path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &f.checkpoints); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Check is the readiness check of the store, the directory of the file has to take new temp files.
+func (f *fileCheckpoints) Check(ctx context.Context) error {
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), ".checkpoints-*")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	return os.Remove(tmp.Name())
+}
+
+func (f *fileCheckpoints) Save(cp Checkpoint) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.saveLocked(cp)
+	f.dirty = true
+	return nil
+}
+
+func (f *fileCheckpoints) Delete(sessionID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.checkpoints, sessionID)
+	f.dirty = true
+	return nil
+}
+
+// Run flushes the checkpoints every interval until ctx is done, call Flush once more after the server stopped.
+func (f *fileCheckpoints) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := f.Flush(); err != nil {
+				log.Println("Error writing checkpoints:", err)
+			}
+		}
+	}
+}
+
+// Flush writes the checkpoints to the file if they changed since the last write.
+func (f *fileCheckpoints) Flush() error {
+	f.writeMu.Lock()
+	defer f.writeMu.Unlock()
+
+	f.mu.Lock()
+	if !f.dirty {
+		f.mu.Unlock()
+		return nil
+	}
+	data, err := json.MarshalIndent(f.checkpoints, "", "  ")
+	f.dirty = false
+	f.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(f.path, data); err != nil {
+		f.mu.Lock()
+		f.dirty = true
+		f.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// writeFileAtomic replaces path with data through a temp file that is synced before the rename.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".checkpoints-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// The rename itself only survives a crash once the directory entry is on disk
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
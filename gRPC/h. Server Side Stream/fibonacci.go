@@ -0,0 +1,108 @@
+package main
+
+import (
+	"math"
+	"math/big"
+	"time"
+
+	main_pb "grpcstreams/proto/gen"
+
+	"pkg/calculator"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	maxFibonacciCount = 100_000
+	maxFibonacciBatch = 1_000
+)
+
+/*
+GenerateFibonacci streams count terms, batch_size per message.
+  - Each stream.Send blocks while the client's flow-control window is full, batching keeps the number of
+    messages (and their per-message overhead) down for large counts
+  - Cancellation and deadlines of the client end the stream between messages, also while pacing
+  - Send errors are returned so the failure shows up in the call status instead of a silent success
+*/
+func (s *server) GenerateFibonacci(req *main_pb.FibonacciRequest, stream main_pb.Calculator_GenerateFibonacciServer) error {
+	count, batchSize := int(req.GetCount()), int(req.GetBatchSize())
+	if count < 0 || count > maxFibonacciCount {
+		return status.Errorf(codes.InvalidArgument, "count must be between 0 and %d", maxFibonacciCount)
+	}
+	if batchSize < 0 || batchSize > maxFibonacciBatch {
+		return status.Errorf(codes.InvalidArgument, "batch_size must be between 0 and %d", maxFibonacciBatch)
+	}
+	if batchSize == 0 {
+		batchSize = 1
+	}
+
+	ctx := stream.Context()
+
+	var pace *time.Ticker
+	if s.fibonacciInterval > 0 {
+		pace = time.NewTicker(s.fibonacciInterval)
+		defer pace.Stop()
+	}
+
+	batch := make([]*main_pb.FibonacciTerm, 0, batchSize)
+	var number int32 // legacy value of the batch's first term
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		res := &main_pb.FibonacciResponse{Terms: batch}
+		if len(batch) == 1 {
+			res.Number = number
+		}
+		if err := stream.Send(res); err != nil {
+			return err
+		}
+		batch = make([]*main_pb.FibonacciTerm, 0, batchSize)
+
+		if pace == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-pace.C:
+			return nil
+		}
+	}
+
+	for index, value := range calculator.Fibonacci(count) {
+		if len(batch) == 0 {
+			number = legacyNumber(value)
+		}
+		batch = append(batch, newFibonacciTerm(uint64(index), value, req.GetEncoding()))
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+func newFibonacciTerm(index uint64, value *big.Int, encoding main_pb.FibonacciEncoding) *main_pb.FibonacciTerm {
+	term := &main_pb.FibonacciTerm{Index: index}
+	if encoding == main_pb.FibonacciEncoding_FIBONACCI_ENCODING_BYTES {
+		term.Value = &main_pb.FibonacciTerm_BigEndian{BigEndian: value.Bytes()}
+	} else {
+		term.Value = &main_pb.FibonacciTerm_Decimal{Decimal: value.String()}
+	}
+	return term
+}
+
+// legacyNumber fills the old int32 field for clients that predate terms, 0 once the term no longer fits.
+func legacyNumber(value *big.Int) int32 {
+	if !value.IsInt64() || value.Int64() > math.MaxInt32 {
+		return 0
+	}
+	return int32(value.Int64())
+}
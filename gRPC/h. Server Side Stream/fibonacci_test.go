@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	main_pb "grpcstreams/proto/gen"
+
+	"pkg/compression"
+	"pkg/grpctest"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGenerateFibonacciBigTermsInBatches(t *testing.T) {
+	client := newBufconnClient(t)
+
+	stream, err := client.GenerateFibonacci(context.Background(), &main_pb.FibonacciRequest{Count: 101, BatchSize: 25})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var terms []*main_pb.FibonacciTerm
+	messages := 0
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		messages++
+		terms = append(terms, res.GetTerms()...)
+	}
+
+	if messages != 5 || len(terms) != 101 {
+		t.Fatalf("got %d terms in %d messages; want 101 in 5", len(terms), messages)
+	}
+	if got := terms[100].GetDecimal(); got != "354224848179261915075" {
+		t.Errorf("term 100 = %s; want 354224848179261915075", got)
+	}
+}
+
+func TestGenerateFibonacciBytesAndLegacyNumber(t *testing.T) {
+	client := newBufconnClient(t)
+
+	stream, err := client.GenerateFibonacci(context.Background(), &main_pb.FibonacciRequest{
+		Count:    48,
+		Encoding: main_pb.FibonacciEncoding_FIBONACCI_ENCODING_BYTES,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var last *main_pb.FibonacciResponse
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.GetTerms()[0].GetIndex() == 46 && res.GetNumber() != 1836311903 {
+			t.Errorf("legacy number of term 46 = %d; want 1836311903", res.GetNumber())
+		}
+		last = res
+	}
+
+	// Term 47 overflows int32, only the bytes carry it
+	term := last.GetTerms()[0]
+	if got := new(big.Int).SetBytes(term.GetBigEndian()); got.String() != "2971215073" || last.GetNumber() != 0 {
+		t.Errorf("term 47 = %s (number %d); want 2971215073 (number 0)", got, last.GetNumber())
+	}
+}
+
+func TestGenerateFibonacciHonoursDeadline(t *testing.T) {
+	client := newBufconnClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// newBufconnClient serves without pacing, a count this large can't finish within the deadline
+	stream, err := client.GenerateFibonacci(ctx, &main_pb.FibonacciRequest{Count: 100_000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		_, err = stream.Recv()
+		if err != nil {
+			break
+		}
+	}
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("err = %v; want DeadlineExceeded", err)
+	}
+}
+
+func TestGenerateFibonacciRejectsBadRequests(t *testing.T) {
+	client := newBufconnClient(t)
+
+	for _, req := range []*main_pb.FibonacciRequest{{Count: -1}, {Count: maxFibonacciCount + 1}, {Count: 1, BatchSize: -1}} {
+		stream, err := client.GenerateFibonacci(context.Background(), req)
+		if err == nil {
+			_, err = stream.Recv()
+		}
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%v: err = %v; want InvalidArgument", req, err)
+		}
+	}
+}
+
+/*
+BenchmarkGenerateFibonacciCompression compares the CPU and bytes of streaming the first 1000 terms in batches of 100.
+- wire-B/op counts all messages of one stream as sent, compressed and framed
+- gzip about halves decimal digits for roughly three times the CPU, big-endian bytes hardly compress at all
+*/
+func BenchmarkGenerateFibonacciCompression(b *testing.B) {
+	for _, bench := range []struct {
+		name     string
+		policy   compression.Policy
+		encoding main_pb.FibonacciEncoding
+	}{
+		{"identity/decimal", compression.Policy{}, main_pb.FibonacciEncoding_FIBONACCI_ENCODING_DECIMAL},
+		{"gzip/decimal", compression.DefaultPolicy, main_pb.FibonacciEncoding_FIBONACCI_ENCODING_DECIMAL},
+		{"identity/bytes", compression.Policy{}, main_pb.FibonacciEncoding_FIBONACCI_ENCODING_BYTES},
+		{"gzip/bytes", compression.DefaultPolicy, main_pb.FibonacciEncoding_FIBONACCI_ENCODING_BYTES},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			meter := &compression.Meter{}
+			srv := grpctest.Start(b, func(s *grpc.Server) {
+				main_pb.RegisterCalculatorServer(s, newServer(0, newMemoryCheckpoints()))
+			},
+				grpctest.WithServerOptions(compression.NewServer(bench.policy, compression.Limits{}).ServerOptions()...),
+				grpctest.WithDialOptions(grpc.WithStatsHandler(meter)),
+			)
+			client := grpctest.NewClient(srv, main_pb.NewCalculatorClient)
+			req := &main_pb.FibonacciRequest{Count: 1000, BatchSize: 100, Encoding: bench.encoding}
+
+			b.ReportAllocs()
+			for range b.N {
+				stream, err := client.GenerateFibonacci(context.Background(), req)
+				if err != nil {
+					b.Fatal(err)
+				}
+				for {
+					if _, err := stream.Recv(); err == io.EOF {
+						break
+					} else if err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+			b.ReportMetric(float64(meter.Sent()+meter.Received())/float64(b.N), "wire-B/op")
+		})
+	}
+}
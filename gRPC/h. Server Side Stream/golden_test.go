@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	main_pb "grpcstreams/proto/gen"
+
+	"pkg/grpctest"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// The golden tests pin the messages of each streaming mode, go test -update-golden rewrites testdata/
+
+func TestGenerateFibonacciGolden(t *testing.T) {
+	client := newBufconnClient(t)
+
+	stream, err := client.GenerateFibonacci(context.Background(), &main_pb.FibonacciRequest{Count: 7, BatchSize: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []proto.Message
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, res)
+	}
+	grpctest.Golden(t, "generate_fibonacci", got...)
+}
+
+func TestSendNumbersGolden(t *testing.T) {
+	client := newBufconnClient(t)
+
+	stream, err := client.SendNumbers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int32{3, 5, 8, 13} {
+		if err := stream.Send(&main_pb.NumberRequest{Number: n}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpctest.Golden(t, "send_numbers", res)
+}
+
+func TestChatGolden(t *testing.T) {
+	client := newBufconnClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.Chat(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.Send(&main_pb.ChatMessage{Room: "golden", Sender: "alice", Event: main_pb.ChatEvent_CHAT_EVENT_JOIN})
+	stream.Send(&main_pb.ChatMessage{Message: "hello"})
+	stream.Send(&main_pb.ChatMessage{Message: "bye"})
+
+	var got []proto.Message
+	recvUntil(t, stream, func(m *main_pb.ChatMessage) bool {
+		m.SentAtUnixMs = 0 // the only field that changes between runs
+		got = append(got, m)
+		return m.GetMessage() == "bye"
+	})
+	grpctest.Golden(t, "chat", got...)
+}
@@ -21,9 +21,110 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type ChatEvent int32
+
+const (
+	ChatEvent_CHAT_EVENT_MESSAGE ChatEvent = 0
+	ChatEvent_CHAT_EVENT_JOIN    ChatEvent = 1
+	ChatEvent_CHAT_EVENT_LEAVE   ChatEvent = 2
+)
+
+// Enum value maps for ChatEvent.
+var (
+	ChatEvent_name = map[int32]string{
+		0: "CHAT_EVENT_MESSAGE",
+		1: "CHAT_EVENT_JOIN",
+		2: "CHAT_EVENT_LEAVE",
+	}
+	ChatEvent_value = map[string]int32{
+		"CHAT_EVENT_MESSAGE": 0,
+		"CHAT_EVENT_JOIN":    1,
+		"CHAT_EVENT_LEAVE":   2,
+	}
+)
+
+func (x ChatEvent) Enum() *ChatEvent {
+	p := new(ChatEvent)
+	*p = x
+	return p
+}
+
+func (x ChatEvent) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ChatEvent) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_main_proto_enumTypes[0].Descriptor()
+}
+
+func (ChatEvent) Type() protoreflect.EnumType {
+	return &file_proto_main_proto_enumTypes[0]
+}
+
+func (x ChatEvent) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ChatEvent.Descriptor instead.
+func (ChatEvent) EnumDescriptor() ([]byte, []int) {
+	return file_proto_main_proto_rawDescGZIP(), []int{0}
+}
+
+type FibonacciEncoding int32
+
+const (
+	FibonacciEncoding_FIBONACCI_ENCODING_DECIMAL FibonacciEncoding = 0 // Base 10 string
+	FibonacciEncoding_FIBONACCI_ENCODING_BYTES   FibonacciEncoding = 1 // Unsigned big-endian bytes, as big.Int.Bytes()
+)
+
+// Enum value maps for FibonacciEncoding.
+var (
+	FibonacciEncoding_name = map[int32]string{
+		0: "FIBONACCI_ENCODING_DECIMAL",
+		1: "FIBONACCI_ENCODING_BYTES",
+	}
+	FibonacciEncoding_value = map[string]int32{
+		"FIBONACCI_ENCODING_DECIMAL": 0,
+		"FIBONACCI_ENCODING_BYTES":   1,
+	}
+)
+
+func (x FibonacciEncoding) Enum() *FibonacciEncoding {
+	p := new(FibonacciEncoding)
+	*p = x
+	return p
+}
+
+func (x FibonacciEncoding) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FibonacciEncoding) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_main_proto_enumTypes[1].Descriptor()
+}
+
+func (FibonacciEncoding) Type() protoreflect.EnumType {
+	return &file_proto_main_proto_enumTypes[1]
+}
+
+func (x FibonacciEncoding) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FibonacciEncoding.Descriptor instead.
+func (FibonacciEncoding) EnumDescriptor() ([]byte, []int) {
+	return file_proto_main_proto_rawDescGZIP(), []int{1}
+}
+
 type ChatMessage struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Room          string                 `protobuf:"bytes,2,opt,name=room,proto3" json:"room,omitempty"`     // Room to join, only read from the first message of a Chat stream (default "lobby")
+	Sender        string                 `protobuf:"bytes,3,opt,name=sender,proto3" json:"sender,omitempty"` // Display name, only read from the first message of a Chat stream
+	Event         ChatEvent              `protobuf:"varint,4,opt,name=event,proto3,enum=calculator.ChatEvent" json:"event,omitempty"`
+	Id            uint64                 `protobuf:"varint,5,opt,name=id,proto3" json:"id,omitempty"`                                             // Set by the server, increasing per room
+	SentAtUnixMs  int64                  `protobuf:"varint,6,opt,name=sent_at_unix_ms,json=sentAtUnixMs,proto3" json:"sent_at_unix_ms,omitempty"` // Set by the server
+	Replayed      bool                   `protobuf:"varint,7,opt,name=replayed,proto3" json:"replayed,omitempty"`                                 // Set by the server on history replayed after joining
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,9 +166,55 @@ func (x *ChatMessage) GetMessage() string {
 	return ""
 }
 
+func (x *ChatMessage) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetSender() string {
+	if x != nil {
+		return x.Sender
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetEvent() ChatEvent {
+	if x != nil {
+		return x.Event
+	}
+	return ChatEvent_CHAT_EVENT_MESSAGE
+}
+
+func (x *ChatMessage) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ChatMessage) GetSentAtUnixMs() int64 {
+	if x != nil {
+		return x.SentAtUnixMs
+	}
+	return 0
+}
+
+func (x *ChatMessage) GetReplayed() bool {
+	if x != nil {
+		return x.Replayed
+	}
+	return false
+}
+
+// SendNumbers is resumable when the stream carries "session-id" metadata: the server answers with a
+// "last-seq" header holding the last checkpointed sequence, the client continues from last-seq + 1
 type NumberRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Number        int32                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	SessionId     string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"` // Optional, must match the "session-id" metadata when set
+	Seq           uint64                 `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`                             // 1-based position in the session, numbers at or below the checkpoint are skipped as duplicates
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -109,9 +256,26 @@ func (x *NumberRequest) GetNumber() int32 {
 	return 0
 }
 
+func (x *NumberRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *NumberRequest) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
 type NumberResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Sum           int32                  `protobuf:"varint,1,opt,name=sum,proto3" json:"sum,omitempty"`
+	SessionId     string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	LastSeq       uint64                 `protobuf:"varint,3,opt,name=last_seq,json=lastSeq,proto3" json:"last_seq,omitempty"` // Last sequence folded into sum
+	Count         uint64                 `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`                    // Numbers folded into sum
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -153,6 +317,27 @@ func (x *NumberResponse) GetSum() int32 {
 	return 0
 }
 
+func (x *NumberResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *NumberResponse) GetLastSeq() uint64 {
+	if x != nil {
+		return x.LastSeq
+	}
+	return 0
+}
+
+func (x *NumberResponse) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
 type AddRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	A             int32                  `protobuf:"varint,1,opt,name=a,proto3" json:"a,omitempty"`
@@ -251,7 +436,9 @@ func (x *AddResponse) GetSum() int32 {
 
 type FibonacciRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Count         int32                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"` // Number of fibonacci numbers to generate
+	Count         int32                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`                                         // Number of fibonacci numbers to generate
+	Encoding      FibonacciEncoding      `protobuf:"varint,2,opt,name=encoding,proto3,enum=calculator.FibonacciEncoding" json:"encoding,omitempty"` // How terms are written into FibonacciTerm
+	BatchSize     int32                  `protobuf:"varint,3,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`                // Terms per response message, 0 means one term per message
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -293,9 +480,24 @@ func (x *FibonacciRequest) GetCount() int32 {
 	return 0
 }
 
+func (x *FibonacciRequest) GetEncoding() FibonacciEncoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return FibonacciEncoding_FIBONACCI_ENCODING_DECIMAL
+}
+
+func (x *FibonacciRequest) GetBatchSize() int32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
 type FibonacciResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Number        int32                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"` // Individual fibonacci number
+	Number        int32                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"` // Individual fibonacci number, only set for single-term messages while the term fits in int32 (up to the 46th)
+	Terms         []*FibonacciTerm       `protobuf:"bytes,2,rep,name=terms,proto3" json:"terms,omitempty"`    // Arbitrary precision terms, batch_size of them per message
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -337,28 +539,155 @@ func (x *FibonacciResponse) GetNumber() int32 {
 	return 0
 }
 
+func (x *FibonacciResponse) GetTerms() []*FibonacciTerm {
+	if x != nil {
+		return x.Terms
+	}
+	return nil
+}
+
+type FibonacciTerm struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Index uint64                 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"` // Position in the sequence, starting at 0
+	// Types that are valid to be assigned to Value:
+	//
+	//	*FibonacciTerm_Decimal
+	//	*FibonacciTerm_BigEndian
+	Value         isFibonacciTerm_Value `protobuf_oneof:"value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FibonacciTerm) Reset() {
+	*x = FibonacciTerm{}
+	mi := &file_proto_main_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FibonacciTerm) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FibonacciTerm) ProtoMessage() {}
+
+func (x *FibonacciTerm) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_main_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FibonacciTerm.ProtoReflect.Descriptor instead.
+func (*FibonacciTerm) Descriptor() ([]byte, []int) {
+	return file_proto_main_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *FibonacciTerm) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *FibonacciTerm) GetValue() isFibonacciTerm_Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *FibonacciTerm) GetDecimal() string {
+	if x != nil {
+		if x, ok := x.Value.(*FibonacciTerm_Decimal); ok {
+			return x.Decimal
+		}
+	}
+	return ""
+}
+
+func (x *FibonacciTerm) GetBigEndian() []byte {
+	if x != nil {
+		if x, ok := x.Value.(*FibonacciTerm_BigEndian); ok {
+			return x.BigEndian
+		}
+	}
+	return nil
+}
+
+type isFibonacciTerm_Value interface {
+	isFibonacciTerm_Value()
+}
+
+type FibonacciTerm_Decimal struct {
+	Decimal string `protobuf:"bytes,2,opt,name=decimal,proto3,oneof"`
+}
+
+type FibonacciTerm_BigEndian struct {
+	BigEndian []byte `protobuf:"bytes,3,opt,name=big_endian,json=bigEndian,proto3,oneof"`
+}
+
+func (*FibonacciTerm_Decimal) isFibonacciTerm_Value() {}
+
+func (*FibonacciTerm_BigEndian) isFibonacciTerm_Value() {}
+
 var File_proto_main_proto protoreflect.FileDescriptor
 
 const file_proto_main_proto_rawDesc = "" +
 	"\n" +
 	"\x10proto/main.proto\x12\n" +
-	"calculator\"'\n" +
+	"calculator\"\xd3\x01\n" +
 	"\vChatMessage\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage\"'\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12\x12\n" +
+	"\x04room\x18\x02 \x01(\tR\x04room\x12\x16\n" +
+	"\x06sender\x18\x03 \x01(\tR\x06sender\x12+\n" +
+	"\x05event\x18\x04 \x01(\x0e2\x15.calculator.ChatEventR\x05event\x12\x0e\n" +
+	"\x02id\x18\x05 \x01(\x04R\x02id\x12%\n" +
+	"\x0fsent_at_unix_ms\x18\x06 \x01(\x03R\fsentAtUnixMs\x12\x1a\n" +
+	"\breplayed\x18\a \x01(\bR\breplayed\"X\n" +
 	"\rNumberRequest\x12\x16\n" +
-	"\x06number\x18\x01 \x01(\x05R\x06number\"\"\n" +
+	"\x06number\x18\x01 \x01(\x05R\x06number\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12\x10\n" +
+	"\x03seq\x18\x03 \x01(\x04R\x03seq\"r\n" +
 	"\x0eNumberResponse\x12\x10\n" +
-	"\x03sum\x18\x01 \x01(\x05R\x03sum\"(\n" +
+	"\x03sum\x18\x01 \x01(\x05R\x03sum\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12\x19\n" +
+	"\blast_seq\x18\x03 \x01(\x04R\alastSeq\x12\x14\n" +
+	"\x05count\x18\x04 \x01(\x04R\x05count\"(\n" +
 	"\n" +
 	"AddRequest\x12\f\n" +
 	"\x01a\x18\x01 \x01(\x05R\x01a\x12\f\n" +
 	"\x01b\x18\x02 \x01(\x05R\x01b\"\x1f\n" +
 	"\vAddResponse\x12\x10\n" +
-	"\x03sum\x18\x01 \x01(\x05R\x03sum\"(\n" +
+	"\x03sum\x18\x01 \x01(\x05R\x03sum\"\x82\x01\n" +
 	"\x10FibonacciRequest\x12\x14\n" +
-	"\x05count\x18\x01 \x01(\x05R\x05count\"+\n" +
+	"\x05count\x18\x01 \x01(\x05R\x05count\x129\n" +
+	"\bencoding\x18\x02 \x01(\x0e2\x1d.calculator.FibonacciEncodingR\bencoding\x12\x1d\n" +
+	"\n" +
+	"batch_size\x18\x03 \x01(\x05R\tbatchSize\"\\\n" +
 	"\x11FibonacciResponse\x12\x16\n" +
-	"\x06number\x18\x01 \x01(\x05R\x06number2\x9e\x02\n" +
+	"\x06number\x18\x01 \x01(\x05R\x06number\x12/\n" +
+	"\x05terms\x18\x02 \x03(\v2\x19.calculator.FibonacciTermR\x05terms\"k\n" +
+	"\rFibonacciTerm\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x04R\x05index\x12\x1a\n" +
+	"\adecimal\x18\x02 \x01(\tH\x00R\adecimal\x12\x1f\n" +
+	"\n" +
+	"big_endian\x18\x03 \x01(\fH\x00R\tbigEndianB\a\n" +
+	"\x05value*N\n" +
+	"\tChatEvent\x12\x16\n" +
+	"\x12CHAT_EVENT_MESSAGE\x10\x00\x12\x13\n" +
+	"\x0fCHAT_EVENT_JOIN\x10\x01\x12\x14\n" +
+	"\x10CHAT_EVENT_LEAVE\x10\x02*Q\n" +
+	"\x11FibonacciEncoding\x12\x1e\n" +
+	"\x1aFIBONACCI_ENCODING_DECIMAL\x10\x00\x12\x1c\n" +
+	"\x18FIBONACCI_ENCODING_BYTES\x10\x012\x9e\x02\n" +
 	"\n" +
 	"Calculator\x126\n" +
 	"\x03Add\x12\x16.calculator.AddRequest\x1a\x17.calculator.AddResponse\x12R\n" +
@@ -378,30 +707,37 @@ func file_proto_main_proto_rawDescGZIP() []byte {
 	return file_proto_main_proto_rawDescData
 }
 
-var file_proto_main_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_proto_main_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_proto_main_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_proto_main_proto_goTypes = []any{
-	(*ChatMessage)(nil),       // 0: calculator.ChatMessage
-	(*NumberRequest)(nil),     // 1: calculator.NumberRequest
-	(*NumberResponse)(nil),    // 2: calculator.NumberResponse
-	(*AddRequest)(nil),        // 3: calculator.AddRequest
-	(*AddResponse)(nil),       // 4: calculator.AddResponse
-	(*FibonacciRequest)(nil),  // 5: calculator.FibonacciRequest
-	(*FibonacciResponse)(nil), // 6: calculator.FibonacciResponse
+	(ChatEvent)(0),            // 0: calculator.ChatEvent
+	(FibonacciEncoding)(0),    // 1: calculator.FibonacciEncoding
+	(*ChatMessage)(nil),       // 2: calculator.ChatMessage
+	(*NumberRequest)(nil),     // 3: calculator.NumberRequest
+	(*NumberResponse)(nil),    // 4: calculator.NumberResponse
+	(*AddRequest)(nil),        // 5: calculator.AddRequest
+	(*AddResponse)(nil),       // 6: calculator.AddResponse
+	(*FibonacciRequest)(nil),  // 7: calculator.FibonacciRequest
+	(*FibonacciResponse)(nil), // 8: calculator.FibonacciResponse
+	(*FibonacciTerm)(nil),     // 9: calculator.FibonacciTerm
 }
 var file_proto_main_proto_depIdxs = []int32{
-	3, // 0: calculator.Calculator.Add:input_type -> calculator.AddRequest
-	5, // 1: calculator.Calculator.GenerateFibonacci:input_type -> calculator.FibonacciRequest
-	1, // 2: calculator.Calculator.SendNumbers:input_type -> calculator.NumberRequest
-	0, // 3: calculator.Calculator.Chat:input_type -> calculator.ChatMessage
-	4, // 4: calculator.Calculator.Add:output_type -> calculator.AddResponse
-	6, // 5: calculator.Calculator.GenerateFibonacci:output_type -> calculator.FibonacciResponse
-	2, // 6: calculator.Calculator.SendNumbers:output_type -> calculator.NumberResponse
-	0, // 7: calculator.Calculator.Chat:output_type -> calculator.ChatMessage
-	4, // [4:8] is the sub-list for method output_type
-	0, // [0:4] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0, // 0: calculator.ChatMessage.event:type_name -> calculator.ChatEvent
+	1, // 1: calculator.FibonacciRequest.encoding:type_name -> calculator.FibonacciEncoding
+	9, // 2: calculator.FibonacciResponse.terms:type_name -> calculator.FibonacciTerm
+	5, // 3: calculator.Calculator.Add:input_type -> calculator.AddRequest
+	7, // 4: calculator.Calculator.GenerateFibonacci:input_type -> calculator.FibonacciRequest
+	3, // 5: calculator.Calculator.SendNumbers:input_type -> calculator.NumberRequest
+	2, // 6: calculator.Calculator.Chat:input_type -> calculator.ChatMessage
+	6, // 7: calculator.Calculator.Add:output_type -> calculator.AddResponse
+	8, // 8: calculator.Calculator.GenerateFibonacci:output_type -> calculator.FibonacciResponse
+	4, // 9: calculator.Calculator.SendNumbers:output_type -> calculator.NumberResponse
+	2, // 10: calculator.Calculator.Chat:output_type -> calculator.ChatMessage
+	7, // [7:11] is the sub-list for method output_type
+	3, // [3:7] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_proto_main_proto_init() }
@@ -409,18 +745,23 @@ func file_proto_main_proto_init() {
 	if File_proto_main_proto != nil {
 		return
 	}
+	file_proto_main_proto_msgTypes[7].OneofWrappers = []any{
+		(*FibonacciTerm_Decimal)(nil),
+		(*FibonacciTerm_BigEndian)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_main_proto_rawDesc), len(file_proto_main_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   7,
+			NumEnums:      2,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_main_proto_goTypes,
 		DependencyIndexes: file_proto_main_proto_depIdxs,
+		EnumInfos:         file_proto_main_proto_enumTypes,
 		MessageInfos:      file_proto_main_proto_msgTypes,
 	}.Build()
 	File_proto_main_proto = out.File
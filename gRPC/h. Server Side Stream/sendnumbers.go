@@ -0,0 +1,178 @@
+package main
+
+import (
+	"io"
+	"log"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	main_pb "grpcstreams/proto/gen"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	sessionIDHeader = "session-id"
+	lastSeqHeader   = "last-seq"
+)
+
+// numberSessions makes sure a SendNumbers session is only fed by one stream at a time
+type numberSessions struct {
+	mu     sync.Mutex
+	active map[string]bool
+}
+
+func (n *numberSessions) acquire(sessionID string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.active == nil {
+		n.active = make(map[string]bool)
+	}
+	if n.active[sessionID] {
+		return false
+	}
+	n.active[sessionID] = true
+	return true
+}
+
+func (n *numberSessions) release(sessionID string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.active, sessionID)
+}
+
+/*
+SendNumbers sums the streamed numbers.
+- Without "session-id" metadata it is a plain one-shot aggregation, a dropped stream loses the sum
+- With it the running sum is checkpointed after every number and the "last-seq" header tells the client where to resume
+- Numbers at or below the checkpoint are duplicates of an earlier attempt and skipped, a gap fails with FailedPrecondition
+- A sum that overflows int32 fails with OutOfRange, the checkpoint keeps the last sum that fit
+- Every outcome, errors included, carries the final "last-seq" trailer
+- A completed session's checkpoint is deleted, sending the same session ID again starts a new sum
+*/
+func (s *server) SendNumbers(stream main_pb.Calculator_SendNumbersServer) error {
+	var sessionID string
+	if md, ok := metadata.FromIncomingContext(stream.Context()); ok {
+		if values := md.Get(sessionIDHeader); len(values) > 0 {
+			sessionID = values[0]
+		}
+	}
+	if sessionID == "" {
+		return s.sumNumbers(stream)
+	}
+
+	if !s.sessions.acquire(sessionID) {
+		return status.Errorf(codes.Aborted, "session %q is already being streamed", sessionID)
+	}
+	defer s.sessions.release(sessionID)
+
+	cp, err := s.checkpoints.Load(sessionID)
+	if err != nil {
+		log.Println("Error loading checkpoint:", err)
+		return status.Error(codes.Internal, "loading checkpoint failed")
+	}
+	defer func() { stream.SetTrailer(lastSeqMD(cp.LastSeq)) }()
+
+	if err := stream.SendHeader(lastSeqMD(cp.LastSeq)); err != nil {
+		return err
+	}
+	if cp.LastSeq > 0 {
+		log.Printf("Resuming session %s after seq %d", sessionID, cp.LastSeq)
+	}
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			// The session is done, its checkpoint would only grow the store and let the session be appended to
+			if err := s.checkpoints.Delete(sessionID); err != nil {
+				log.Println("Error deleting checkpoint:", err)
+			}
+			return stream.SendAndClose(toNumberResponse(cp))
+		}
+		if err != nil {
+			return recvError(stream, err)
+		}
+
+		if req.GetSessionId() != "" && req.GetSessionId() != sessionID {
+			return status.Errorf(codes.InvalidArgument, "message for session %q on a stream for session %q", req.GetSessionId(), sessionID)
+		}
+		switch seq := req.GetSeq(); {
+		case seq == 0:
+			return status.Error(codes.InvalidArgument, "seq is required in a session, the first number is seq 1")
+		case seq <= cp.LastSeq:
+			continue
+		case seq > cp.LastSeq+1:
+			return status.Errorf(codes.FailedPrecondition, "got seq %d, expected %d", seq, cp.LastSeq+1)
+		}
+
+		next := cp
+		next.LastSeq = req.GetSeq()
+		next.Count++
+		if next.Sum, err = addNumber(cp.Sum, req.GetNumber(), next.Count); err != nil {
+			return err
+		}
+		next.UpdatedAt = time.Now()
+		if err := s.checkpoints.Save(next); err != nil {
+			log.Println("Error saving checkpoint:", err)
+			return status.Error(codes.Internal, "saving checkpoint failed")
+		}
+		cp = next
+	}
+}
+
+func (s *server) sumNumbers(stream main_pb.Calculator_SendNumbersServer) error {
+	var cp Checkpoint
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(toNumberResponse(cp))
+		}
+		if err != nil {
+			return recvError(stream, err)
+		}
+
+		log.Println(req.GetNumber())
+		cp.Count++
+		if cp.Sum, err = addNumber(cp.Sum, req.GetNumber(), cp.Count); err != nil {
+			return err
+		}
+	}
+}
+
+// addNumber adds in int64, a sum that no longer fits the int32 on the wire ends the call with OutOfRange instead of wrapping
+func addNumber(sum, number int32, count uint64) (int32, error) {
+	next := int64(sum) + int64(number)
+	if next > math.MaxInt32 || next < math.MinInt32 {
+		return sum, status.Errorf(codes.OutOfRange, "sum of %d numbers overflows int32", count)
+	}
+	return int32(next), nil
+}
+
+// recvError turns a failed Recv into the status the call ends with, a client that went away is not a server error
+func recvError(stream grpc.ServerStream, err error) error {
+	if ctxErr := stream.Context().Err(); ctxErr != nil {
+		return status.FromContextError(ctxErr).Err()
+	}
+	log.Println("Error receiving number:", err)
+	return err
+}
+
+func lastSeqMD(seq uint64) metadata.MD {
+	return metadata.Pairs(lastSeqHeader, strconv.FormatUint(seq, 10))
+}
+
+func toNumberResponse(cp Checkpoint) *main_pb.NumberResponse {
+	return &main_pb.NumberResponse{
+		Sum:       cp.Sum,
+		SessionId: cp.SessionID,
+		LastSeq:   cp.LastSeq,
+		Count:     cp.Count,
+	}
+}
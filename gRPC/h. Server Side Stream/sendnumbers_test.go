@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	main_pb "grpcstreams/proto/gen"
+
+	"pkg/grpctest"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// openSession opens a SendNumbers stream for sessionID, waiting out a previous stream the server hasn't released yet
+func openSession(t *testing.T, ctx context.Context, client main_pb.CalculatorClient, sessionID string) (main_pb.Calculator_SendNumbersClient, uint64) {
+	t.Helper()
+
+	for {
+		stream, err := client.SendNumbers(metadata.AppendToOutgoingContext(ctx, sessionIDHeader, sessionID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		header, err := stream.Header()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if values := header.Get(lastSeqHeader); len(values) == 1 {
+			lastSeq, err := strconv.ParseUint(values[0], 10, 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return stream, lastSeq
+		}
+
+		_, err = stream.CloseAndRecv()
+		if status.Code(err) != codes.Aborted {
+			t.Fatalf("opening session %s: %v", sessionID, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func sendRange(t *testing.T, stream main_pb.Calculator_SendNumbersClient, from, to uint64) {
+	t.Helper()
+	for seq := from; seq <= to; seq++ {
+		if err := stream.Send(&main_pb.NumberRequest{Number: int32(seq), Seq: seq}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSendNumbersSkipsAcknowledgedSeqs(t *testing.T) {
+	// 1 to 5 were checkpointed by an earlier attempt whose acks got lost
+	checkpoints := newMemoryCheckpoints()
+	checkpoints.Save(Checkpoint{SessionID: "upload-1", LastSeq: 5, Count: 5, Sum: 15, UpdatedAt: time.Now()})
+	srv := grpctest.Start(t, func(s *grpc.Server) {
+		main_pb.RegisterCalculatorServer(s, newServer(0, checkpoints))
+	})
+	client := grpctest.NewClient(srv, main_pb.NewCalculatorClient)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Re-send 4 and 5, they must not be counted twice
+	stream, lastSeq := openSession(t, ctx, client, "upload-1")
+	if lastSeq != 5 {
+		t.Fatalf("resumed last-seq = %d; want 5", lastSeq)
+	}
+	sendRange(t, stream, 4, 10)
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetSum() != 55 || res.GetCount() != 10 || res.GetLastSeq() != 10 {
+		t.Errorf("response = %v; want sum 55 of 10 numbers up to seq 10", res)
+	}
+	if got := stream.Trailer().Get(lastSeqHeader); len(got) != 1 || got[0] != "10" {
+		t.Errorf("last-seq trailer = %v; want 10", got)
+	}
+
+	// The completed session is forgotten, the same ID starts a new sum
+	stream, lastSeq = openSession(t, ctx, client, "upload-1")
+	if lastSeq != 0 {
+		t.Errorf("last-seq after completion = %d; want 0", lastSeq)
+	}
+	sendRange(t, stream, 1, 2)
+	if res, err := stream.CloseAndRecv(); err != nil || res.GetSum() != 3 {
+		t.Errorf("new session = %v, %v; want sum 3", res, err)
+	}
+	if n := len(checkpoints.checkpoints); n != 0 {
+		t.Errorf("%d checkpoints left after every session completed; want 0", n)
+	}
+}
+
+func TestSendNumbersResumesAfterDisconnect(t *testing.T) {
+	client := newBufconnClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	attemptCtx, drop := context.WithCancel(ctx)
+	stream, _ := openSession(t, attemptCtx, client, "upload-2")
+	sendRange(t, stream, 1, 5)
+	drop()
+
+	// However much of the first attempt made it in, resuming from the header completes the sum exactly once
+	stream, lastSeq := openSession(t, ctx, client, "upload-2")
+	if lastSeq > 5 {
+		t.Fatalf("resumed last-seq = %d; want at most 5", lastSeq)
+	}
+	sendRange(t, stream, lastSeq+1, 10)
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetSum() != 55 || res.GetCount() != 10 {
+		t.Errorf("response = %v; want sum 55 of 10 numbers", res)
+	}
+}
+
+func TestSendNumbersRejectsGapsAndForeignSessions(t *testing.T) {
+	client := newBufconnClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name string
+		req  *main_pb.NumberRequest
+		want codes.Code
+	}{
+		{"gap", &main_pb.NumberRequest{Number: 1, Seq: 2}, codes.FailedPrecondition},
+		{"missing seq", &main_pb.NumberRequest{Number: 1}, codes.InvalidArgument},
+		{"foreign session", &main_pb.NumberRequest{Number: 1, Seq: 1, SessionId: "someone-else"}, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, _ := openSession(t, ctx, client, "session-"+tt.name)
+			stream.Send(tt.req)
+			_, err := stream.CloseAndRecv()
+			if status.Code(err) != tt.want {
+				t.Errorf("err = %v; want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendNumbersWithoutSession(t *testing.T) {
+	client := newBufconnClient(t)
+
+	stream, err := client.SendNumbers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for num := range 9 {
+		stream.Send(&main_pb.NumberRequest{Number: int32(num)})
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil || res.GetSum() != 36 {
+		t.Errorf("sum = %d, err = %v; want 36", res.GetSum(), err)
+	}
+}
+
+func TestSendNumbersOverflowIsOutOfRange(t *testing.T) {
+	checkpoints := newMemoryCheckpoints()
+	srv := grpctest.Start(t, func(s *grpc.Server) {
+		main_pb.RegisterCalculatorServer(s, newServer(0, checkpoints))
+	})
+	client := grpctest.NewClient(srv, main_pb.NewCalculatorClient)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.SendNumbers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.Send(&main_pb.NumberRequest{Number: math.MinInt32})
+	stream.Send(&main_pb.NumberRequest{Number: -1})
+	if res, err := stream.CloseAndRecv(); status.Code(err) != codes.OutOfRange {
+		t.Errorf("sum below MinInt32 = %v, %v; want OutOfRange", res, err)
+	}
+
+	// The checkpoint keeps the last sum that fit, not a wrapped one
+	session, _ := openSession(t, ctx, client, "big")
+	session.Send(&main_pb.NumberRequest{Number: math.MaxInt32, Seq: 1})
+	session.Send(&main_pb.NumberRequest{Number: 1, Seq: 2})
+	if res, err := session.CloseAndRecv(); status.Code(err) != codes.OutOfRange {
+		t.Fatalf("sum above MaxInt32 = %v, %v; want OutOfRange", res, err)
+	}
+	if lastSeq := session.Trailer().Get(lastSeqHeader); len(lastSeq) != 1 || lastSeq[0] != "1" {
+		t.Errorf("last-seq trailer = %v; want 1", lastSeq)
+	}
+	if cp, err := checkpoints.Load("big"); err != nil || cp.Sum != math.MaxInt32 || cp.LastSeq != 1 {
+		t.Errorf("checkpoint = %+v, %v; want sum MaxInt32 at seq 1", cp, err)
+	}
+}
+
+func TestFileCheckpointsSurviveRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+
+	store, err := newFileCheckpoints(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(Checkpoint{SessionID: "upload-1", LastSeq: 3, Count: 3, Sum: 6, UpdatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Save wrote the file (%v); writes are batched until Flush", err)
+	}
+	if err := store.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := newFileCheckpoints(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp, err := reopened.Load("upload-1")
+	if err != nil || cp.LastSeq != 3 || cp.Sum != 6 {
+		t.Errorf("reloaded checkpoint = %+v, err = %v; want seq 3 sum 6", cp, err)
+	}
+}
+
+func TestFileCheckpointsForgetCompletedSessions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+	store, err := newFileCheckpoints(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Save(Checkpoint{SessionID: "done", LastSeq: 2, Count: 2, Sum: 3, UpdatedAt: time.Now()})
+	store.Save(Checkpoint{SessionID: "open", LastSeq: 1, Count: 1, Sum: 1, UpdatedAt: time.Now()})
+	store.Delete("done")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go store.Run(ctx, 5*time.Millisecond)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		reopened, err := newFileCheckpoints(path)
+		if err == nil && len(reopened.checkpoints) > 0 {
+			if _, ok := reopened.checkpoints["done"]; ok || len(reopened.checkpoints) != 1 {
+				t.Errorf("file holds %v; want only the open session", reopened.checkpoints)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Run never wrote the checkpoints")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCheckpointsExpire(t *testing.T) {
+	start := time.Now()
+	now := start
+	store := newMemoryCheckpoints()
+	store.ttl = time.Hour
+	store.now = func() time.Time { return now }
+
+	store.Save(Checkpoint{SessionID: "abandoned", LastSeq: 3, UpdatedAt: start})
+	now = start.Add(30 * time.Minute)
+	if cp, _ := store.Load("abandoned"); cp.LastSeq != 3 {
+		t.Fatalf("checkpoint within the ttl = %+v; want it kept", cp)
+	}
+
+	now = start.Add(2 * time.Hour)
+	if cp, _ := store.Load("abandoned"); cp.LastSeq != 0 {
+		t.Errorf("checkpoint past the ttl = %+v; want it gone", cp)
+	}
+	store.Save(Checkpoint{SessionID: "new", LastSeq: 1, UpdatedAt: now})
+	if _, ok := store.checkpoints["abandoned"]; ok || len(store.checkpoints) != 1 {
+		t.Errorf("checkpoints = %v; want the expired one swept by the next new session", store.checkpoints)
+	}
+}
+
+func TestCheckpointsCapped(t *testing.T) {
+	start := time.Now()
+	store := newMemoryCheckpoints()
+	store.max = 3
+	for i := range 5 {
+		store.Save(Checkpoint{SessionID: strconv.Itoa(i), LastSeq: 1, UpdatedAt: start.Add(time.Duration(i) * time.Second)})
+	}
+	// Updating an existing session never evicts
+	store.Save(Checkpoint{SessionID: "2", LastSeq: 2, UpdatedAt: start.Add(time.Minute)})
+
+	if len(store.checkpoints) != 3 {
+		t.Fatalf("%d checkpoints; want the cap of 3", len(store.checkpoints))
+	}
+	for _, id := range []string{"0", "1"} {
+		if cp, _ := store.Load(id); cp.LastSeq != 0 {
+			t.Errorf("session %s = %+v; want the least recently updated evicted", id, cp)
+		}
+	}
+	if cp, _ := store.Load("2"); cp.LastSeq != 2 {
+		t.Errorf("session 2 = %+v; want its update kept", cp)
+	}
+}
@@ -1,119 +1,116 @@
 package main
 
 import (
-	"bufio"
 	"context"
-	"fmt"
+	"flag"
 	main_pb "grpcstreams/proto/gen"
-	"io"
 	"log"
+	"log/slog"
 	"net"
 	"os"
-	"strings"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"pkg/chat"
+	"pkg/health"
+	"pkg/interceptors"
+
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/reflection"
 )
 
 type server struct {
 	main_pb.UnimplementedCalculatorServer
+	broker *chat.Broker
+
+	// fibonacciInterval paces GenerateFibonacci, one message per interval (0 sends as fast as flow control allows)
+	fibonacciInterval time.Duration
+
+	checkpoints CheckpointStore
+	sessions    numberSessions
 }
 
 func (s *server) Add(ctx context.Context, req *main_pb.AddRequest) (*main_pb.AddResponse, error) {
 	return &main_pb.AddResponse{Sum: req.A + req.B}, nil
 }
 
-func (s *server) GenerateFibonacci(req *main_pb.FibonacciRequest, stream main_pb.Calculator_GenerateFibonacciServer) error {
-	n := req.Count
-	a, b := 0, 1
-
-	for range int(n) {
-		err := stream.Send(&main_pb.FibonacciResponse{Number: int32(a)})
-		if err != nil {
-			return nil
-		}
-		a, b = b, a+b
-		time.Sleep(time.Second) // Simulate processing time
+func newServer(fibonacciInterval time.Duration, checkpoints CheckpointStore) *server {
+	return &server{
+		broker:            chat.NewBroker(chat.BrokerConfig{BufferSize: 64, HistorySize: 50}),
+		fibonacciInterval: fibonacciInterval,
+		checkpoints:       checkpoints,
 	}
-	return nil
 }
 
-func (s *server) SendNumbers(stream main_pb.Calculator_SendNumbersServer) error {
-
-	var sum int32
-
-	for {
-		req, err := stream.Recv()
-		if err == io.EOF {
-			return stream.SendAndClose(&main_pb.NumberResponse{Sum: sum})
-		}
-		if err != nil {
-			log.Fatalln(err)
-			return err
-		}
-
-		log.Println(req.GetNumber())
-		sum += req.GetNumber()
+func main() {
+	fibonacciInterval := flag.Duration("fib-interval", time.Second, "pause between GenerateFibonacci messages, simulates processing time")
+	debugAddress := flag.String("debug-addr", "localhost:6060", "serve expvar metrics at /debug/vars, empty disables it")
+	reflectionFlag := flag.String("reflection", "off", "server reflection: off or public (development only, there is no auth here)")
+	checkpointFile := flag.String("checkpoint-file", "", "persist SendNumbers checkpoints to this JSON file (default in memory)")
+	flag.Parse()
+
+	reflectionMode, err := health.ParseReflectionMode(*reflectionFlag)
+	if err != nil || reflectionMode == health.ReflectionAuthenticated {
+		log.Fatalln("-reflection must be off or public")
 	}
-}
 
-func (s *server) Chat(stream main_pb.Calculator_ChatServer) error {
+	healthServer := health.NewServer(main_pb.Calculator_ServiceDesc.ServiceName)
 
-	reader := bufio.NewReader(os.Stdin)
-
-	for {
-		// Receiving values/messages from the stream
-		req, err := stream.Recv()
-		if err == io.EOF {
-			break
-		}
+	var checkpoints CheckpointStore = newMemoryCheckpoints()
+	var persisted *fileCheckpoints
+	if *checkpointFile != "" {
+		persisted, err = newFileCheckpoints(*checkpointFile)
 		if err != nil {
 			log.Fatalln(err)
-			return err
 		}
-		log.Println("Received Message:", req.GetMessage())
-
-		// Read input from the terminal
-		fmt.Println("Enter Response:")
-		inputMsg, err := reader.ReadString('\n')
-		if err != nil {
-			log.Fatalln("Error reading message from user in server:", err)
-			return err
-		}
-
-		responseMsg := &main_pb.ChatMessage{Message: strings.TrimSpace(inputMsg)}
-
-		// Sending data/values through the stream
-		err = stream.Send(responseMsg)
-		if err != nil {
-			log.Fatalln("Error sending value to stream:", err)
-			return err
-		}
-
+		checkpoints = persisted
+		healthServer.AddCheck("checkpoints", persisted.Check)
 	}
 
-	fmt.Println("Returning control")
-	return nil
-}
-
-func main() {
-
 	lis, err := net.Listen("tcp", "localhost:50051")
 	if err != nil {
 		log.Fatalln(err)
 	}
 
-	grpcServer := grpc.NewServer()
-	main_pb.RegisterCalculatorServer(grpcServer, &server{})
-
-	// Don't use in production!
-	reflection.Register(grpcServer)
+	metrics := interceptors.NewMetrics()
+	metrics.Publish("grpc_server")
+	if *debugAddress != "" {
+		go func() {
+			log.Println("Debug server stopped:", interceptors.ServeDebug(*debugAddress))
+		}()
+	}
 
-	log.Println("Running gPRC server at:", "localhost:50051")
-	err = grpcServer.Serve(lis)
-	if err != nil {
-		log.Fatalln(err)
+	// Streams get no default deadline, Chat and paced GenerateFibonacci calls are meant to stay open
+	grpcServer := grpc.NewServer(interceptors.ServerOptions(interceptors.Config{
+		Logger:       slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+		Metrics:      metrics,
+		UnaryTimeout: 10 * time.Second,
+	})...)
+	main_pb.RegisterCalculatorServer(grpcServer, newServer(*fibonacciInterval, checkpoints))
+	healthServer.Register(grpcServer)
+	health.RegisterReflection(grpcServer, reflectionMode)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go healthServer.Run(ctx, 10*time.Second)
+	if persisted != nil {
+		go persisted.Run(ctx, time.Second)
 	}
 
+	go func() {
+		log.Println("Running gPRC server at:", "localhost:50051")
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Fatalln(err)
+		}
+	}()
+
+	// Chat streams never finish on their own, the timeout cuts them off
+	<-ctx.Done()
+	log.Println("Shutting down, draining in-flight calls")
+	healthServer.Shutdown(grpcServer, 2*time.Second, 15*time.Second)
+	if persisted != nil {
+		if err := persisted.Flush(); err != nil {
+			log.Println("Error writing checkpoints:", err)
+		}
+	}
 }
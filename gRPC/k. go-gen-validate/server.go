@@ -2,54 +2,86 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	pb "simplegPRCServer/proto/gen"
 
+	"pkg/health"
+	"pkg/interceptors"
+
 	"google.golang.org/grpc"
 
-	"google.golang.org/grpc/codes"
 	_ "google.golang.org/grpc/encoding/gzip" // compression in server
-	"google.golang.org/grpc/reflection"
-	"google.golang.org/grpc/status"
 )
 
 type server struct {
 	pb.UnimplementedGreeterServer
 }
 
+// Greet only sees requests that passed their validate rules, the interceptor suite rejects the rest
 func (s *server) Greet(ctx context.Context, req *pb.HelloRequest) (*pb.HelloResponse, error) {
-
-	if err := req.Validate(); err != nil {
-		log.Printf("validation failed: %v", err)
-
-		return nil, status.Errorf(codes.InvalidArgument, "Invalid request: %v", err)
-	}
-
 	return &pb.HelloResponse{Message: fmt.Sprintf("Hello user %s.", req.Name)}, nil
 }
 
 func main() {
+	debugAddress := flag.String("debug-addr", "localhost:6062", "serve expvar metrics at /debug/vars, empty disables it")
+	flag.Parse()
 
 	serveAddress := "127.0.0.1:50001"
 
+	// Reflection lets grpcurl list the services, there is no auth here so only "public" (development) can enable it
+	reflectionMode, err := health.ParseReflectionMode(os.Getenv("GRPC_REFLECTION"))
+	if err != nil || reflectionMode == health.ReflectionAuthenticated {
+		log.Fatal("GRPC_REFLECTION must be off or public.")
+	}
+
 	list, err := net.Listen("tcp", serveAddress)
 	if err != nil {
 		log.Fatal("Failed to listen.", err)
 	}
 
-	grpcServer := grpc.NewServer()
-
-	pb.RegisterGreeterServer(grpcServer, &server{})
+	metrics := interceptors.NewMetrics()
+	metrics.Publish("grpc_server")
+	if *debugAddress != "" {
+		go func() {
+			log.Println("Debug server stopped:", interceptors.ServeDebug(*debugAddress))
+		}()
+	}
 
-	reflection.Register(grpcServer)
+	grpcServer := grpc.NewServer(interceptors.ServerOptions(interceptors.Config{
+		Logger:       slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+		Metrics:      metrics,
+		UnaryTimeout: 10 * time.Second,
+		Validate:     true,
+	})...)
 
-	log.Println("Server is running at", serveAddress)
-	err = grpcServer.Serve(list)
-	if err != nil {
-		log.Fatal("Failed to server.", err)
-	}
+	pb.RegisterGreeterServer(grpcServer, &server{})
 
+	// Nothing to wait for here, the first check marks Greeter SERVING
+	healthServer := health.NewServer(pb.Greeter_ServiceDesc.ServiceName)
+	healthServer.Register(grpcServer)
+	health.RegisterReflection(grpcServer, reflectionMode)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go healthServer.Run(ctx, 10*time.Second)
+
+	go func() {
+		log.Println("Server is running at", serveAddress)
+		if err := grpcServer.Serve(list); err != nil {
+			log.Fatal("Failed to server.", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down, draining in-flight calls")
+	healthServer.Shutdown(grpcServer, 2*time.Second, 15*time.Second)
 }
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	pb "simplegPRCServer/proto/gen"
+
+	"pkg/grpctest"
+	"pkg/interceptors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newBufconnClient(t *testing.T) pb.GreeterClient {
+	t.Helper()
+
+	srv := grpctest.Start(t, func(s *grpc.Server) {
+		pb.RegisterGreeterServer(s, &server{})
+	}, grpctest.WithServerOptions(interceptors.ServerOptions(interceptors.Config{
+		Logger:   slog.New(slog.DiscardHandler),
+		Validate: true,
+	})...))
+	return grpctest.NewClient(srv, pb.NewGreeterClient)
+}
+
+func TestGreetValidatedByInterceptor(t *testing.T) {
+	client := newBufconnClient(t)
+
+	res, err := client.Greet(context.Background(), &pb.HelloRequest{Name: "Alice"})
+	if err != nil || res.GetMessage() != "Hello user Alice." {
+		t.Fatalf("Greet(Alice) = %v, %v", res, err)
+	}
+
+	// Too short and without a single letter, both rules have to be reported
+	_, err = client.Greet(context.Background(), &pb.HelloRequest{Name: "12"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("err = %v; want InvalidArgument", err)
+	}
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			violations = badRequest.GetFieldViolations()
+		}
+	}
+	if len(violations) != 2 {
+		t.Fatalf("violations = %v; want the length and the pattern rule", violations)
+	}
+	for _, v := range violations {
+		if v.GetField() != "Name" {
+			t.Errorf("violation field = %q; want Name", v.GetField())
+		}
+	}
+}
+
+func TestGreetValidationGolden(t *testing.T) {
+	client := newBufconnClient(t)
+
+	// Each request breaks other rules, the golden files pin the violations clients get to see
+	for name, req := range map[string]*pb.HelloRequest{
+		"greet_invalid_empty":    {},
+		"greet_invalid_too_long": {Name: "Alexander"},
+		"greet_invalid_digits":   {Name: "12345"},
+	} {
+		_, err := client.Greet(context.Background(), req)
+		grpctest.Golden(t, name, status.Convert(err).Proto())
+	}
+}
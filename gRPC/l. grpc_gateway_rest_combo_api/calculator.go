@@ -0,0 +1,53 @@
+package main
+
+import (
+	"io"
+	"math"
+
+	pb "grpc_gateway_project/proto/gen"
+
+	"pkg/calculator"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type calculatorServer struct {
+	pb.UnimplementedCalculatorServer
+}
+
+// GenerateFibonacci streams one term per message, the gateway writes each as a line of JSON
+func (s *calculatorServer) GenerateFibonacci(req *pb.FibonacciRequest, stream pb.Calculator_GenerateFibonacciServer) error {
+	for i, value := range calculator.Fibonacci(int(req.GetCount())) {
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		if err := stream.Send(&pb.FibonacciResponse{Index: uint64(i), Value: value.String()}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+SendNumbers sums the streamed numbers, over the gateway that is a request body with one JSON object per line.
+- The sum is an int32 on the wire, one that no longer fits ends the call with OutOfRange instead of wrapping
+*/
+func (s *calculatorServer) SendNumbers(stream pb.Calculator_SendNumbersServer) error {
+	res := &pb.NumberResponse{}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(res)
+		}
+		if err != nil {
+			return err
+		}
+		sum := int64(res.Sum) + int64(req.GetNumber())
+		if sum > math.MaxInt32 || sum < math.MinInt32 {
+			return status.Errorf(codes.OutOfRange, "sum of %d numbers overflows int32", res.Count+1)
+		}
+		res.Sum = int32(sum)
+		res.Count++
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"embed"
+	"net/http"
+
+	"github.com/swaggest/swgui/v5emb"
+)
+
+// apiDocs is generated from proto/main.proto with tools/cmd/openapi, see commands.txt
+//
+//go:embed docs/swagger.json docs/openapi.json
+var apiDocs embed.FS
+
+// registerDocs serves the OpenAPI documents and a Swagger UI reading openapi.json under /docs
+func registerDocs(mux *http.ServeMux) {
+	mux.Handle("GET /docs/swagger.json", http.FileServerFS(apiDocs))
+	mux.Handle("GET /docs/openapi.json", http.FileServerFS(apiDocs))
+	mux.Handle("GET /docs/", v5emb.New("Gateway API", "/docs/openapi.json", "/docs/"))
+	mux.Handle("GET /docs", http.RedirectHandler("/docs/", http.StatusMovedPermanently))
+}
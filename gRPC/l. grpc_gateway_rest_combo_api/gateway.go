@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// forwardedHeaders pass from the HTTP request into gRPC metadata and from the gRPC response back out, the gateway keeps every other header to itself
+var forwardedHeaders = []string{"Authorization", "X-Request-Id"}
+
+// responseHeaders only pass from the gRPC response out, they announce deprecated API versions
+var responseHeaders = []string{"Deprecation", "Sunset", "Link"}
+
+/*
+newServeMux is the gateway mux with our error format and header allowlist.
+- Errors of unary calls and of routing are a problem+json body
+- Streams are newline-delimited JSON, {"result": ...} per message, an error ends the stream with an {"error": ...} line
+- A stream failing before its first message still gets the HTTP status of its error
+*/
+func newServeMux() *runtime.ServeMux {
+	return runtime.NewServeMux(
+		runtime.WithErrorHandler(problemErrorHandler),
+		runtime.WithIncomingHeaderMatcher(incomingHeader),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeader),
+		runtime.WithOutgoingTrailerMatcher(outgoingHeader),
+	)
+}
+
+func incomingHeader(key string) (string, bool) {
+	if slices.Contains(forwardedHeaders, http.CanonicalHeaderKey(key)) {
+		return strings.ToLower(key), true
+	}
+	return "", false
+}
+
+func outgoingHeader(key string) (string, bool) {
+	header := http.CanonicalHeaderKey(key)
+	if slices.Contains(forwardedHeaders, header) || slices.Contains(responseHeaders, header) {
+		return header, true
+	}
+	return "", false
+}
+
+/*
+problem is our application/problem+json error body (RFC 9457).
+- Title and Status describe the HTTP status, Code is the gRPC status code it came from and Detail its message
+- Violations lists the fields a request failed validation on
+*/
+type problem struct {
+	Type       string      `json:"type"`
+	Title      string      `json:"title"`
+	Status     int         `json:"status"`
+	Detail     string      `json:"detail,omitempty"`
+	Instance   string      `json:"instance,omitempty"`
+	Code       string      `json:"code"`
+	RequestID  string      `json:"request_id,omitempty"`
+	Violations []violation `json:"violations,omitempty"`
+}
+
+type violation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// problemErrorHandler replaces the gateway's default error body with a problem, allowlisted metadata of the failed call is still forwarded
+func problemErrorHandler(ctx context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	var httpStatusErr *runtime.HTTPStatusError
+	httpStatus := 0
+	if errors.As(err, &httpStatusErr) {
+		httpStatus = httpStatusErr.HTTPStatus
+		err = httpStatusErr.Err
+	}
+	st := status.Convert(err)
+	if httpStatus == 0 {
+		httpStatus = runtime.HTTPStatusFromCode(st.Code())
+	}
+
+	// Errors usually end a call trailers-only, so the allowlisted metadata can sit in either half
+	requestID := r.Header.Get("X-Request-Id")
+	if md, ok := runtime.ServerMetadataFromContext(ctx); ok {
+		for _, part := range []metadata.MD{md.HeaderMD, md.TrailerMD} {
+			for key, values := range part {
+				header, ok := outgoingHeader(key)
+				if !ok {
+					continue
+				}
+				w.Header().Del(header)
+				for _, value := range values {
+					w.Header().Add(header, value)
+				}
+				if header == "X-Request-Id" && len(values) > 0 {
+					requestID = values[0]
+				}
+			}
+		}
+	}
+
+	body := problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(httpStatus),
+		Status:    httpStatus,
+		Detail:    st.Message(),
+		Instance:  r.URL.Path,
+		Code:      st.Code().String(),
+		RequestID: requestID,
+	}
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, fv := range badRequest.GetFieldViolations() {
+				body.Violations = append(body.Violations, violation{Field: fv.GetField(), Description: fv.GetDescription()})
+			}
+		}
+	}
+
+	w.Header().Del("Trailer")
+	w.Header().Del("Transfer-Encoding")
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(httpStatus)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Println("Failed to write problem:", err)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func (env singlePortEnv) do(t *testing.T, method, path, body string, header http.Header) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, "https://"+env.addr+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	res, err := env.httpClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { res.Body.Close() })
+	return res
+}
+
+func decodeProblem(t *testing.T, res *http.Response) problem {
+	t.Helper()
+	if ct := res.Header.Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("Content-Type = %q; want application/problem+json", ct)
+	}
+	var p problem
+	if err := json.NewDecoder(res.Body).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestGatewayProblemJSON(t *testing.T) {
+	env := startSinglePort(t)
+
+	res := env.do(t, http.MethodPost, "/v1/greet", `{"name":"12"}`, http.Header{"X-Request-Id": {"req-1"}})
+	p := decodeProblem(t, res)
+	if res.StatusCode != http.StatusBadRequest || p.Status != http.StatusBadRequest || p.Code != "InvalidArgument" || p.Instance != "/v1/greet" {
+		t.Errorf("problem = %+v (HTTP %d); want 400 InvalidArgument for /v1/greet", p, res.StatusCode)
+	}
+	if len(p.Violations) != 2 || p.Violations[0].Field != "Name" {
+		t.Errorf("violations = %+v; want both Name rules", p.Violations)
+	}
+	if p.RequestID != "req-1" || res.Header.Get("X-Request-Id") != "req-1" {
+		t.Errorf("request id = %q, header %q; want req-1 in both", p.RequestID, res.Header.Get("X-Request-Id"))
+	}
+
+	res = env.do(t, http.MethodGet, "/v1/nothing-here", "", nil)
+	if p := decodeProblem(t, res); res.StatusCode != http.StatusNotFound || p.Title != "Not Found" {
+		t.Errorf("unknown route = HTTP %d %+v; want 404 problem", res.StatusCode, p)
+	}
+}
+
+func TestGatewayDeadlineBudget(t *testing.T) {
+	env := startSinglePort(t)
+
+	res := env.do(t, http.MethodPost, "/v1/greet", `{"name":"Alice"}`, http.Header{"Grpc-Timeout": {"5S"}})
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("5s budget = HTTP %d; want 200", res.StatusCode)
+	}
+
+	// 30ms don't cover the gateway's reserve, the call fails before reaching the server
+	res = env.do(t, http.MethodPost, "/v1/greet", `{"name":"Alice"}`, http.Header{"Grpc-Timeout": {"30m"}})
+	p := decodeProblem(t, res)
+	if res.StatusCode != http.StatusGatewayTimeout || p.Code != "DeadlineExceeded" || !strings.HasPrefix(p.Detail, "budget:") {
+		t.Errorf("30ms budget = HTTP %d %+v; want 504 DeadlineExceeded from the budget", res.StatusCode, p)
+	}
+}
+
+func TestGatewayForwardsAllowlistedHeaders(t *testing.T) {
+	env := startSinglePort(t)
+
+	res := env.do(t, http.MethodPost, "/v1/greet", `{"name":"Alice"}`, http.Header{"X-Request-Id": {"req-2"}})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("greet = HTTP %d", res.StatusCode)
+	}
+	if got := res.Header.Get("X-Request-Id"); got != "req-2" {
+		t.Errorf("X-Request-Id = %q; want the request's req-2 echoed by the gRPC server", got)
+	}
+
+	// Without one the gRPC server makes one up, it still comes back out
+	res = env.do(t, http.MethodPost, "/v1/greet", `{"name":"Alice"}`, nil)
+	if res.Header.Get("X-Request-Id") == "" {
+		t.Error("X-Request-Id missing from the response")
+	}
+	for key := range res.Header {
+		if strings.HasPrefix(key, "Grpc-Metadata-") {
+			t.Errorf("metadata %s leaked out of the allowlist", key)
+		}
+	}
+}
+
+func TestGatewayStreamsNDJSON(t *testing.T) {
+	env := startSinglePort(t)
+
+	res := env.do(t, http.MethodGet, "/v1/fibonacci/10", "", nil)
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		t.Fatalf("fibonacci = HTTP %d %s", res.StatusCode, body)
+	}
+	var values []string
+	lines := bufio.NewScanner(res.Body)
+	for lines.Scan() {
+		var line struct {
+			Result struct {
+				Index string `json:"index"`
+				Value string `json:"value"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(lines.Bytes(), &line); err != nil {
+			t.Fatalf("line %q: %v", lines.Text(), err)
+		}
+		values = append(values, line.Result.Value)
+	}
+	if len(values) != 10 || values[9] != "34" {
+		t.Errorf("fibonacci lines = %v; want 10 terms ending in 34", values)
+	}
+
+	res = env.do(t, http.MethodPost, "/v1/numbers:sum", "{\"number\":1}\n{\"number\":2}\n{\"number\":3}\n", nil)
+	var sum struct {
+		Sum   int32  `json:"sum"`
+		Count string `json:"count"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&sum); err != nil || sum.Sum != 6 || sum.Count != "3" {
+		t.Errorf("numbers:sum = %+v, %v; want sum 6 of 3 numbers", sum, err)
+	}
+
+	res = env.do(t, http.MethodPost, "/v1/numbers:sum", "{\"number\":2147483647}\n{\"number\":1}\n", nil)
+	if res.StatusCode != http.StatusBadRequest {
+		body, _ := io.ReadAll(res.Body)
+		t.Errorf("numbers:sum past MaxInt32 = HTTP %d %s; want 400 OutOfRange", res.StatusCode, body)
+	}
+
+	// Stream errors are a final {"error": ...} line, the status code only tells when nothing was streamed yet
+	res = env.do(t, http.MethodGet, "/v1/fibonacci/0", "", nil)
+	var line struct {
+		Error struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&line); err != nil || res.StatusCode != http.StatusBadRequest || !strings.Contains(line.Error.Message, "Count") {
+		t.Errorf("fibonacci/0 = HTTP %d %+v, %v; want 400 with the Count violation", res.StatusCode, line, err)
+	}
+}
+
+func TestGatewayServesDocs(t *testing.T) {
+	env := startSinglePort(t)
+
+	res := env.do(t, http.MethodGet, "/docs/openapi.json", "", nil)
+	var doc struct {
+		OpenAPI    string `json:"openapi"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]struct {
+					MinLength int    `json:"minLength"`
+					Pattern   string `json:"pattern"`
+				} `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	name := doc.Components.Schemas["main_apiHelloRequest"].Properties["name"]
+	if doc.OpenAPI == "" || name.MinLength != 5 || name.Pattern == "" {
+		t.Errorf("openapi %q, HelloRequest.name = %+v; want the validate rules of name", doc.OpenAPI, name)
+	}
+
+	res = env.do(t, http.MethodGet, "/docs", "", nil)
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(body), "swagger-ui") {
+		t.Errorf("/docs = HTTP %d; want the Swagger UI page", res.StatusCode)
+	}
+	res = env.do(t, http.MethodGet, "/docs/swagger-ui-bundle.js", "", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("Swagger UI assets = HTTP %d", res.StatusCode)
+	}
+}
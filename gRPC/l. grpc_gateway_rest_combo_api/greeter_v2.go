@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pbv2 "grpc_gateway_project/proto/gen/v2"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type greeterV2Server struct {
+	pbv2.UnimplementedGreeterServer
+}
+
+var greetings = map[string]string{"": "Hello", "en": "Hello", "de": "Hallo"}
+
+// Greet is the v2 of server.Greet, the validate rules already limited Locale to the keys of greetings
+func (s *greeterV2Server) Greet(ctx context.Context, req *pbv2.GreetRequest) (*pbv2.GreetResponse, error) {
+	name := strings.TrimSpace(req.GetFirstName() + " " + req.GetLastName())
+	return &pbv2.GreetResponse{
+		Message:   fmt.Sprintf("%s %s.", greetings[req.GetLocale()], name),
+		GreetedAt: timestamppb.Now(),
+	}, nil
+}
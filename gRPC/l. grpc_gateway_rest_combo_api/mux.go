@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+/*
+singlePort serves gRPC and HTTP on one TLS listener.
+- HTTP/2 requests with content-type application/grpc go to the gRPC server, everything else to the HTTP handler
+- Requests are routed inside one http.Server, sniffing connections with cmux would need a SETTINGS frame of its own that curl rejects
+- A client certificate is optional in the handshake so browsers get in, gRPC requests without one are rejected with Unauthenticated
+*/
+type singlePort struct {
+	grpcServer *grpc.Server
+	httpServer *http.Server
+	lis        net.Listener
+}
+
+// newSinglePort serves lis with TLS, tlsConfig is a mutual TLS server config whose client certificate becomes optional
+func newSinglePort(lis net.Listener, tlsConfig *tls.Config, grpcServer *grpc.Server, handler http.Handler) *singlePort {
+	tlsConfig = tlsConfig.Clone()
+	tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	tlsConfig.NextProtos = []string{"h2", "http/1.1"}
+
+	return &singlePort{
+		grpcServer: grpcServer,
+		httpServer: &http.Server{
+			Handler:           grpcHandler(grpcServer, handler),
+			TLSConfig:         tlsConfig,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+		lis: lis,
+	}
+}
+
+// Serve blocks until Shutdown
+func (p *singlePort) Serve() error {
+	err := p.httpServer.ServeTLS(p.lis, "", "")
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+/*
+Shutdown stops both servers gracefully, the caller drains health first.
+- HTTP goes first, it waits for gRPC calls on the port and for gateway requests, which are in-flight gRPC calls on the in-memory connection
+- GracefulStop then waits for whatever is left on the in-memory connection, after timeout the remaining calls are cut off
+- grpc.Server can't drain requests it got through ServeHTTP, so when HTTP didn't finish in time it is stopped right away
+*/
+func (p *singlePort) Shutdown(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := p.httpServer.Shutdown(ctx); err != nil {
+		log.Println("HTTP shutdown:", err)
+		p.grpcServer.Stop()
+		return
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		p.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		p.grpcServer.Stop()
+	}
+}
+
+// grpcHandler sends gRPC requests to grpcServer and the rest to other, the client certificate shows up in peer.FromContext as with grpcServer.Serve
+func grpcHandler(grpcServer *grpc.Server, other http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			other.ServeHTTP(w, r)
+			return
+		}
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			writeGRPCStatus(w, codes.Unauthenticated, "a client certificate is required for gRPC")
+			return
+		}
+		grpcServer.ServeHTTP(w, r)
+	})
+}
+
+// writeGRPCStatus ends a gRPC call with a trailers-only response
+func writeGRPCStatus(w http.ResponseWriter, code codes.Code, message string) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Grpc-Status", strconv.Itoa(int(code)))
+	w.Header().Set("Grpc-Message", message)
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	pb "grpc_gateway_project/proto/gen"
+
+	"pkg/health"
+	"pkg/interceptors"
+	"pkg/mtls"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type singlePortEnv struct {
+	addr       string
+	ca         *mtls.CA
+	client     *mtls.Issued
+	httpClient *http.Client
+}
+
+// startSinglePort serves the whole stack on a loopback port with throwaway certificates
+func startSinglePort(t *testing.T) singlePortEnv {
+	t.Helper()
+
+	ca, err := mtls.NewCA("test CA", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverCert, err := ca.IssueServer("localhost", time.Hour, "localhost", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gatewayCert, err := ca.IssueClient("gateway", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCert, err := ca.IssueClient("client", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{serverCert.TLS},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    ca.Pool(),
+	}
+	healthServer := health.NewServer(pb.Greeter_ServiceDesc.ServiceName)
+	healthServer.Check(context.Background())
+
+	grpcServer := newGRPCServer(credentials.NewTLS(tlsConfig), interceptors.NewMetrics(), healthServer, health.ReflectionOff)
+	conn := dialInMemory(grpcServer, credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{gatewayCert.TLS},
+		RootCAs:      ca.Pool(),
+		ServerName:   "localhost",
+	}))
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := newSinglePort(lis, tlsConfig, grpcServer, newGatewayHandler(context.Background(), conn, healthServer))
+	served := make(chan error, 1)
+	go func() { served <- port.Serve() }()
+	t.Cleanup(func() {
+		port.Shutdown(5 * time.Second)
+		if err := <-served; err != nil {
+			t.Errorf("Serve() = %v after shutdown", err)
+		}
+	})
+
+	return singlePortEnv{
+		addr:   lis.Addr().String(),
+		ca:     ca,
+		client: clientCert,
+		httpClient: &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: ca.Pool()},
+			ForceAttemptHTTP2: true,
+		}},
+	}
+}
+
+func (env singlePortEnv) dialGRPC(t *testing.T, certs ...tls.Certificate) *grpc.ClientConn {
+	t.Helper()
+	conn, err := grpc.NewClient(env.addr, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+		Certificates: certs,
+		RootCAs:      env.ca.Pool(),
+		ServerName:   "localhost",
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestSinglePortServesGRPC(t *testing.T) {
+	env := startSinglePort(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn := env.dialGRPC(t, env.client.TLS)
+	res, err := pb.NewGreeterClient(conn).Greet(ctx, &pb.HelloRequest{Name: "Alice"})
+	if err != nil || res.GetMessage() != "Hello user Alice." {
+		t.Fatalf("Greet(Alice) = %v, %v", res, err)
+	}
+
+	check, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil || check.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("health = %v, %v; want SERVING", check, err)
+	}
+
+	// The handshake lets clients without a certificate in for the REST routes, gRPC still refuses them
+	_, err = pb.NewGreeterClient(env.dialGRPC(t)).Greet(ctx, &pb.HelloRequest{Name: "Alice"})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Greet without client certificate err = %v; want Unauthenticated", err)
+	}
+}
+
+func TestSinglePortServesGateway(t *testing.T) {
+	env := startSinglePort(t)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"greet", http.MethodPost, "/v1/greet", `{"name":"Alice"}`, http.StatusOK},
+		{"validation", http.MethodPost, "/v1/greet", `{"name":"12"}`, http.StatusBadRequest},
+		{"readiness", http.MethodGet, "/readyz", "", http.StatusOK},
+		{"liveness", http.MethodGet, "/healthz", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, "https://"+env.addr+tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := env.httpClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+			body, _ := io.ReadAll(res.Body)
+
+			if res.StatusCode != tt.want {
+				t.Errorf("%s %s = %d %s; want %d", tt.method, tt.path, res.StatusCode, body, tt.want)
+			}
+			if res.ProtoMajor != 2 {
+				t.Errorf("served over %s; want HTTP/2", res.Proto)
+			}
+		})
+	}
+
+	// HTTP/1.1 clients are served on the same port
+	http1 := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: env.ca.Pool()}}}
+	res, err := http1.Post("https://"+env.addr+"/v1/greet", "application/json", strings.NewReader(`{"name":"Bobby"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK || res.ProtoMajor != 1 || !strings.Contains(string(body), "Hello user Bobby.") {
+		t.Errorf("HTTP/1.1 greet = %s %d %s", res.Proto, res.StatusCode, body)
+	}
+}
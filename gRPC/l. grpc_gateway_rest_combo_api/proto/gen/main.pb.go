@@ -112,6 +112,198 @@ func (x *HelloResponse) GetMessage() string {
 	return ""
 }
 
+type FibonacciRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Count         int32                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"` // Number of fibonacci numbers to generate
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FibonacciRequest) Reset() {
+	*x = FibonacciRequest{}
+	mi := &file_main_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FibonacciRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FibonacciRequest) ProtoMessage() {}
+
+func (x *FibonacciRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_main_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FibonacciRequest.ProtoReflect.Descriptor instead.
+func (*FibonacciRequest) Descriptor() ([]byte, []int) {
+	return file_main_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *FibonacciRequest) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type FibonacciResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         uint64                 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"` // Position in the sequence, starting at 0
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`  // Base 10, terms outgrow int64 after the 92nd
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FibonacciResponse) Reset() {
+	*x = FibonacciResponse{}
+	mi := &file_main_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FibonacciResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FibonacciResponse) ProtoMessage() {}
+
+func (x *FibonacciResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_main_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FibonacciResponse.ProtoReflect.Descriptor instead.
+func (*FibonacciResponse) Descriptor() ([]byte, []int) {
+	return file_main_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *FibonacciResponse) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *FibonacciResponse) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type NumberRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Number        int32                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NumberRequest) Reset() {
+	*x = NumberRequest{}
+	mi := &file_main_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NumberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NumberRequest) ProtoMessage() {}
+
+func (x *NumberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_main_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NumberRequest.ProtoReflect.Descriptor instead.
+func (*NumberRequest) Descriptor() ([]byte, []int) {
+	return file_main_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *NumberRequest) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+type NumberResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sum           int32                  `protobuf:"varint,1,opt,name=sum,proto3" json:"sum,omitempty"`
+	Count         uint64                 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"` // Numbers folded into sum
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NumberResponse) Reset() {
+	*x = NumberResponse{}
+	mi := &file_main_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NumberResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NumberResponse) ProtoMessage() {}
+
+func (x *NumberResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_main_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NumberResponse.ProtoReflect.Descriptor instead.
+func (*NumberResponse) Descriptor() ([]byte, []int) {
+	return file_main_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *NumberResponse) GetSum() int32 {
+	if x != nil {
+		return x.Sum
+	}
+	return 0
+}
+
+func (x *NumberResponse) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
 var File_main_proto protoreflect.FileDescriptor
 
 const file_main_proto_rawDesc = "" +
@@ -121,9 +313,24 @@ const file_main_proto_rawDesc = "" +
 	"\fHelloRequest\x12'\n" +
 	"\x04name\x18\x01 \x01(\tB\x13\xfaB\x10r\x0e\x10\x05\x18\x052\b[a-zA-X]R\x04name\")\n" +
 	"\rHelloResponse\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage2Y\n" +
-	"\aGreeter\x12N\n" +
-	"\x05Greet\x12\x16.main_api.HelloRequest\x1a\x17.main_api.HelloResponse\"\x14\x82\xd3\xe4\x93\x02\x0e:\x01*\"\t/v1/greetB\x15Z\x13/proto/gen;main_apib\x06proto3"
+	"\amessage\x18\x01 \x01(\tR\amessage\"4\n" +
+	"\x10FibonacciRequest\x12 \n" +
+	"\x05count\x18\x01 \x01(\x05B\n" +
+	"\xfaB\a\x1a\x05\x18\xe8\a \x00R\x05count\"?\n" +
+	"\x11FibonacciResponse\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x04R\x05index\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"'\n" +
+	"\rNumberRequest\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x05R\x06number\"8\n" +
+	"\x0eNumberResponse\x12\x10\n" +
+	"\x03sum\x18\x01 \x01(\x05R\x03sum\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x04R\x05count2\\\n" +
+	"\aGreeter\x12Q\n" +
+	"\x05Greet\x12\x16.main_api.HelloRequest\x1a\x17.main_api.HelloResponse\"\x17\x82\xd3\xe4\x93\x02\x0e:\x01*\"\t/v1/greet\x88\x02\x012\xdb\x01\n" +
+	"\n" +
+	"Calculator\x12m\n" +
+	"\x11GenerateFibonacci\x12\x1a.main_api.FibonacciRequest\x1a\x1b.main_api.FibonacciResponse\"\x1d\x82\xd3\xe4\x93\x02\x17\x12\x15/v1/fibonacci/{count}0\x01\x12^\n" +
+	"\vSendNumbers\x12\x17.main_api.NumberRequest\x1a\x18.main_api.NumberResponse\"\x1a\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/numbers:sum(\x01B\x15Z\x13/proto/gen;main_apib\x06proto3"
 
 var (
 	file_main_proto_rawDescOnce sync.Once
@@ -137,16 +344,24 @@ func file_main_proto_rawDescGZIP() []byte {
 	return file_main_proto_rawDescData
 }
 
-var file_main_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_main_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_main_proto_goTypes = []any{
-	(*HelloRequest)(nil),  // 0: main_api.HelloRequest
-	(*HelloResponse)(nil), // 1: main_api.HelloResponse
+	(*HelloRequest)(nil),      // 0: main_api.HelloRequest
+	(*HelloResponse)(nil),     // 1: main_api.HelloResponse
+	(*FibonacciRequest)(nil),  // 2: main_api.FibonacciRequest
+	(*FibonacciResponse)(nil), // 3: main_api.FibonacciResponse
+	(*NumberRequest)(nil),     // 4: main_api.NumberRequest
+	(*NumberResponse)(nil),    // 5: main_api.NumberResponse
 }
 var file_main_proto_depIdxs = []int32{
 	0, // 0: main_api.Greeter.Greet:input_type -> main_api.HelloRequest
-	1, // 1: main_api.Greeter.Greet:output_type -> main_api.HelloResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
+	2, // 1: main_api.Calculator.GenerateFibonacci:input_type -> main_api.FibonacciRequest
+	4, // 2: main_api.Calculator.SendNumbers:input_type -> main_api.NumberRequest
+	1, // 3: main_api.Greeter.Greet:output_type -> main_api.HelloResponse
+	3, // 4: main_api.Calculator.GenerateFibonacci:output_type -> main_api.FibonacciResponse
+	5, // 5: main_api.Calculator.SendNumbers:output_type -> main_api.NumberResponse
+	3, // [3:6] is the sub-list for method output_type
+	0, // [0:3] is the sub-list for method input_type
 	0, // [0:0] is the sub-list for extension type_name
 	0, // [0:0] is the sub-list for extension extendee
 	0, // [0:0] is the sub-list for field type_name
@@ -163,9 +378,9 @@ func file_main_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_main_proto_rawDesc), len(file_main_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   6,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_main_proto_goTypes,
 		DependencyIndexes: file_main_proto_depIdxs,
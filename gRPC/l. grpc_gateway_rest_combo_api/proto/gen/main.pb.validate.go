@@ -260,3 +260,426 @@ var _ interface {
 	Cause() error
 	ErrorName() string
 } = HelloResponseValidationError{}
+
+// Validate checks the field values on FibonacciRequest with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// first error encountered is returned, or nil if there are no violations.
+func (m *FibonacciRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on FibonacciRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// FibonacciRequestMultiError, or nil if none found.
+func (m *FibonacciRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *FibonacciRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if val := m.GetCount(); val <= 0 || val > 1000 {
+		err := FibonacciRequestValidationError{
+			field:  "Count",
+			reason: "value must be inside range (0, 1000]",
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return FibonacciRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// FibonacciRequestMultiError is an error wrapping multiple validation errors
+// returned by FibonacciRequest.ValidateAll() if the designated constraints
+// aren't met.
+type FibonacciRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m FibonacciRequestMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m FibonacciRequestMultiError) AllErrors() []error { return m }
+
+// FibonacciRequestValidationError is the validation error returned by
+// FibonacciRequest.Validate if the designated constraints aren't met.
+type FibonacciRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e FibonacciRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e FibonacciRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e FibonacciRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e FibonacciRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e FibonacciRequestValidationError) ErrorName() string { return "FibonacciRequestValidationError" }
+
+// Error satisfies the builtin error interface
+func (e FibonacciRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sFibonacciRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = FibonacciRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = FibonacciRequestValidationError{}
+
+// Validate checks the field values on FibonacciResponse with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// first error encountered is returned, or nil if there are no violations.
+func (m *FibonacciResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on FibonacciResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// FibonacciResponseMultiError, or nil if none found.
+func (m *FibonacciResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *FibonacciResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Index
+
+	// no validation rules for Value
+
+	if len(errors) > 0 {
+		return FibonacciResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// FibonacciResponseMultiError is an error wrapping multiple validation errors
+// returned by FibonacciResponse.ValidateAll() if the designated constraints
+// aren't met.
+type FibonacciResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m FibonacciResponseMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m FibonacciResponseMultiError) AllErrors() []error { return m }
+
+// FibonacciResponseValidationError is the validation error returned by
+// FibonacciResponse.Validate if the designated constraints aren't met.
+type FibonacciResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e FibonacciResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e FibonacciResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e FibonacciResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e FibonacciResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e FibonacciResponseValidationError) ErrorName() string {
+	return "FibonacciResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e FibonacciResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sFibonacciResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = FibonacciResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = FibonacciResponseValidationError{}
+
+// Validate checks the field values on NumberRequest with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *NumberRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on NumberRequest with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in NumberRequestMultiError, or
+// nil if none found.
+func (m *NumberRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *NumberRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Number
+
+	if len(errors) > 0 {
+		return NumberRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// NumberRequestMultiError is an error wrapping multiple validation errors
+// returned by NumberRequest.ValidateAll() if the designated constraints
+// aren't met.
+type NumberRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m NumberRequestMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m NumberRequestMultiError) AllErrors() []error { return m }
+
+// NumberRequestValidationError is the validation error returned by
+// NumberRequest.Validate if the designated constraints aren't met.
+type NumberRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e NumberRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e NumberRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e NumberRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e NumberRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e NumberRequestValidationError) ErrorName() string { return "NumberRequestValidationError" }
+
+// Error satisfies the builtin error interface
+func (e NumberRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sNumberRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = NumberRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = NumberRequestValidationError{}
+
+// Validate checks the field values on NumberResponse with the rules defined in
+// the proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *NumberResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on NumberResponse with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// result is a list of violation errors wrapped in NumberResponseMultiError,
+// or nil if none found.
+func (m *NumberResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *NumberResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for Sum
+
+	// no validation rules for Count
+
+	if len(errors) > 0 {
+		return NumberResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// NumberResponseMultiError is an error wrapping multiple validation errors
+// returned by NumberResponse.ValidateAll() if the designated constraints
+// aren't met.
+type NumberResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m NumberResponseMultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m NumberResponseMultiError) AllErrors() []error { return m }
+
+// NumberResponseValidationError is the validation error returned by
+// NumberResponse.Validate if the designated constraints aren't met.
+type NumberResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e NumberResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e NumberResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e NumberResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e NumberResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e NumberResponseValidationError) ErrorName() string { return "NumberResponseValidationError" }
+
+// Error satisfies the builtin error interface
+func (e NumberResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sNumberResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = NumberResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = NumberResponseValidationError{}
@@ -0,0 +1,209 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        v6.32.0
+// source: v2/greeter.proto
+
+package main_apiv2
+
+import (
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+
+	_ "github.com/envoyproxy/protoc-gen-validate/validate"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GreetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FirstName     string                 `protobuf:"bytes,1,opt,name=first_name,json=firstName,proto3" json:"first_name,omitempty"`
+	LastName      string                 `protobuf:"bytes,2,opt,name=last_name,json=lastName,proto3" json:"last_name,omitempty"`
+	Locale        string                 `protobuf:"bytes,3,opt,name=locale,proto3" json:"locale,omitempty"` // en when empty
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GreetRequest) Reset() {
+	*x = GreetRequest{}
+	mi := &file_v2_greeter_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GreetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GreetRequest) ProtoMessage() {}
+
+func (x *GreetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_greeter_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GreetRequest.ProtoReflect.Descriptor instead.
+func (*GreetRequest) Descriptor() ([]byte, []int) {
+	return file_v2_greeter_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GreetRequest) GetFirstName() string {
+	if x != nil {
+		return x.FirstName
+	}
+	return ""
+}
+
+func (x *GreetRequest) GetLastName() string {
+	if x != nil {
+		return x.LastName
+	}
+	return ""
+}
+
+func (x *GreetRequest) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+type GreetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	GreetedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=greeted_at,json=greetedAt,proto3" json:"greeted_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GreetResponse) Reset() {
+	*x = GreetResponse{}
+	mi := &file_v2_greeter_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GreetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GreetResponse) ProtoMessage() {}
+
+func (x *GreetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_greeter_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GreetResponse.ProtoReflect.Descriptor instead.
+func (*GreetResponse) Descriptor() ([]byte, []int) {
+	return file_v2_greeter_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GreetResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *GreetResponse) GetGreetedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.GreetedAt
+	}
+	return nil
+}
+
+var File_v2_greeter_proto protoreflect.FileDescriptor
+
+const file_v2_greeter_proto_rawDesc = "" +
+	"\n" +
+	"\x10v2/greeter.proto\x12\vmain_api.v2\x1a\x17validate/validate.proto\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x87\x01\n" +
+	"\fGreetRequest\x12(\n" +
+	"\n" +
+	"first_name\x18\x01 \x01(\tB\t\xfaB\x06r\x04\x10\x01\x182R\tfirstName\x12$\n" +
+	"\tlast_name\x18\x02 \x01(\tB\a\xfaB\x04r\x02\x182R\blastName\x12'\n" +
+	"\x06locale\x18\x03 \x01(\tB\x0f\xfaB\fr\n" +
+	"R\x00R\x02enR\x02deR\x06locale\"d\n" +
+	"\rGreetResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x129\n" +
+	"\n" +
+	"greeted_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tgreetedAt2_\n" +
+	"\aGreeter\x12T\n" +
+	"\x05Greet\x12\x19.main_api.v2.GreetRequest\x1a\x1a.main_api.v2.GreetResponse\"\x14\x82\xd3\xe4\x93\x02\x0e:\x01*\"\t/v2/greetB\x1aZ\x18/proto/gen/v2;main_apiv2b\x06proto3"
+
+var (
+	file_v2_greeter_proto_rawDescOnce sync.Once
+	file_v2_greeter_proto_rawDescData []byte
+)
+
+func file_v2_greeter_proto_rawDescGZIP() []byte {
+	file_v2_greeter_proto_rawDescOnce.Do(func() {
+		file_v2_greeter_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_v2_greeter_proto_rawDesc), len(file_v2_greeter_proto_rawDesc)))
+	})
+	return file_v2_greeter_proto_rawDescData
+}
+
+var file_v2_greeter_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_v2_greeter_proto_goTypes = []any{
+	(*GreetRequest)(nil),          // 0: main_api.v2.GreetRequest
+	(*GreetResponse)(nil),         // 1: main_api.v2.GreetResponse
+	(*timestamppb.Timestamp)(nil), // 2: google.protobuf.Timestamp
+}
+var file_v2_greeter_proto_depIdxs = []int32{
+	2, // 0: main_api.v2.GreetResponse.greeted_at:type_name -> google.protobuf.Timestamp
+	0, // 1: main_api.v2.Greeter.Greet:input_type -> main_api.v2.GreetRequest
+	1, // 2: main_api.v2.Greeter.Greet:output_type -> main_api.v2.GreetResponse
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_v2_greeter_proto_init() }
+func file_v2_greeter_proto_init() {
+	if File_v2_greeter_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_v2_greeter_proto_rawDesc), len(file_v2_greeter_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_v2_greeter_proto_goTypes,
+		DependencyIndexes: file_v2_greeter_proto_depIdxs,
+		MessageInfos:      file_v2_greeter_proto_msgTypes,
+	}.Build()
+	File_v2_greeter_proto = out.File
+	file_v2_greeter_proto_goTypes = nil
+	file_v2_greeter_proto_depIdxs = nil
+}
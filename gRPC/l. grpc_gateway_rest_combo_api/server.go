@@ -2,118 +2,178 @@ package main
 
 import (
 	"context"
-	"crypto/tls"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	pb "grpc_gateway_project/proto/gen"
+	pbv2 "grpc_gateway_project/proto/gen/v2"
+
+	"pkg/budget"
+	"pkg/health"
+	"pkg/interceptors"
+	"pkg/mtls"
 
-	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"google.golang.org/grpc"
 
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	_ "google.golang.org/grpc/encoding/gzip" // compression in server
-	"google.golang.org/grpc/reflection"
-	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 )
 
 type server struct {
 	pb.UnimplementedGreeterServer
 }
 
+// Greet only sees requests that passed their validate rules, the interceptor suite rejects the rest
 func (s *server) Greet(ctx context.Context, req *pb.HelloRequest) (*pb.HelloResponse, error) {
+	return &pb.HelloResponse{Message: fmt.Sprintf("Hello user %s.", req.Name)}, nil
+}
 
-	if err := req.Validate(); err != nil {
-		log.Printf("validation failed: %v", err)
-
-		return nil, status.Errorf(codes.InvalidArgument, "Invalid request: %v", err)
-	}
+// newGRPCServer builds the gRPC server, creds only apply to the in-memory listener, the single port does its own TLS
+func newGRPCServer(creds credentials.TransportCredentials, metrics *interceptors.Metrics, healthServer *health.Server, reflectionMode health.ReflectionMode) *grpc.Server {
+	serverOptions := interceptors.ServerOptions(interceptors.Config{
+		Logger:       slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+		Metrics:      metrics,
+		UnaryTimeout: 10 * time.Second,
+		Validate:     true,
+	})
+	// Deprecations go first, so calls the suite rejects (validation, deadlines...) still announce them
+	serverOptions = append([]grpc.ServerOption{grpc.ChainUnaryInterceptor(unaryDeprecation(deprecations))}, serverOptions...)
+	serverOptions = append(serverOptions, grpc.ChainUnaryInterceptor(budget.Config{Logger: gatewayBudget.Logger}.UnaryServerInterceptor()))
+	grpcServer := grpc.NewServer(append(serverOptions, grpc.Creds(creds))...)
 
-	return &pb.HelloResponse{Message: fmt.Sprintf("Hello user %s.", req.Name)}, nil
+	pb.RegisterGreeterServer(grpcServer, &server{})
+	pbv2.RegisterGreeterServer(grpcServer, &greeterV2Server{})
+	pb.RegisterCalculatorServer(grpcServer, &calculatorServer{})
+	healthServer.Register(grpcServer)
+	health.RegisterReflection(grpcServer, reflectionMode)
+	return grpcServer
 }
 
-func runGRPCServer(certFile, keyFile string) {
+// gatewayBudget keeps 50ms of a Grpc-Timeout request header for the gateway's own work, calls left with less than 10ms fail right away
+var gatewayBudget = budget.Config{
+	Reserve: 50 * time.Millisecond,
+	MinCall: 10 * time.Millisecond,
+	Logger:  slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+}
 
-	creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+/*
+dialInMemory connects the gateway to grpcServer without a socket.
+- grpcServer additionally serves an in-memory listener, so gateway calls still run through the interceptors and see the gateway's certificate as peer
+- The connection is closed by stopping grpcServer
+*/
+func dialInMemory(grpcServer *grpc.Server, creds credentials.TransportCredentials) *grpc.ClientConn {
+	lis := bufconn.Listen(1 << 20)
+	go func() {
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Println("In-memory gRPC listener:", err)
+		}
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///in-memory",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(gatewayBudget.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(gatewayBudget.StreamClientInterceptor()),
+	)
 	if err != nil {
-		log.Fatalln("failed load TLS Certificates:", err)
+		log.Fatalln("Failed to connect the gateway:", err)
 	}
+	return conn
+}
 
-	serveAddress := "127.0.0.1:50051"
-
-	list, err := net.Listen("tcp", serveAddress)
-	if err != nil {
-		log.Fatal("Failed to listen.", err)
+// newGatewayHandler serves the REST routes over conn with the HTTP mirror of grpc.health.v1 and the API docs next to them
+func newGatewayHandler(ctx context.Context, conn *grpc.ClientConn, healthServer *health.Server) http.Handler {
+	mux := newServeMux()
+	if err := pb.RegisterGreeterHandler(ctx, mux, conn); err != nil {
+		log.Fatalln("Failed to register gRPC-Gateway handler:", err)
+	}
+	if err := pb.RegisterCalculatorHandler(ctx, mux, conn); err != nil {
+		log.Fatalln("Failed to register gRPC-Gateway handler:", err)
+	}
+	if err := pbv2.RegisterGreeterHandler(ctx, mux, conn); err != nil {
+		log.Fatalln("Failed to register gRPC-Gateway handler:", err)
 	}
 
-	grpcServer := grpc.NewServer(grpc.Creds(creds))
+	httpMux := http.NewServeMux()
+	httpMux.Handle("GET /healthz", healthServer.LivenessHandler())
+	httpMux.Handle("GET /readyz", healthServer.ReadinessHandler())
+	registerDocs(httpMux)
+	httpMux.Handle("/", mux)
+	return httpMux
+}
 
-	pb.RegisterGreeterServer(grpcServer, &server{})
+func main() {
+	// /debug/vars lists the command line and memory stats, it gets its own loopback listener instead of the public port
+	debugAddress := flag.String("debug-addr", "localhost:6063", "serve expvar metrics at /debug/vars, empty disables it")
+	flag.Parse()
 
-	reflection.Register(grpcServer)
+	// Generated with cmd/gencerts in pkg/, see commands.txt. gRPC clients need a client certificate issued by ca.pem,
+	// browsers and curl don't. The gateway presents its own certificate on its in-memory connection.
+	cert := "certs/server.pem"
+	key := "certs/server-key.pem"
+	ca := "certs/ca.pem"
 
-	log.Println("gRPC Server is running at", serveAddress)
-	err = grpcServer.Serve(list)
+	tlsConfig, err := mtls.ServerConfig(cert, key, ca)
 	if err != nil {
-		log.Fatal("Failed to server.", err)
+		log.Fatalln("failed load TLS Certificates:", err)
 	}
-
-}
-
-func loadTLSCredentials(certFile, keyFile string) tls.Certificate {
-	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	gatewayTLS, err := mtls.ClientConfig("certs/gateway.pem", "certs/gateway-key.pem", ca, "localhost")
 	if err != nil {
-		log.Fatalln("Failed to load certificated:", err)
+		log.Fatalln("Failed to load gateway client certificate:", err)
 	}
-	return cert
-}
 
-func runGatewayServer(certFile, keyFile string) {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	mux := runtime.NewServeMux()
-	opts := []grpc.DialOption{
-		// For HTTP
-		// grpc.WithTransportCredentials(insecure.NewCredentials()),
-		// For HTTPS
-		grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
-			InsecureSkipVerify: true,
-		})),
-	}
-	err := pb.RegisterGreeterHandlerFromEndpoint(ctx, mux, "localhost:50051", opts)
-	if err != nil {
-		log.Fatalln("Failed to register gRPC-Gateway handler:", err)
+	// Reflection lets grpcurl list the services, there is no auth here so only "public" (development) can enable it
+	reflectionMode, err := health.ParseReflectionMode(os.Getenv("GRPC_REFLECTION"))
+	if err != nil || reflectionMode == health.ReflectionAuthenticated {
+		log.Fatalln("GRPC_REFLECTION must be off or public.")
 	}
 
-	tlsConfig := &tls.Config{
-		Certificates: []tls.Certificate{loadTLSCredentials(certFile, keyFile)},
+	metrics := interceptors.NewMetrics()
+	metrics.Publish("grpc_server")
+	if *debugAddress != "" {
+		go func() {
+			log.Println("Debug server stopped:", interceptors.ServeDebug(*debugAddress))
+		}()
 	}
 
-	server := &http.Server{
-		Addr:      ":8080",
-		Handler:   mux,
-		TLSConfig: tlsConfig,
-	}
+	healthServer := health.NewServer(pb.Greeter_ServiceDesc.ServiceName, pb.Calculator_ServiceDesc.ServiceName, pbv2.Greeter_ServiceDesc.ServiceName)
+	healthServer.AddCheck("certificate", health.CertificateCheck(cert, key, 0))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go healthServer.Run(ctx, 10*time.Second)
 
-	log.Printf("HTTPS Server is running on port: 8080")
-	// Without tls
-	// err = http.ListenAndServe(":8080", mux)
-	err = server.ListenAndServeTLS(certFile, keyFile)
+	grpcServer := newGRPCServer(credentials.NewTLS(tlsConfig), metrics, healthServer, reflectionMode)
+	conn := dialInMemory(grpcServer, credentials.NewTLS(gatewayTLS))
+	handler := newGatewayHandler(context.Background(), conn, healthServer)
+
+	serveAddress := ":8080"
+	list, err := net.Listen("tcp", serveAddress)
 	if err != nil {
-		log.Fatalln("Failed to start REST API server:", err)
+		log.Fatal("Failed to listen.", err)
 	}
+	port := newSinglePort(list, tlsConfig, grpcServer, handler)
 
-}
-
-func main() {
+	go func() {
+		log.Println("gRPC and HTTPS Server is running at", serveAddress)
+		if err := port.Serve(); err != nil {
+			log.Fatalln("Failed to serve:", err)
+		}
+	}()
 
-	cert := "cert.pem"
-	key := "key.pem"
+	<-ctx.Done()
+	log.Println("Shutting down, draining in-flight calls")
 
-	go runGRPCServer(cert, key)
-	runGatewayServer(cert, key)
+	healthServer.Drain()
+	time.Sleep(2 * time.Second) // load balancers polling /readyz or Watch see NOT_SERVING before connections close
+	port.Shutdown(15 * time.Second)
 }
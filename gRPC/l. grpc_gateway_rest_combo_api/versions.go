@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"net/http"
+	"time"
+
+	pb "grpc_gateway_project/proto/gen"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+/*
+deprecation announces that a method has a successor in a later API version.
+- Every API version is its own proto package with its own REST prefix, main_api is v1 under /v1, main_api.v2 is v2 under /v2
+- A version only gains fields and methods, anything breaking goes into the next package
+- A method with a successor keeps serving unchanged until its sunset and announces it on every call
+*/
+type deprecation struct {
+	since     time.Time // Deprecation header (RFC 9745)
+	sunset    time.Time // Sunset header (RFC 8594), the method is removed after it
+	successor string    // REST route of the replacement, sent as a successor-version Link
+}
+
+// deprecations maps full gRPC method names to their deprecation
+var deprecations = map[string]deprecation{
+	pb.Greeter_Greet_FullMethodName: {
+		since:     time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC),
+		sunset:    time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC),
+		successor: "/v2/greet",
+	},
+}
+
+// deprecatedCalls counts the calls per deprecated method, /debug/vars shows who still has to migrate before the sunset
+var deprecatedCalls = expvar.NewMap("deprecated_calls")
+
+// header is the response metadata announcing d, the gateway forwards it as HTTP headers
+func (d deprecation) header() metadata.MD {
+	return metadata.Pairs(
+		"deprecation", fmt.Sprintf("@%d", d.since.Unix()),
+		"sunset", d.sunset.Format(http.TimeFormat),
+		"link", fmt.Sprintf("<%s>; rel=\"successor-version\"", d.successor),
+	)
+}
+
+// unaryDeprecation adds the deprecation headers to the responses of deprecated methods, errors included
+func unaryDeprecation(deprecations map[string]deprecation) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if d, ok := deprecations[info.FullMethod]; ok {
+			deprecatedCalls.Add(info.FullMethod, 1)
+			if err := grpc.SetHeader(ctx, d.header()); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	pb "grpc_gateway_project/proto/gen"
+	pbv2 "grpc_gateway_project/proto/gen/v2"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// v1Contract is main_api.Greeter as v1 clients were built against it, a change here breaks them
+var v1Contract = map[protoreflect.FullName]map[protoreflect.Name]protoreflect.FieldNumber{
+	"main_api.HelloRequest":  {"name": 1},
+	"main_api.HelloResponse": {"message": 1},
+}
+
+// TestV1ClientsKeepWorking holds v1 to what its clients rely on while v2 evolves next to it
+func TestV1ClientsKeepWorking(t *testing.T) {
+	for name, fields := range v1Contract {
+		var msg protoreflect.MessageDescriptor
+		switch name {
+		case "main_api.HelloRequest":
+			msg = (&pb.HelloRequest{}).ProtoReflect().Descriptor()
+		case "main_api.HelloResponse":
+			msg = (&pb.HelloResponse{}).ProtoReflect().Descriptor()
+		}
+		for field, number := range fields {
+			f := msg.Fields().ByName(field)
+			if f == nil || f.Number() != number || f.Kind() != protoreflect.StringKind {
+				t.Errorf("%s.%s changed, v1 clients send and read string field %d", name, field, number)
+			}
+		}
+	}
+	if method := pb.Greeter_Greet_FullMethodName; method != "/main_api.Greeter/Greet" {
+		t.Errorf("v1 Greet moved to %s", method)
+	}
+
+	// Bytes an old v1 client puts on the wire still decode
+	wire, _ := hex.DecodeString("0a05416c696365")
+	var req pb.HelloRequest
+	if err := proto.Unmarshal(wire, &req); err != nil || req.GetName() != "Alice" {
+		t.Errorf("v1 wire request = %v, %v", &req, err)
+	}
+
+	env := startSinglePort(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var header metadata.MD
+	res, err := pb.NewGreeterClient(env.dialGRPC(t, env.client.TLS)).Greet(ctx, &req, grpc.Header(&header))
+	if err != nil || res.GetMessage() != "Hello user Alice." {
+		t.Fatalf("gRPC v1 Greet = %v, %v", res, err)
+	}
+	if len(header.Get("sunset")) != 1 {
+		t.Errorf("gRPC v1 header = %v; want the deprecation announced", header)
+	}
+
+	httpRes := env.do(t, http.MethodPost, "/v1/greet", `{"name":"Alice"}`, nil)
+	body, _ := io.ReadAll(httpRes.Body)
+	if httpRes.StatusCode != http.StatusOK || string(body) != `{"message":"Hello user Alice."}` {
+		t.Errorf("REST v1 greet = %d %s", httpRes.StatusCode, body)
+	}
+	checkDeprecated(t, httpRes.Header, deprecations[pb.Greeter_Greet_FullMethodName])
+
+	// Failed calls announce it too
+	httpRes = env.do(t, http.MethodPost, "/v1/greet", `{"name":"12"}`, nil)
+	if httpRes.StatusCode != http.StatusBadRequest {
+		t.Errorf("REST v1 invalid greet = %d; want 400", httpRes.StatusCode)
+	}
+	checkDeprecated(t, httpRes.Header, deprecations[pb.Greeter_Greet_FullMethodName])
+}
+
+func checkDeprecated(t *testing.T, header http.Header, d deprecation) {
+	t.Helper()
+	if got := header.Get("Deprecation"); got != "@1790812800" {
+		t.Errorf("Deprecation = %q; want @1790812800", got)
+	}
+	if got := header.Get("Sunset"); got != "Thu, 01 Apr 2027 00:00:00 GMT" {
+		t.Errorf("Sunset = %q", got)
+	}
+	if got, want := header.Get("Link"), "<"+d.successor+`>; rel="successor-version"`; got != want {
+		t.Errorf("Link = %q; want %q", got, want)
+	}
+}
+
+func TestV2Greet(t *testing.T) {
+	env := startSinglePort(t)
+
+	res := env.do(t, http.MethodPost, "/v2/greet", `{"firstName":"Alice","lastName":"Smith","locale":"de"}`, nil)
+	var greeting struct {
+		Message   string    `json:"message"`
+		GreetedAt time.Time `json:"greetedAt"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&greeting); err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK || greeting.Message != "Hallo Alice Smith." || greeting.GreetedAt.IsZero() {
+		t.Errorf("REST v2 greet = %d %+v", res.StatusCode, greeting)
+	}
+	if res.Header.Get("Deprecation") != "" || res.Header.Get("Sunset") != "" {
+		t.Errorf("v2 is not deprecated, got Deprecation %q Sunset %q", res.Header.Get("Deprecation"), res.Header.Get("Sunset"))
+	}
+
+	res = env.do(t, http.MethodPost, "/v2/greet", `{"firstName":"Alice","locale":"fr"}`, nil)
+	if p := decodeProblem(t, res); p.Status != http.StatusBadRequest || len(p.Violations) != 1 || p.Violations[0].Field != "Locale" {
+		t.Errorf("unsupported locale = %+v; want a Locale violation", p)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	v2, err := pbv2.NewGreeterClient(env.dialGRPC(t, env.client.TLS)).Greet(ctx, &pbv2.GreetRequest{FirstName: "Alice"})
+	if err != nil || v2.GetMessage() != "Hello Alice." {
+		t.Errorf("gRPC v2 Greet = %v, %v", v2, err)
+	}
+}
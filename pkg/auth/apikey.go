@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// APIKey is a stored key. Only the SHA-256 hash of the secret is ever kept, as "sha256:<hex>".
+type APIKey struct {
+	ID      string   `json:"id"`
+	Hash    string   `json:"hash"`
+	Subject string   `json:"subject"`
+	Roles   []string `json:"roles"`
+}
+
+type APIKeyStore struct {
+	keys []APIKey
+}
+
+// HashAPIKey returns the value to store in the "hash" field for a plaintext key.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func NewAPIKeyStore(keys ...APIKey) (*APIKeyStore, error) {
+	for _, k := range keys {
+		if !strings.HasPrefix(k.Hash, "sha256:") {
+			return nil, fmt.Errorf("auth: api key %q: hash must start with sha256:", k.ID)
+		}
+		if k.Subject == "" {
+			return nil, fmt.Errorf("auth: api key %q has no subject", k.ID)
+		}
+	}
+	return &APIKeyStore{keys: keys}, nil
+}
+
+// LoadAPIKeys reads a JSON array of APIKey from path.
+func LoadAPIKeys(path string) (*APIKeyStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("auth: %s: %w", path, err)
+	}
+	return NewAPIKeyStore(keys...)
+}
+
+// Authenticate compares the hash against every stored key so the time taken doesn't reveal which one matched.
+func (s *APIKeyStore) Authenticate(key string) (*Principal, error) {
+	hash := []byte(HashAPIKey(key))
+
+	var match *APIKey
+	for i := range s.keys {
+		if subtle.ConstantTimeCompare(hash, []byte(s.keys[i].Hash)) == 1 {
+			match = &s.keys[i]
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("%w: unknown api key", ErrUnauthenticated)
+	}
+	return &Principal{Subject: match.Subject, Roles: match.Roles, Method: "api_key", KeyID: match.ID}, nil
+}
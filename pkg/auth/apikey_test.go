@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAPIKeyStore(t *testing.T) {
+	store, err := NewAPIKeyStore(
+		APIKey{ID: "admin", Hash: HashAPIKey("admin-secret"), Subject: "admin", Roles: []string{"admin"}},
+		APIKey{ID: "client", Hash: HashAPIKey("client-secret"), Subject: "calculator-client", Roles: []string{"client"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := store.Authenticate("client-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Subject != "calculator-client" || p.KeyID != "client" || p.Method != "api_key" || !p.HasRole("client") {
+		t.Errorf("principal = %+v", p)
+	}
+
+	for _, key := range []string{"", "client-secre", "CLIENT-SECRET", HashAPIKey("client-secret")} {
+		if _, err := store.Authenticate(key); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("Authenticate(%q) = %v; want ErrUnauthenticated", key, err)
+		}
+	}
+}
+
+func TestHashAPIKey(t *testing.T) {
+	// sha256 of the plaintext, hex encoded
+	const want = "sha256:df76ff796f70d2c9cb055ea6280553caa27eda26b70e01082c160de75a05a4a9"
+	if got := HashAPIKey("dev-admin-key"); got != want {
+		t.Errorf("HashAPIKey = %s; want %s", got, want)
+	}
+}
+
+func TestNewAPIKeyStoreRejectsBadEntries(t *testing.T) {
+	if _, err := NewAPIKeyStore(APIKey{ID: "plain", Hash: "admin-secret", Subject: "admin"}); err == nil {
+		t.Error("a key stored without the sha256: prefix was accepted")
+	}
+	if _, err := NewAPIKeyStore(APIKey{ID: "anon", Hash: HashAPIKey("x")}); err == nil {
+		t.Error("a key without subject was accepted")
+	}
+}
+
+func TestLoadAPIKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apikeys.json")
+	data := `[{"id": "dev", "hash": "` + HashAPIKey("dev-key") + `", "subject": "dev", "roles": ["admin"]}]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := LoadAPIKeys(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err := store.Authenticate("dev-key"); err != nil || !p.HasRole("admin") {
+		t.Errorf("Authenticate = %+v, %v", p, err)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadAPIKeys(path); err == nil {
+		t.Error("a broken file was accepted")
+	}
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Authenticator accepts either a bearer JWT or an API key, whichever of the two is configured.
+type Authenticator struct {
+	JWT     *Verifier
+	APIKeys *APIKeyStore
+}
+
+/*
+Authenticate resolves the caller from the raw credential values of a request.
+- authorization : "Bearer <jwt>" or "ApiKey <key>"
+- apiKey        : value of the X-API-Key header / x-api-key metadata
+"Bearer=<jwt>" is accepted too, older clients in this repo still send it that way.
+*/
+func (a *Authenticator) Authenticate(authorization, apiKey string) (*Principal, error) {
+	if apiKey != "" {
+		return a.authenticateAPIKey(apiKey)
+	}
+	if authorization == "" {
+		return nil, fmt.Errorf("%w: missing credentials", ErrUnauthenticated)
+	}
+
+	scheme, credential, ok := strings.Cut(authorization, " ")
+	if !ok {
+		scheme, credential, ok = strings.Cut(authorization, "=")
+	}
+	credential = strings.TrimSpace(credential)
+	if !ok || credential == "" {
+		return nil, fmt.Errorf("%w: malformed authorization value", ErrUnauthenticated)
+	}
+
+	switch strings.ToLower(scheme) {
+	case "bearer":
+		if a.JWT == nil {
+			return nil, fmt.Errorf("%w: bearer tokens are not accepted", ErrUnauthenticated)
+		}
+		return a.JWT.Verify(credential)
+	case "apikey":
+		return a.authenticateAPIKey(credential)
+	default:
+		return nil, fmt.Errorf("%w: unsupported authorization scheme %q", ErrUnauthenticated, scheme)
+	}
+}
+
+func (a *Authenticator) authenticateAPIKey(key string) (*Principal, error) {
+	if a.APIKeys == nil {
+		return nil, fmt.Errorf("%w: api keys are not accepted", ErrUnauthenticated)
+	}
+	return a.APIKeys.Authenticate(key)
+}
+
+// RequireAnyRole returns ErrPermissionDenied unless p holds at least one of roles. No roles means any caller.
+func RequireAnyRole(p *Principal, roles ...string) error {
+	if len(roles) == 0 {
+		return nil
+	}
+	for _, role := range roles {
+		if p.HasRole(role) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: requires one of the roles %v", ErrPermissionDenied, roles)
+}
@@ -0,0 +1,13 @@
+package auth
+
+import "errors"
+
+/*
+Every failure wraps one of these two so transports can map them:
+- ErrUnauthenticated -> 401 / codes.Unauthenticated (no or bad credentials)
+- ErrPermissionDenied -> 403 / codes.PermissionDenied (valid credentials, not allowed)
+*/
+var (
+	ErrUnauthenticated  = errors.New("unauthenticated")
+	ErrPermissionDenied = errors.New("permission denied")
+)
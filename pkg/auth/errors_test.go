@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func testAuthenticator(t *testing.T) *Authenticator {
+	t.Helper()
+	store, err := NewAPIKeyStore(
+		APIKey{ID: "admin", Hash: HashAPIKey("admin-secret"), Subject: "admin", Roles: []string{"admin"}},
+		APIKey{ID: "customer", Hash: HashAPIKey("customer-secret"), Subject: "u1", Roles: []string{"customer"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Authenticator{JWT: testVerifier(Key{ID: "hs", Alg: "HS256", Material: testSecret}), APIKeys: store}
+}
+
+func TestWriteHTTPError(t *testing.T) {
+	tests := []struct {
+		err       error
+		code      int
+		challenge bool
+	}{
+		{fmt.Errorf("%w: expired", ErrUnauthenticated), http.StatusUnauthorized, true},
+		{fmt.Errorf("%w: needs admin", ErrPermissionDenied), http.StatusForbidden, false},
+		{errors.New("anything else"), http.StatusUnauthorized, true},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		WriteHTTPError(rec, tt.err)
+		if rec.Code != tt.code {
+			t.Errorf("%v: status = %d; want %d", tt.err, rec.Code, tt.code)
+		}
+		if got := rec.Header().Get("WWW-Authenticate") != ""; got != tt.challenge {
+			t.Errorf("%v: WWW-Authenticate set = %v; want %v", tt.err, got, tt.challenge)
+		}
+		if body := rec.Body.String(); body != http.StatusText(tt.code)+"\n" {
+			t.Errorf("%v: body = %q; the reason must not be echoed", tt.err, body)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	token := sign(t, jwt.SigningMethodHS256, "hs", testSecret, validClaims())
+	handler := Middleware(testAuthenticator(t))(RequireRoles("admin", "customer")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := FromContext(r.Context())
+		fmt.Fprint(w, p.Subject)
+	})))
+
+	tests := []struct {
+		name   string
+		header http.Header
+		code   int
+	}{
+		{"bearer", http.Header{"Authorization": {"Bearer " + token}}, http.StatusOK},
+		{"legacy bearer", http.Header{"Authorization": {"Bearer=" + token}}, http.StatusOK},
+		{"x-api-key", http.Header{"X-Api-Key": {"admin-secret"}}, http.StatusOK},
+		{"apikey scheme", http.Header{"Authorization": {"ApiKey customer-secret"}}, http.StatusOK},
+		{"missing", http.Header{}, http.StatusUnauthorized},
+		{"unknown scheme", http.Header{"Authorization": {"Basic dTE6cHc="}}, http.StatusUnauthorized},
+		{"bad key", http.Header{"X-Api-Key": {"guess"}}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header = tt.header
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		if rec.Code != tt.code {
+			t.Errorf("%s: status = %d; want %d", tt.name, rec.Code, tt.code)
+		}
+	}
+
+	adminOnly := Middleware(testAuthenticator(t))(RequireRoles("admin")(http.NotFoundHandler()))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-API-Key", "customer-secret")
+	rec := httptest.NewRecorder()
+	adminOnly.ServeHTTP(rec, r)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("customer on an admin route: status = %d; want 403", rec.Code)
+	}
+}
+
+func TestGRPCError(t *testing.T) {
+	if got := status.Code(GRPCError(fmt.Errorf("%w: expired", ErrUnauthenticated))); got != codes.Unauthenticated {
+		t.Errorf("unauthenticated maps to %v", got)
+	}
+	if got := status.Code(GRPCError(fmt.Errorf("%w: needs admin", ErrPermissionDenied))); got != codes.PermissionDenied {
+		t.Errorf("permission denied maps to %v", got)
+	}
+	if msg := status.Convert(GRPCError(fmt.Errorf("%w: key abc revoked", ErrUnauthenticated))).Message(); msg != "unauthenticated" {
+		t.Errorf("message = %q; the reason must not be sent back", msg)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor(testAuthenticator(t),
+		WithPublicMethods("/svc/Public"),
+		WithMethodRoles(map[string][]string{"/svc/Admin": {"admin"}}),
+	)
+	handler := func(ctx context.Context, req any) (any, error) {
+		p, ok := FromContext(ctx)
+		if !ok {
+			return "anonymous", nil
+		}
+		return p.Subject, nil
+	}
+	call := func(method string, md metadata.MD) (any, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+		return interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	}
+
+	if got, err := call("/svc/Public", nil); err != nil || got != "anonymous" {
+		t.Errorf("public method = %v, %v; want it served without credentials", got, err)
+	}
+	if _, err := call("/svc/Any", nil); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("no credentials = %v; want Unauthenticated", err)
+	}
+	if got, err := call("/svc/Any", metadata.Pairs("x-api-key", "customer-secret")); err != nil || got != "u1" {
+		t.Errorf("api key = %v, %v; want the principal in the context", got, err)
+	}
+	if _, err := call("/svc/Admin", metadata.Pairs("x-api-key", "customer-secret")); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("customer on an admin method = %v; want PermissionDenied", err)
+	}
+	if got, err := call("/svc/Admin", metadata.Pairs("x-api-key", "admin-secret")); err != nil || got != "admin" {
+		t.Errorf("admin = %v, %v", got, err)
+	}
+}
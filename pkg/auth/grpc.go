@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type grpcOptions struct {
+	publicMethods []string
+	methodRoles   map[string][]string
+}
+
+type GRPCOption func(*grpcOptions)
+
+// WithPublicMethods skips authentication for the given full method names, e.g. "/grpc.health.v1.Health/Check".
+func WithPublicMethods(methods ...string) GRPCOption {
+	return func(o *grpcOptions) {
+		o.publicMethods = append(o.publicMethods, methods...)
+	}
+}
+
+// WithMethodRoles requires callers of a full method name to hold one of the listed roles.
+func WithMethodRoles(roles map[string][]string) GRPCOption {
+	return func(o *grpcOptions) {
+		o.methodRoles = roles
+	}
+}
+
+func UnaryServerInterceptor(a *Authenticator, opts ...GRPCOption) grpc.UnaryServerInterceptor {
+	o := newGRPCOptions(opts)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := o.authenticate(ctx, a, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func StreamServerInterceptor(a *Authenticator, opts ...GRPCOption) grpc.StreamServerInterceptor {
+	o := newGRPCOptions(opts)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := o.authenticate(ss.Context(), a, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &principalStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func newGRPCOptions(opts []GRPCOption) *grpcOptions {
+	o := &grpcOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func (o *grpcOptions) authenticate(ctx context.Context, a *Authenticator, method string) (context.Context, error) {
+	if slices.Contains(o.publicMethods, method) {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	p, err := a.Authenticate(first(md, "authorization"), first(md, "x-api-key"))
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+	if err := RequireAnyRole(p, o.methodRoles[method]...); err != nil {
+		return nil, GRPCError(err)
+	}
+	return NewContext(ctx, p), nil
+}
+
+// GRPCError maps auth errors to Unauthenticated/PermissionDenied, the reason is logged but not sent back.
+func GRPCError(err error) error {
+	log.Println("auth:", err)
+	if errors.Is(err, ErrPermissionDenied) {
+		return status.Error(codes.PermissionDenied, "permission denied")
+	}
+	return status.Error(codes.Unauthenticated, "unauthenticated")
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// principalStream swaps the stream context so handlers see the Principal.
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *principalStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+)
+
+/*
+Middleware authenticates every request and stores the Principal in the request context.
+Failures answer 401 with a WWW-Authenticate challenge and never reach next.
+*/
+func Middleware(a *Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, err := a.Authenticate(r.Header.Get("Authorization"), r.Header.Get("X-API-Key"))
+			if err != nil {
+				WriteHTTPError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), p)))
+		})
+	}
+}
+
+// RequireRoles answers 403 unless the authenticated caller holds one of roles. Use it behind Middleware.
+func RequireRoles(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := FromContext(r.Context())
+			if !ok {
+				WriteHTTPError(w, ErrUnauthenticated)
+				return
+			}
+			if err := RequireAnyRole(p, roles...); err != nil {
+				WriteHTTPError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WriteHTTPError maps auth errors to 401/403, the reason is logged but not echoed to the client.
+func WriteHTTPError(w http.ResponseWriter, err error) {
+	log.Println("auth:", err)
+	switch {
+	case errors.Is(err, ErrPermissionDenied):
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	default:
+		w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims are the JWT claims this repo understands on top of the registered ones.
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+}
+
+/*
+Verifier checks JWTs against a KeySet.
+- The signature must match the key selected by kid and alg (HS256, RS256 or EdDSA only, never "none")
+- exp is required, nbf is honoured when present, both with Leeway for clock skew
+- aud must contain Audience and iss must equal Issuer when those are set
+*/
+type Verifier struct {
+	Keys     *KeySet
+	Audience string
+	Issuer   string
+	Leeway   time.Duration
+}
+
+func (v *Verifier) Verify(token string) (*Principal, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "RS256", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(v.Leeway),
+	}
+	if v.Audience != "" {
+		opts = append(opts, jwt.WithAudience(v.Audience))
+	}
+	if v.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.Issuer))
+	}
+
+	var kid string
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ = t.Header["kid"].(string)
+		key, err := v.Keys.Lookup(kid, t.Method.Alg())
+		if err != nil {
+			return nil, err
+		}
+		kid = key.ID
+		return key.Material, nil
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid token: %v", ErrUnauthenticated, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrUnauthenticated)
+	}
+	return &Principal{Subject: claims.Subject, Roles: claims.Roles, Method: "jwt", KeyID: kid}, nil
+}
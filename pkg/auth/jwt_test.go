@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, claims Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("signing: %v", err)
+	}
+	return signed
+}
+
+// validClaims expire in an hour and carry the audience and issuer of testVerifier
+func validClaims() Claims {
+	now := time.Now()
+	return Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "u1",
+			Audience:  jwt.ClaimStrings{"restapi"},
+			Issuer:    "dev",
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+		Roles: []string{"customer"},
+	}
+}
+
+func testVerifier(keys ...Key) *Verifier {
+	return &Verifier{Keys: NewKeySet(keys...), Audience: "restapi", Issuer: "dev", Leeway: 30 * time.Second}
+}
+
+func TestVerifyAcceptsEveryAlg(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := testVerifier(
+		Key{ID: "hs", Alg: "HS256", Material: testSecret},
+		Key{ID: "rs", Alg: "RS256", Material: &rsaKey.PublicKey},
+		Key{ID: "ed", Alg: "EdDSA", Material: edPub},
+	)
+
+	tokens := map[string]string{
+		"hs": sign(t, jwt.SigningMethodHS256, "hs", testSecret, validClaims()),
+		"rs": sign(t, jwt.SigningMethodRS256, "rs", rsaKey, validClaims()),
+		"ed": sign(t, jwt.SigningMethodEdDSA, "ed", edKey, validClaims()),
+	}
+	for kid, token := range tokens {
+		p, err := v.Verify(token)
+		if err != nil {
+			t.Errorf("%s: %v", kid, err)
+			continue
+		}
+		if p.Subject != "u1" || !p.HasRole("customer") || p.Method != "jwt" || p.KeyID != kid {
+			t.Errorf("%s: principal = %+v", kid, p)
+		}
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)})
+	v := testVerifier(
+		Key{ID: "hs", Alg: "HS256", Material: testSecret},
+		Key{ID: "rs", Alg: "RS256", Material: &rsaKey.PublicKey},
+	)
+
+	with := func(change func(*Claims)) Claims {
+		c := validClaims()
+		change(&c)
+		return c
+	}
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"expired", sign(t, jwt.SigningMethodHS256, "hs", testSecret, with(func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(past) }))},
+		{"no exp", sign(t, jwt.SigningMethodHS256, "hs", testSecret, with(func(c *Claims) { c.ExpiresAt = nil }))},
+		{"not yet valid", sign(t, jwt.SigningMethodHS256, "hs", testSecret, with(func(c *Claims) { c.NotBefore = jwt.NewNumericDate(future) }))},
+		{"wrong audience", sign(t, jwt.SigningMethodHS256, "hs", testSecret, with(func(c *Claims) { c.Audience = jwt.ClaimStrings{"calculator"} }))},
+		{"no audience", sign(t, jwt.SigningMethodHS256, "hs", testSecret, with(func(c *Claims) { c.Audience = nil }))},
+		{"wrong issuer", sign(t, jwt.SigningMethodHS256, "hs", testSecret, with(func(c *Claims) { c.Issuer = "someone" }))},
+		{"no subject", sign(t, jwt.SigningMethodHS256, "hs", testSecret, with(func(c *Claims) { c.Subject = "" }))},
+		{"bad signature", sign(t, jwt.SigningMethodHS256, "hs", []byte("another-secret-another-secret-xx"), validClaims())},
+		{"unknown kid", sign(t, jwt.SigningMethodHS256, "nope", testSecret, validClaims())},
+		{"alg none", sign(t, jwt.SigningMethodNone, "hs", jwt.UnsafeAllowNoneSignatureType, validClaims())},
+		// the classic confusion: HMAC signed with the RSA public key as the secret, sent with the RSA kid
+		{"hs256 with rsa kid", sign(t, jwt.SigningMethodHS256, "rs", rsaPEM, validClaims())},
+		{"rs256 with hmac kid", sign(t, jwt.SigningMethodRS256, "hs", rsaKey, validClaims())},
+		{"not a jwt", "not.a.jwt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := v.Verify(tt.token)
+			if !errors.Is(err, ErrUnauthenticated) {
+				t.Fatalf("Verify = %+v, %v; want ErrUnauthenticated", p, err)
+			}
+		})
+	}
+}
+
+func TestVerifyLeeway(t *testing.T) {
+	v := testVerifier(Key{ID: "hs", Alg: "HS256", Material: testSecret})
+
+	c := validClaims()
+	c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-10 * time.Second))
+	c.NotBefore = jwt.NewNumericDate(time.Now().Add(10 * time.Second))
+	if _, err := v.Verify(sign(t, jwt.SigningMethodHS256, "hs", testSecret, c)); err != nil {
+		t.Errorf("10s of skew within a 30s leeway: %v", err)
+	}
+}
+
+func TestLookupWithoutKid(t *testing.T) {
+	single := NewKeySet(Key{ID: "hs", Alg: "HS256", Material: testSecret})
+	if key, err := single.Lookup("", "HS256"); err != nil || key.ID != "hs" {
+		t.Errorf("Lookup = %+v, %v; want the only HS256 key", key, err)
+	}
+	if _, err := single.Lookup("", "RS256"); err == nil {
+		t.Error("Lookup found a key for an alg that has none")
+	}
+
+	two := NewKeySet(Key{ID: "a", Alg: "HS256", Material: testSecret}, Key{ID: "b", Alg: "HS256", Material: testSecret})
+	if _, err := two.Lookup("", "HS256"); err == nil {
+		t.Error("Lookup picked a key although two match")
+	}
+	if _, err := testVerifier(two.keys["a"], two.keys["b"]).Verify(sign(t, jwt.SigningMethodHS256, "", testSecret, validClaims())); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Verify of a kid-less token with ambiguous keys = %v; want ErrUnauthenticated", err)
+	}
+}
+
+func TestLoadKeySet(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(edKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	write := func(name string, data []byte) {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("dev.hmac", append(testSecret, '\n'))
+	write("ed.pem", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	write("README", []byte("ignored"))
+
+	ks, err := LoadKeySet(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key, err := ks.Lookup("dev", "HS256"); err != nil || string(key.Material.([]byte)) != string(testSecret) {
+		t.Errorf("dev = %+v, %v; want the trimmed HS256 secret", key, err)
+	}
+	if _, err := ks.Lookup("ed", "EdDSA"); err != nil {
+		t.Errorf("ed: %v", err)
+	}
+
+	write("short.hmac", []byte("too-short"))
+	if _, err := LoadKeySet(dir); err == nil || !strings.Contains(err.Error(), "shorter than 32 bytes") {
+		t.Errorf("err = %v; want the short HMAC key rejected", err)
+	}
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Key is one verification key. Alg is the only JWT alg it accepts: HS256, RS256 or EdDSA.
+type Key struct {
+	ID       string
+	Alg      string
+	Material any // []byte for HS256, *rsa.PublicKey for RS256, ed25519.PublicKey for EdDSA
+}
+
+// KeySet holds the keys JWTs may be signed with, looked up by the kid header.
+type KeySet struct {
+	keys map[string]Key
+}
+
+func NewKeySet(keys ...Key) *KeySet {
+	ks := &KeySet{keys: make(map[string]Key)}
+	for _, k := range keys {
+		ks.keys[k.ID] = k
+	}
+	return ks
+}
+
+/*
+LoadKeySet reads every key file in dir, the file name without extension becomes the kid.
+- *.hmac : shared secret for HS256 (surrounding whitespace is trimmed)
+- *.pem  : PUBLIC KEY, RSA PUBLIC KEY or CERTIFICATE block, RS256 or EdDSA depending on the key type
+Anything else in the directory is ignored.
+*/
+func LoadKeySet(dir string) (*KeySet, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ks := NewKeySet()
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		kid := strings.TrimSuffix(entry.Name(), ext)
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		switch ext {
+		case ".hmac":
+			secret := bytes.TrimSpace(data)
+			if len(secret) < 32 {
+				return nil, fmt.Errorf("auth: HMAC key %s is shorter than 32 bytes", entry.Name())
+			}
+			ks.keys[kid] = Key{ID: kid, Alg: "HS256", Material: secret}
+		case ".pem":
+			key, err := parsePublicKey(data)
+			if err != nil {
+				return nil, fmt.Errorf("auth: %s: %w", entry.Name(), err)
+			}
+			key.ID = kid
+			ks.keys[kid] = key
+		}
+	}
+
+	if len(ks.keys) == 0 {
+		return nil, fmt.Errorf("auth: no keys found in %s", dir)
+	}
+	return ks, nil
+}
+
+/*
+Lookup returns the key for a token.
+Tokens without a kid are only accepted when exactly one key of the token's alg exists.
+*/
+func (ks *KeySet) Lookup(kid, alg string) (Key, error) {
+	if kid != "" {
+		key, ok := ks.keys[kid]
+		if !ok {
+			return Key{}, fmt.Errorf("unknown key id %q", kid)
+		}
+		if key.Alg != alg {
+			return Key{}, fmt.Errorf("key %q does not accept alg %s", kid, alg)
+		}
+		return key, nil
+	}
+
+	var found []Key
+	for _, key := range ks.keys {
+		if key.Alg == alg {
+			found = append(found, key)
+		}
+	}
+	if len(found) != 1 {
+		return Key{}, errors.New("token has no kid and the key is ambiguous")
+	}
+	return found[0], nil
+}
+
+func parsePublicKey(data []byte) (Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, errors.New("no PEM block found")
+	}
+
+	var pub any
+	var err error
+	switch block.Type {
+	case "PUBLIC KEY":
+		pub, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		pub, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		cert, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			pub = cert.PublicKey
+		}
+	default:
+		return Key{}, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return Key{}, err
+	}
+
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return Key{Alg: "RS256", Material: k}, nil
+	case ed25519.PublicKey:
+		return Key{Alg: "EdDSA", Material: k}, nil
+	default:
+		return Key{}, fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
@@ -0,0 +1,264 @@
+package balancer
+
+import (
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	_ "google.golang.org/grpc/health" // client-side health checking when the service config has a healthCheckConfig
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// RoundRobin cycles through the ready backends, skipping ejected ones
+	RoundRobin = "outlier_round_robin"
+	// LeastOutstanding picks the ready backend with the fewest calls in flight, ties broken at random
+	LeastOutstanding = "least_outstanding"
+)
+
+/*
+OutlierConfig ejects backends that keep failing.
+- ConsecutiveFailures server-side failures (Unavailable, Internal, Unknown, DataLoss) in a row eject a backend
+- It stays out for BaseEjectionTime times the number of times it was ejected, capped at MaxEjectionTime
+- MaxEjectionPercent of the ready backends may be ejected at most, with everything ejected the picker uses them all anyway
+*/
+type OutlierConfig struct {
+	ConsecutiveFailures int
+	BaseEjectionTime    time.Duration
+	MaxEjectionTime     time.Duration
+	MaxEjectionPercent  int
+}
+
+var DefaultOutlierConfig = OutlierConfig{
+	ConsecutiveFailures: 5,
+	BaseEjectionTime:    30 * time.Second,
+	MaxEjectionTime:     5 * time.Minute,
+	MaxEjectionPercent:  50,
+}
+
+func init() {
+	Register(RoundRobin, DefaultOutlierConfig)
+	Register(LeastOutstanding, DefaultOutlierConfig)
+}
+
+// Register registers a balancer named RoundRobin or LeastOutstanding with its own outlier settings, call it from init.
+func Register(name string, outlier OutlierConfig) {
+	balancer.Register(&builder{name: name, outlier: outlier})
+}
+
+// ServiceConfig selects a balancer by name, pass it to grpc.WithDefaultServiceConfig.
+func ServiceConfig(name string) string {
+	return `{"loadBalancingConfig": [{"` + name + `": {}}]}`
+}
+
+/*
+builder gives every ClientConn its own picker state, base would share one PickerBuilder between all of them.
+Backends only count as ready once their grpc.health.v1 status is SERVING when the service config names a
+healthCheckConfig service, so a draining server (see pkg/health) stops getting calls before it goes away.
+*/
+type builder struct {
+	name    string
+	outlier OutlierConfig
+}
+
+func (b *builder) Name() string {
+	return b.name
+}
+
+func (b *builder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
+	pb := &pickerBuilder{
+		leastOutstanding: b.name == LeastOutstanding,
+		outlier:          b.outlier,
+		endpoints:        make(map[string]*endpoint),
+		now:              time.Now,
+	}
+	return base.NewBalancerBuilder(b.name, pb, base.Config{HealthCheck: true}).Build(cc, opts)
+}
+
+/*
+endpoint is the per-backend state kept across picker rebuilds.
+It is keyed by address, not SubConn: a backend that drops out of READY and reconnects, or comes back
+on a new SubConn, is still ejected. Backends that are gone are forgotten once no ejection is left to serve.
+*/
+type endpoint struct {
+	addr  string
+	ready bool // guarded by pickerBuilder.mu
+
+	outstanding atomic.Int64
+
+	mu           sync.Mutex
+	failures     int
+	ejections    int
+	ejectedUntil time.Time
+}
+
+type pickerBuilder struct {
+	leastOutstanding bool
+	outlier          OutlierConfig
+	now              func() time.Time
+
+	mu        sync.Mutex
+	endpoints map[string]*endpoint // by address
+}
+
+// readyEndpoint is an endpoint with the SubConn the picker it was built for sends to
+type readyEndpoint struct {
+	*endpoint
+	subConn balancer.SubConn
+}
+
+func (pb *pickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	for _, ep := range pb.endpoints {
+		ep.ready = false
+	}
+	ready := make([]readyEndpoint, 0, len(info.ReadySCs))
+	for sc, sci := range info.ReadySCs {
+		ep, ok := pb.endpoints[sci.Address.Addr]
+		if !ok {
+			ep = &endpoint{addr: sci.Address.Addr}
+			pb.endpoints[ep.addr] = ep
+		}
+		ep.ready = true
+		ready = append(ready, readyEndpoint{endpoint: ep, subConn: sc})
+	}
+	now := pb.now()
+	for addr, ep := range pb.endpoints {
+		if !ep.ready && !ep.ejected(now) {
+			delete(pb.endpoints, addr)
+		}
+	}
+
+	return &picker{builder: pb, endpoints: ready, next: rand.Uint32()}
+}
+
+type picker struct {
+	builder   *pickerBuilder
+	endpoints []readyEndpoint
+	next      uint32 // round-robin position, atomic
+}
+
+func (p *picker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	candidates := p.available()
+
+	var ep readyEndpoint
+	if p.builder.leastOutstanding {
+		ep = leastOutstanding(candidates)
+	} else {
+		n := atomic.AddUint32(&p.next, 1)
+		ep = candidates[int(n)%len(candidates)]
+	}
+
+	ep.outstanding.Add(1)
+	return balancer.PickResult{
+		SubConn: ep.subConn,
+		Done: func(info balancer.DoneInfo) {
+			ep.outstanding.Add(-1)
+			p.builder.record(ep.endpoint, info.Err)
+		},
+	}, nil
+}
+
+// available leaves out ejected endpoints, unless that would leave nothing
+func (p *picker) available() []readyEndpoint {
+	now := p.builder.now()
+	out := make([]readyEndpoint, 0, len(p.endpoints))
+	for _, ep := range p.endpoints {
+		if !ep.ejected(now) {
+			out = append(out, ep)
+		}
+	}
+	if len(out) == 0 {
+		return p.endpoints
+	}
+	return out
+}
+
+func leastOutstanding(candidates []readyEndpoint) readyEndpoint {
+	var best readyEndpoint
+	var bestLoad int64
+	ties := 0
+	for _, ep := range candidates {
+		load := ep.outstanding.Load()
+		switch {
+		case best.endpoint == nil || load < bestLoad:
+			best, bestLoad, ties = ep, load, 1
+		case load == bestLoad:
+			// Reservoir sampling keeps each tied endpoint equally likely
+			ties++
+			if rand.IntN(ties) == 0 {
+				best = ep
+			}
+		}
+	}
+	return best
+}
+
+func (ep *endpoint) ejected(now time.Time) bool {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	return now.Before(ep.ejectedUntil)
+}
+
+func (pb *pickerBuilder) record(ep *endpoint, err error) {
+	cfg := pb.outlier
+	if cfg.ConsecutiveFailures <= 0 {
+		return
+	}
+
+	ep.mu.Lock()
+	if !isServerFailure(err) {
+		ep.failures = 0
+		ep.mu.Unlock()
+		return
+	}
+	ep.failures++
+	eject := ep.failures >= cfg.ConsecutiveFailures && !pb.now().Before(ep.ejectedUntil)
+	ep.mu.Unlock()
+
+	if eject && pb.mayEject() {
+		ep.mu.Lock()
+		ep.ejections++
+		ejection := min(cfg.BaseEjectionTime*time.Duration(ep.ejections), cfg.MaxEjectionTime)
+		ep.ejectedUntil = pb.now().Add(ejection)
+		ep.failures = 0
+		ep.mu.Unlock()
+	}
+}
+
+// mayEject keeps the number of ejected ready endpoints within MaxEjectionPercent
+func (pb *pickerBuilder) mayEject() bool {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	now := pb.now()
+	ready, ejected := 0, 0
+	for _, ep := range pb.endpoints {
+		if !ep.ready {
+			continue
+		}
+		ready++
+		if ep.ejected(now) {
+			ejected++
+		}
+	}
+	return (ejected+1)*100 <= pb.outlier.MaxEjectionPercent*ready
+}
+
+func isServerFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Internal, codes.Unknown, codes.DataLoss:
+		return true
+	}
+	return false
+}
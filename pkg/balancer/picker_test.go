@@ -0,0 +1,261 @@
+package balancer
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
+)
+
+type fakeSubConn struct {
+	balancer.SubConn
+	addr string
+}
+
+// fakeClock is the pickerBuilder's now, moved forward by hand
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+var testOutlier = OutlierConfig{
+	ConsecutiveFailures: 2,
+	BaseEjectionTime:    10 * time.Second,
+	MaxEjectionTime:     25 * time.Second,
+	MaxEjectionPercent:  50,
+}
+
+func newTestPicker(t *testing.T, leastOutstanding bool, outlier OutlierConfig, addrs ...string) (*pickerBuilder, balancer.Picker, *fakeClock, map[string]*fakeSubConn) {
+	t.Helper()
+	clock := &fakeClock{t: time.Now()}
+	pb := &pickerBuilder{leastOutstanding: leastOutstanding, outlier: outlier, endpoints: make(map[string]*endpoint), now: clock.now}
+	subConns := make(map[string]*fakeSubConn)
+	for _, addr := range addrs {
+		subConns[addr] = &fakeSubConn{addr: addr}
+	}
+	return pb, pb.Build(buildInfo(subConns)), clock, subConns
+}
+
+func buildInfo(subConns map[string]*fakeSubConn) base.PickerBuildInfo {
+	info := base.PickerBuildInfo{ReadySCs: make(map[balancer.SubConn]base.SubConnInfo)}
+	for addr, sc := range subConns {
+		info.ReadySCs[sc] = base.SubConnInfo{Address: resolver.Address{Addr: addr}}
+	}
+	return info
+}
+
+// pick picks once and ends the call with err
+func pick(t *testing.T, p balancer.Picker, err error) string {
+	t.Helper()
+	res, pickErr := p.Pick(balancer.PickInfo{})
+	if pickErr != nil {
+		t.Fatal(pickErr)
+	}
+	res.Done(balancer.DoneInfo{Err: err})
+	return res.SubConn.(*fakeSubConn).addr
+}
+
+// picked counts the backends of n successful picks
+func picked(t *testing.T, p balancer.Picker, n int) map[string]int {
+	t.Helper()
+	counts := make(map[string]int)
+	for range n {
+		counts[pick(t, p, nil)]++
+	}
+	return counts
+}
+
+// failOn picks until addr was picked and failed n times in a row
+func failOn(t *testing.T, p balancer.Picker, addr string, n int) {
+	t.Helper()
+	unavailable := status.Error(codes.Unavailable, "down")
+	for failed, tries := 0, 0; failed < n; tries++ {
+		if tries > 1000 {
+			t.Fatalf("%s was never picked", addr)
+		}
+		res, err := p.Pick(balancer.PickInfo{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.SubConn.(*fakeSubConn).addr == addr {
+			res.Done(balancer.DoneInfo{Err: unavailable})
+			failed++
+		} else {
+			res.Done(balancer.DoneInfo{})
+		}
+	}
+}
+
+func TestRoundRobinUsesEveryBackend(t *testing.T) {
+	_, p, _, _ := newTestPicker(t, false, testOutlier, "a", "b", "c")
+	counts := picked(t, p, 30)
+	for _, addr := range []string{"a", "b", "c"} {
+		if counts[addr] != 10 {
+			t.Errorf("picks = %v; want 10 each", counts)
+		}
+	}
+}
+
+func TestNoReadyBackends(t *testing.T) {
+	pb, _, _, _ := newTestPicker(t, false, testOutlier)
+	if _, err := pb.Build(base.PickerBuildInfo{}).Pick(balancer.PickInfo{}); err != balancer.ErrNoSubConnAvailable {
+		t.Errorf("err = %v; want ErrNoSubConnAvailable", err)
+	}
+}
+
+func TestEjectionGrowsAndExpires(t *testing.T) {
+	_, p, clock, _ := newTestPicker(t, false, testOutlier, "a", "b", "c", "d")
+
+	failOn(t, p, "a", 2)
+	if counts := picked(t, p, 30); counts["a"] != 0 {
+		t.Fatalf("picks = %v; want a ejected", counts)
+	}
+
+	// first ejection lasts BaseEjectionTime
+	clock.advance(9 * time.Second)
+	if counts := picked(t, p, 30); counts["a"] != 0 {
+		t.Fatalf("picks = %v after 9s; want a still ejected", counts)
+	}
+	clock.advance(2 * time.Second)
+	if counts := picked(t, p, 40); counts["a"] == 0 {
+		t.Fatalf("picks = %v after 11s; want a back", counts)
+	}
+
+	// second ejection lasts twice as long
+	failOn(t, p, "a", 2)
+	clock.advance(19 * time.Second)
+	if counts := picked(t, p, 30); counts["a"] != 0 {
+		t.Fatalf("picks = %v 19s into the second ejection; want a still ejected", counts)
+	}
+	clock.advance(2 * time.Second)
+	if counts := picked(t, p, 40); counts["a"] == 0 {
+		t.Fatalf("picks = %v 21s into the second ejection; want a back", counts)
+	}
+
+	// the third would be 30s, MaxEjectionTime caps it at 25s
+	failOn(t, p, "a", 2)
+	clock.advance(26 * time.Second)
+	if counts := picked(t, p, 40); counts["a"] == 0 {
+		t.Errorf("picks = %v 26s into the third ejection; want MaxEjectionTime to have ended it", counts)
+	}
+}
+
+func TestClientErrorsDoNotEject(t *testing.T) {
+	_, p, _, _ := newTestPicker(t, false, testOutlier, "a", "b")
+
+	// a client error between two server failures resets the streak
+	failOn(t, p, "a", 1)
+	for pick(t, p, status.Error(codes.InvalidArgument, "bad request")) != "a" {
+	}
+	failOn(t, p, "a", 1)
+	if counts := picked(t, p, 20); counts["a"] == 0 {
+		t.Errorf("picks = %v; want a not ejected without %d failures in a row", counts, testOutlier.ConsecutiveFailures)
+	}
+}
+
+func TestMaxEjectionPercent(t *testing.T) {
+	_, p, _, _ := newTestPicker(t, false, testOutlier, "a", "b", "c", "d")
+
+	// 50% of 4 backends: two may be ejected, the third failing one stays in
+	for _, addr := range []string{"a", "b", "c"} {
+		failOn(t, p, addr, 2)
+	}
+	counts := picked(t, p, 60)
+	if counts["a"] != 0 || counts["b"] != 0 || counts["c"] == 0 || counts["d"] == 0 {
+		t.Errorf("picks = %v; want a and b ejected, c and d serving", counts)
+	}
+}
+
+func TestEverythingEjectedUsesEveryBackend(t *testing.T) {
+	outlier := testOutlier
+	outlier.MaxEjectionPercent = 100
+	_, p, _, _ := newTestPicker(t, false, outlier, "a", "b")
+
+	failOn(t, p, "a", 2)
+	failOn(t, p, "b", 2)
+	if counts := picked(t, p, 20); counts["a"] == 0 || counts["b"] == 0 {
+		t.Errorf("picks = %v; want both used rather than failing every call", counts)
+	}
+}
+
+func TestEjectionSurvivesRebuild(t *testing.T) {
+	pb, p, _, subConns := newTestPicker(t, false, testOutlier, "a", "b", "c")
+	failOn(t, p, "a", 2)
+
+	// c goes away, a new picker must still know a is ejected
+	delete(subConns, "c")
+	p = pb.Build(buildInfo(subConns))
+	if counts := picked(t, p, 20); counts["a"] != 0 || counts["b"] != 20 {
+		t.Errorf("picks = %v; want only b", counts)
+	}
+	if len(pb.endpoints) != 2 {
+		t.Errorf("%d endpoints tracked; want the one that went away dropped", len(pb.endpoints))
+	}
+}
+
+func TestEjectionSurvivesReconnect(t *testing.T) {
+	pb, p, clock, subConns := newTestPicker(t, false, testOutlier, "a", "b", "c")
+	failOn(t, p, "a", 2)
+
+	// a leaves READY, then comes back on a new SubConn while its ejection still runs
+	delete(subConns, "a")
+	pb.Build(buildInfo(subConns))
+	subConns["a"] = &fakeSubConn{addr: "a"}
+	p = pb.Build(buildInfo(subConns))
+	if counts := picked(t, p, 30); counts["a"] != 0 {
+		t.Fatalf("picks = %v; want a still ejected after reconnecting", counts)
+	}
+
+	clock.advance(11 * time.Second)
+	if counts := picked(t, p, 30); counts["a"] == 0 {
+		t.Errorf("picks = %v after the ejection ended; want a back", counts)
+	}
+
+	// Once its ejection is over a backend that goes away is forgotten
+	delete(subConns, "a")
+	pb.Build(buildInfo(subConns))
+	if _, ok := pb.endpoints["a"]; ok {
+		t.Error("a is still tracked after it went away with no ejection left")
+	}
+}
+
+func TestLeastOutstanding(t *testing.T) {
+	_, p, _, _ := newTestPicker(t, true, testOutlier, "a", "b", "c")
+
+	// hold one call on each of two backends, the third must get the next pick
+	held := make(map[string]balancer.PickResult)
+	for len(held) < 2 {
+		res, err := p.Pick(balancer.PickInfo{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr := res.SubConn.(*fakeSubConn).addr
+		if _, ok := held[addr]; ok {
+			t.Fatalf("%s picked while it had the most calls in flight", addr)
+		}
+		held[addr] = res
+	}
+
+	var idle string
+	for _, addr := range []string{"a", "b", "c"} {
+		if _, ok := held[addr]; !ok {
+			idle = addr
+		}
+	}
+	for range 5 {
+		if got := pick(t, p, nil); got != idle {
+			t.Fatalf("picked %s; want the idle %s", got, idle)
+		}
+	}
+
+	for _, res := range held {
+		res.Done(balancer.DoneInfo{})
+	}
+	if counts := picked(t, p, 60); len(counts) != 3 {
+		t.Errorf("picks = %v; want ties spread over every backend once the calls finished", counts)
+	}
+}
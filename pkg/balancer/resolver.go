@@ -0,0 +1,175 @@
+package balancer
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+/*
+Scheme is the target scheme of the backend list resolver, the same forms as gRPC's unix scheme
+- "backends:relative/file" or "backends:///absolute/file" for a file
+- "backends:env:VAR" for an environment variable
+*/
+const Scheme = "backends"
+
+/*
+ResolverBuilder resolves a target into the backends listed in a file or an environment variable.
+- Addresses are separated by newlines, commas or spaces, lines starting with # are comments
+- A file is polled every PollInterval and changes are pushed to the connection, a broken or empty file keeps the last list
+- An environment variable is read once, the process environment can't change from outside
+Pass it to a single connection with grpc.WithResolvers, nothing is registered globally.
+*/
+type ResolverBuilder struct {
+	PollInterval time.Duration
+}
+
+func NewResolverBuilder(pollInterval time.Duration) *ResolverBuilder {
+	return &ResolverBuilder{PollInterval: pollInterval}
+}
+
+func (b *ResolverBuilder) Scheme() string {
+	return Scheme
+}
+
+func (b *ResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	source := target.URL.Opaque
+	if source == "" {
+		source = target.URL.Path
+	}
+	r := &backendResolver{cc: cc, done: make(chan struct{})}
+
+	if name, ok := strings.CutPrefix(strings.TrimPrefix(source, "/"), "env:"); ok {
+		addrs := parseAddresses([]byte(os.Getenv(name)))
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("balancer: environment variable %s lists no backends", name)
+		}
+		return r, r.update(addrs)
+	}
+
+	r.path = source
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	if b.PollInterval > 0 {
+		r.wg.Add(1)
+		go r.watch(b.PollInterval)
+	}
+	return r, nil
+}
+
+type backendResolver struct {
+	cc   resolver.ClientConn
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	current []string
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func (r *backendResolver) load() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return fmt.Errorf("balancer: backend list: %w", err)
+	}
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return fmt.Errorf("balancer: backend list: %w", err)
+	}
+	addrs := parseAddresses(data)
+	if len(addrs) == 0 {
+		return fmt.Errorf("balancer: backend list %s is empty", r.path)
+	}
+
+	r.mu.Lock()
+	r.modTime = info.ModTime()
+	r.mu.Unlock()
+	return r.update(addrs)
+}
+
+func (r *backendResolver) update(addrs []string) error {
+	r.mu.Lock()
+	if slices.Equal(r.current, addrs) {
+		r.mu.Unlock()
+		return nil
+	}
+	r.current = addrs
+	r.mu.Unlock()
+
+	state := resolver.State{}
+	for _, addr := range addrs {
+		state.Addresses = append(state.Addresses, resolver.Address{Addr: addr})
+	}
+	return r.cc.UpdateState(state)
+}
+
+func (r *backendResolver) watch(interval time.Duration) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			info, err := os.Stat(r.path)
+			if err != nil {
+				log.Println("balancer: stat backend list:", err)
+				continue
+			}
+
+			r.mu.Lock()
+			changed := !info.ModTime().Equal(r.modTime)
+			r.mu.Unlock()
+			if !changed {
+				continue
+			}
+
+			if err := r.load(); err != nil {
+				log.Println("balancer: reload failed, keeping previous backends:", err)
+				continue
+			}
+			log.Println("balancer: backends reloaded from", r.path)
+		}
+	}
+}
+
+// ResolveNow re-reads the file right away, gRPC calls it when connections fail.
+func (r *backendResolver) ResolveNow(resolver.ResolveNowOptions) {
+	if r.path == "" {
+		return
+	}
+	if err := r.load(); err != nil {
+		log.Println("balancer: reload failed, keeping previous backends:", err)
+	}
+}
+
+func (r *backendResolver) Close() {
+	close(r.done)
+	r.wg.Wait()
+}
+
+func parseAddresses(data []byte) []string {
+	var addrs []string
+	for line := range bytes.Lines(data) {
+		if bytes.HasPrefix(bytes.TrimSpace(line), []byte("#")) {
+			continue
+		}
+		for _, field := range strings.FieldsFunc(string(line), func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r' }) {
+			addrs = append(addrs, field)
+		}
+	}
+	return addrs
+}
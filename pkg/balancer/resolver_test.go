@@ -0,0 +1,140 @@
+package balancer
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+func TestParseAddresses(t *testing.T) {
+	tests := []struct {
+		data string
+		want []string
+	}{
+		{"", nil},
+		{"localhost:50001", []string{"localhost:50001"}},
+		{"a:1,b:2 c:3\td:4", []string{"a:1", "b:2", "c:3", "d:4"}},
+		{"a:1\r\nb:2\n\n", []string{"a:1", "b:2"}},
+		{"# backends\na:1, b:2\n  # b:3 is down\nc:3", []string{"a:1", "b:2", "c:3"}},
+		{"a:1 # not a comment mid-line", []string{"a:1", "#", "not", "a", "comment", "mid-line"}},
+		{"# only comments\n", nil},
+		{" , ,\n", nil},
+	}
+	for _, tt := range tests {
+		if got := parseAddresses([]byte(tt.data)); !slices.Equal(got, tt.want) {
+			t.Errorf("parseAddresses(%q) = %q; want %q", tt.data, got, tt.want)
+		}
+	}
+}
+
+// fakeClientConn records the address lists the resolver pushes
+type fakeClientConn struct {
+	resolver.ClientConn
+
+	mu      sync.Mutex
+	updates [][]string
+}
+
+func (cc *fakeClientConn) UpdateState(state resolver.State) error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	var addrs []string
+	for _, a := range state.Addresses {
+		addrs = append(addrs, a.Addr)
+	}
+	cc.updates = append(cc.updates, addrs)
+	return nil
+}
+
+func (cc *fakeClientConn) last() ([]string, int) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if len(cc.updates) == 0 {
+		return nil, 0
+	}
+	return cc.updates[len(cc.updates)-1], len(cc.updates)
+}
+
+func build(t *testing.T, target string, pollInterval time.Duration) (*fakeClientConn, error) {
+	t.Helper()
+	u, err := url.Parse(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := &fakeClientConn{}
+	r, err := NewResolverBuilder(pollInterval).Build(resolver.Target{URL: *u}, cc, resolver.BuildOptions{})
+	if err == nil {
+		t.Cleanup(r.Close)
+	}
+	return cc, err
+}
+
+func TestResolverFromEnv(t *testing.T) {
+	t.Setenv("TEST_BACKENDS", "a:1, b:2")
+	cc, err := build(t, "backends:env:TEST_BACKENDS", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := cc.last(); !slices.Equal(got, []string{"a:1", "b:2"}) {
+		t.Errorf("addresses = %v", got)
+	}
+
+	t.Setenv("TEST_BACKENDS", "  ")
+	if _, err := build(t, "backends:env:TEST_BACKENDS", 0); err == nil {
+		t.Error("an empty variable was accepted")
+	}
+}
+
+func TestResolverKeepsLastListOnBadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backends.txt")
+	modTime := time.Now()
+	write := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		// bump the modification time so the change is seen on filesystems with coarse timestamps
+		modTime = modTime.Add(time.Second)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := build(t, "backends://"+path, 0); err == nil {
+		t.Fatal("a missing file was accepted")
+	}
+
+	write("a:1\nb:2\n")
+	cc, err := build(t, "backends://"+path, 5*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := cc.last(); !slices.Equal(got, []string{"a:1", "b:2"}) {
+		t.Fatalf("addresses = %v", got)
+	}
+
+	write("# everything commented out\n")
+	time.Sleep(50 * time.Millisecond)
+	if got, n := cc.last(); n != 1 || !slices.Equal(got, []string{"a:1", "b:2"}) {
+		t.Fatalf("after an empty file: %d updates, last %v; want the first list kept", n, got)
+	}
+
+	write("b:2\nc:3\n")
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if got, _ := cc.last(); slices.Equal(got, []string{"b:2", "c:3"}) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the fixed file was never picked up")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,122 @@
+package budget
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+/*
+Config is the deadline budget of one hop, the deadline of a context is the time the whole chain of calls has left.
+- grpc sends the deadline along as grpc-timeout and the gateway turns a Grpc-Timeout request header into one, so each hop sees what is left
+- Reserve is kept back from every outgoing call, the time this hop needs after the call returned, so downstream hops run out first
+- A call that would get less than MinCall fails with DeadlineExceeded without being sent
+- Logger gets one line per call with the budget it started with and consumed, nil logs nothing
+*/
+type Config struct {
+	Reserve time.Duration
+	MinCall time.Duration
+	Logger  *slog.Logger
+}
+
+// Remaining is the time left before the deadline of ctx, false without a deadline
+func Remaining(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
+
+/*
+Call derives the context of the next call from ctx, the context of a chain of sequential calls.
+- The call gets what is left minus Reserve, at most limit if limit isn't 0
+- Without a deadline on ctx the call gets limit, or no deadline either if limit is 0
+- Fails with DeadlineExceeded if that is less than MinCall, the call wouldn't finish anyway
+*/
+func (c Config) Call(ctx context.Context, limit time.Duration) (context.Context, context.CancelFunc, error) {
+	remaining, ok := Remaining(ctx)
+	if !ok {
+		if limit == 0 {
+			return ctx, func() {}, nil
+		}
+		remaining = limit
+	} else {
+		remaining -= c.Reserve
+		if limit > 0 && limit < remaining {
+			remaining = limit
+		}
+	}
+	if remaining < c.MinCall || remaining <= 0 {
+		return ctx, func() {}, status.Errorf(codes.DeadlineExceeded, "budget: %v left after the %v reserve, a call needs %v", remaining.Round(time.Millisecond), c.Reserve, c.MinCall)
+	}
+	ctx, cancel := context.WithTimeout(ctx, remaining)
+	return ctx, cancel, nil
+}
+
+// UnaryClientInterceptor applies Call to every call, install it first so retries share the call's budget
+func (c Config) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, cancel, err := c.Call(ctx, 0)
+		if err != nil {
+			c.log(ctx, "budget exhausted", method, time.Now(), err)
+			return err
+		}
+		defer cancel()
+		start := time.Now()
+		err = invoker(ctx, method, req, reply, cc, opts...)
+		c.log(ctx, "budget consumed", method, start, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor applies Call to every stream, it only logs the budget left when the stream was opened
+func (c Config) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		callCtx, cancel, err := c.Call(ctx, 0)
+		if err != nil {
+			c.log(ctx, "budget exhausted", method, time.Now(), err)
+			return nil, err
+		}
+		c.log(callCtx, "budget opened", method, time.Now(), nil)
+		stream, err := streamer(callCtx, desc, cc, method, opts...)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		// The stream ends with its context, cancel only releases the timer once it did
+		context.AfterFunc(stream.Context(), cancel)
+		return stream, nil
+	}
+}
+
+// UnaryServerInterceptor logs the budget each call arrived with and how much of it this hop consumed
+func (c Config) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		c.log(ctx, "budget served", info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// log writes the budget of ctx at start, the time consumed since and what is left
+func (c Config) log(ctx context.Context, msg, method string, start time.Time, err error) {
+	if c.Logger == nil {
+		return
+	}
+	attrs := []slog.Attr{slog.String("method", method), slog.String("code", status.Code(err).String())}
+	if remaining, ok := Remaining(ctx); ok {
+		consumed := time.Since(start)
+		attrs = append(attrs,
+			slog.Duration("budget", remaining+consumed),
+			slog.Duration("consumed", consumed),
+			slog.Duration("left", remaining),
+		)
+	}
+	c.Logger.LogAttrs(ctx, slog.LevelInfo, msg, attrs...)
+}
@@ -0,0 +1,118 @@
+package budget
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"pkg/grpctest"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// hop is a health server that calls next, if any, and records the budget it was called with
+type hop struct {
+	healthpb.UnimplementedHealthServer
+	next   healthpb.HealthClient
+	calls  atomic.Int32
+	budget atomic.Int64
+}
+
+func (h *hop) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	h.calls.Add(1)
+	remaining, _ := Remaining(ctx)
+	h.budget.Store(int64(remaining))
+	if h.next != nil {
+		return h.next.Check(ctx, req)
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+// startChain serves two hops, the first one calling the second, and returns a client of the first, every hop calls out with cfg
+func startChain(t *testing.T, cfg Config) (healthpb.HealthClient, *hop, *hop) {
+	t.Helper()
+
+	start := func(h *hop) healthpb.HealthClient {
+		srv := grpctest.Start(t, func(s *grpc.Server) { healthpb.RegisterHealthServer(s, h) },
+			grpctest.WithServerOptions(grpc.UnaryInterceptor(cfg.UnaryServerInterceptor())),
+			grpctest.WithDialOptions(grpc.WithUnaryInterceptor(cfg.UnaryClientInterceptor())),
+		)
+		return grpctest.NewClient(srv, healthpb.NewHealthClient)
+	}
+	downstream := &hop{}
+	first := &hop{next: start(downstream)}
+	return start(first), first, downstream
+}
+
+func TestBudgetShrinksByReservePerHop(t *testing.T) {
+	var logs bytes.Buffer
+	client, first, downstream := startChain(t, Config{Reserve: 100 * time.Millisecond, Logger: slog.New(slog.NewJSONHandler(&logs, nil))})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := time.Duration(first.budget.Load()); got > 900*time.Millisecond || got < 800*time.Millisecond {
+		t.Errorf("first hop got %v; want 1s minus one reserve", got)
+	}
+	if got := time.Duration(downstream.budget.Load()); got > 800*time.Millisecond || got < 700*time.Millisecond {
+		t.Errorf("downstream hop got %v; want 1s minus two reserves", got)
+	}
+	// Each hop logs the call it served and the one it made
+	if n := strings.Count(logs.String(), `"msg":"budget served"`); n != 2 {
+		t.Errorf("logged %d served calls; want 2:\n%s", n, logs.String())
+	}
+	if n := strings.Count(logs.String(), `"msg":"budget consumed"`); n != 2 || !strings.Contains(logs.String(), `"left":`) {
+		t.Errorf("logged %d consumed budgets; want 2 with what was left:\n%s", n, logs.String())
+	}
+}
+
+func TestBudgetFailsFast(t *testing.T) {
+	client, first, downstream := startChain(t, Config{Reserve: 100 * time.Millisecond, MinCall: 150 * time.Millisecond})
+
+	// Too little for the first call already
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	if status.Code(err) != codes.DeadlineExceeded || first.calls.Load() != 0 {
+		t.Fatalf("err = %v after %d calls; want DeadlineExceeded without calling", err, first.calls.Load())
+	}
+
+	// Enough for the first hop, not for the one after it
+	ctx, cancel = context.WithTimeout(context.Background(), 350*time.Millisecond)
+	defer cancel()
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	if status.Code(err) != codes.DeadlineExceeded || !strings.Contains(err.Error(), "budget:") || first.calls.Load() != 1 || downstream.calls.Load() != 0 {
+		t.Errorf("err = %v, calls %d and %d; want the first hop to fail fast", err, first.calls.Load(), downstream.calls.Load())
+	}
+}
+
+func TestCall(t *testing.T) {
+	cfg := Config{MinCall: 10 * time.Millisecond}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	callCtx, callCancel, err := cfg.Call(ctx, 300*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer callCancel()
+	if remaining, _ := Remaining(callCtx); remaining > 300*time.Millisecond || remaining < 250*time.Millisecond {
+		t.Errorf("call got %v; want the 300ms limit", remaining)
+	}
+
+	callCtx, callCancel, err = cfg.Call(context.Background(), 0)
+	defer callCancel()
+	if _, ok := callCtx.Deadline(); ok || err != nil {
+		t.Errorf("call without budget got a deadline or %v; want it unchanged", err)
+	}
+}
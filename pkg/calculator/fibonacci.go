@@ -0,0 +1,24 @@
+package calculator
+
+import (
+	"iter"
+	"math/big"
+)
+
+/*
+Fibonacci yields the first count numbers of the sequence (0, 1, 1, 2, 3, 5...) with their index.
+Values are arbitrary precision, int32 overflows after the 46th term and int64 after the 92nd.
+Each yielded *big.Int is a fresh value the caller may keep.
+*/
+func Fibonacci(count int) iter.Seq2[int, *big.Int] {
+	return func(yield func(int, *big.Int) bool) {
+		a, b := big.NewInt(0), big.NewInt(1)
+		for i := range count {
+			if !yield(i, new(big.Int).Set(a)) {
+				return
+			}
+			a.Add(a, b)
+			a, b = b, a
+		}
+	}
+}
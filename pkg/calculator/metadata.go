@@ -0,0 +1,10 @@
+package calculator
+
+import "pkg/mdkit"
+
+// Metadata of the Calculate service's Add, shared by the e. server and the f. client
+var (
+	TestHeader  = mdkit.StringKey("test")
+	Test2Header = mdkit.StringKey("test2")
+	TestTrailer = mdkit.StringKey("test-trailer")
+)
@@ -0,0 +1,261 @@
+package chat
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrSlowConsumer = errors.New("chat: subscriber evicted, it was not keeping up with the room")
+	ErrEmptyRoom    = errors.New("chat: room name is required")
+	ErrTooManyRooms = errors.New("chat: too many rooms, try again later")
+)
+
+type Kind int
+
+const (
+	KindMessage Kind = iota
+	KindJoin
+	KindLeave
+)
+
+type Message struct {
+	ID       uint64 // increasing per room, join and leave events included
+	Room     string
+	Sender   string
+	Text     string
+	Kind     Kind
+	SentAt   time.Time
+	Replayed bool // delivered from history on join, not live
+}
+
+type BrokerConfig struct {
+	// BufferSize is how many undelivered live messages a subscriber may have queued before it is evicted.
+	BufferSize int
+	// HistorySize is how many past messages of a room are replayed to a member when they join.
+	HistorySize int
+	// IdleTimeout is how long a room without members keeps its history before it is dropped, 10 minutes when zero.
+	IdleTimeout time.Duration
+	// MaxRooms caps the number of rooms, 1024 when zero. Creating one more first drops the longest idle empty
+	// room, and fails with ErrTooManyRooms when every room still has members.
+	MaxRooms int
+}
+
+/*
+Broker fans every message published to a room out to all of the room's subscribers.
+Publishing never blocks on a subscriber: each one gets a bounded buffer, and a subscriber whose
+buffer is full is evicted (its channel closed, Err returning ErrSlowConsumer) so one stalled
+client can't hold up the rest of the room.
+Rooms are created on first use and bounded by MaxRooms, an empty room is dropped with its history
+once it has been idle for IdleTimeout.
+*/
+type Broker struct {
+	cfg BrokerConfig
+	now func() time.Time
+
+	mu    sync.Mutex
+	rooms map[string]*room
+}
+
+type room struct {
+	name    string
+	nextID  uint64
+	history []Message // ring of the last HistorySize messages, oldest first
+	subs    map[*Subscription]struct{}
+	idle    time.Time // when the last member left, zero while the room has members
+}
+
+func NewBroker(cfg BrokerConfig) *Broker {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 64
+	}
+	if cfg.HistorySize < 0 {
+		cfg.HistorySize = 0
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = 10 * time.Minute
+	}
+	if cfg.MaxRooms <= 0 {
+		cfg.MaxRooms = 1024
+	}
+	return &Broker{cfg: cfg, now: time.Now, rooms: make(map[string]*room)}
+}
+
+// Subscription is one member's view of a room. Read messages from C until it is closed.
+type Subscription struct {
+	C <-chan Message
+
+	broker *Broker
+	room   *room
+	member string
+	ch     chan Message
+	err    error
+	closed bool
+}
+
+// Join subscribes member to roomName, replays the room history into C and announces the join.
+func (b *Broker) Join(roomName, member string) (*Subscription, error) {
+	if roomName == "" {
+		return nil, ErrEmptyRoom
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	r, err := b.roomLocked(roomName)
+	if err != nil {
+		return nil, err
+	}
+
+	// The history fits on top of the live buffer, so replaying it can never evict the new member
+	ch := make(chan Message, b.cfg.BufferSize+len(r.history))
+	sub := &Subscription{C: ch, broker: b, room: r, member: member, ch: ch}
+	for _, m := range r.history {
+		m.Replayed = true
+		ch <- m
+	}
+
+	r.subs[sub] = struct{}{}
+	r.idle = time.Time{}
+	b.publishLocked(r, Message{Room: roomName, Sender: member, Kind: KindJoin})
+	return sub, nil
+}
+
+// Publish sends text from sender to every member of roomName, the sender included.
+func (b *Broker) Publish(roomName, sender, text string) (Message, error) {
+	if roomName == "" {
+		return Message{}, ErrEmptyRoom
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	r, err := b.roomLocked(roomName)
+	if err != nil {
+		return Message{}, err
+	}
+	return b.publishLocked(r, Message{Room: roomName, Sender: sender, Text: text, Kind: KindMessage}), nil
+}
+
+// Members lists who is currently subscribed to roomName.
+func (b *Broker) Members(roomName string) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var members []string
+	if r, ok := b.rooms[roomName]; ok {
+		for sub := range r.subs {
+			members = append(members, sub.member)
+		}
+	}
+	return members
+}
+
+// Rooms reports how many rooms the broker currently keeps, with or without members.
+func (b *Broker) Rooms() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.rooms)
+}
+
+/*
+roomLocked returns the room called name, creating it if needed.
+Creating a room first drops every empty room idle for longer than IdleTimeout, then, at MaxRooms,
+the longest idle empty one.
+*/
+func (b *Broker) roomLocked(name string) (*room, error) {
+	if r, ok := b.rooms[name]; ok {
+		return r, nil
+	}
+
+	now := b.now()
+	var oldest *room
+	for _, r := range b.rooms {
+		if len(r.subs) > 0 {
+			continue
+		}
+		if now.Sub(r.idle) > b.cfg.IdleTimeout {
+			delete(b.rooms, r.name)
+			continue
+		}
+		if oldest == nil || r.idle.Before(oldest.idle) {
+			oldest = r
+		}
+	}
+	if len(b.rooms) >= b.cfg.MaxRooms {
+		if oldest == nil {
+			return nil, ErrTooManyRooms
+		}
+		delete(b.rooms, oldest.name)
+	}
+
+	// A room starts idle, it only stays idle when the caller merely publishes into it
+	r := &room{name: name, subs: make(map[*Subscription]struct{}), idle: now}
+	b.rooms[name] = r
+	return r, nil
+}
+
+func (b *Broker) publishLocked(r *room, m Message) Message {
+	r.nextID++
+	m.ID = r.nextID
+	m.SentAt = time.Now()
+
+	if m.Kind == KindMessage && b.cfg.HistorySize > 0 {
+		r.history = append(r.history, m)
+		if len(r.history) > b.cfg.HistorySize {
+			r.history = r.history[len(r.history)-b.cfg.HistorySize:]
+		}
+	}
+
+	// Evict after the fan-out so the leave announcements get IDs after m and arrive after it
+	var evicted []*Subscription
+	for sub := range r.subs {
+		select {
+		case sub.ch <- m:
+		default:
+			evicted = append(evicted, sub)
+		}
+	}
+	for _, sub := range evicted {
+		b.removeLocked(sub, ErrSlowConsumer)
+	}
+	return m
+}
+
+// removeLocked drops sub from its room and closes its channel, announcing the departure to the others.
+func (b *Broker) removeLocked(sub *Subscription, err error) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	sub.err = err
+	delete(sub.room.subs, sub)
+	close(sub.ch)
+
+	// A room nobody is in keeps its history for IdleTimeout, so members rejoining soon get it replayed
+	if len(sub.room.subs) == 0 {
+		sub.room.idle = b.now()
+		if len(sub.room.history) == 0 {
+			delete(b.rooms, sub.room.name)
+			return
+		}
+	}
+	b.publishLocked(sub.room, Message{Room: sub.room.name, Sender: sub.member, Kind: KindLeave})
+}
+
+// Leave unsubscribes and closes C, messages already buffered in C can still be drained.
+func (s *Subscription) Leave() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	s.broker.removeLocked(s, nil)
+}
+
+// Err is ErrSlowConsumer once the subscription was evicted, nil otherwise.
+func (s *Subscription) Err() error {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	return s.err
+}
@@ -0,0 +1,162 @@
+package chat
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBrokerFanOutAndHistory(t *testing.T) {
+	b := NewBroker(BrokerConfig{BufferSize: 8, HistorySize: 2})
+
+	alice, err := b.Join("go", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-alice.C // own join
+
+	for _, text := range []string{"one", "two", "three"} {
+		b.Publish("go", "alice", text)
+	}
+
+	bob, err := b.Join("go", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the last HistorySize messages are replayed, then bob's own join
+	for _, want := range []string{"two", "three"} {
+		m := <-bob.C
+		if !m.Replayed || m.Text != want {
+			t.Errorf("replayed = %+v; want %q", m, want)
+		}
+	}
+	if m := <-bob.C; m.Kind != KindJoin || m.Sender != "bob" {
+		t.Errorf("got %+v; want bob's join", m)
+	}
+
+	for _, want := range []string{"one", "two", "three"} {
+		if m := <-alice.C; m.Text != want {
+			t.Errorf("alice got %q; want %q", m.Text, want)
+		}
+	}
+	if m := <-alice.C; m.Kind != KindJoin || m.Sender != "bob" {
+		t.Errorf("alice got %+v; want bob's join", m)
+	}
+
+	b.Publish("go", "bob", "hi")
+	if m := <-alice.C; m.Text != "hi" || m.Sender != "bob" || m.Replayed {
+		t.Errorf("alice got %+v; want live hi from bob", m)
+	}
+}
+
+func TestBrokerEvictsSlowConsumer(t *testing.T) {
+	b := NewBroker(BrokerConfig{BufferSize: 3})
+
+	slow, _ := b.Join("go", "slow")
+	fast, _ := b.Join("go", "fast")
+	<-fast.C // own join
+
+	// slow never reads: its join, fast's join and "1" fill its buffer, "2" overflows it
+	for _, text := range []string{"1", "2"} {
+		b.Publish("go", "fast", text)
+		if m := <-fast.C; m.Text != text {
+			t.Fatalf("fast got %+v; want %q", m, text)
+		}
+	}
+	if m := <-fast.C; m.Kind != KindLeave || m.Sender != "slow" {
+		t.Errorf("fast got %+v; want slow's leave after the message that evicted it", m)
+	}
+
+	for range slow.C {
+	}
+	if !errors.Is(slow.Err(), ErrSlowConsumer) {
+		t.Errorf("slow.Err() = %v; want ErrSlowConsumer", slow.Err())
+	}
+
+	members := b.Members("go")
+	if len(members) != 1 || members[0] != "fast" {
+		t.Errorf("members = %v; want [fast]", members)
+	}
+
+	fast.Leave()
+	if fast.Err() != nil {
+		t.Errorf("fast.Err() = %v after Leave; want nil", fast.Err())
+	}
+}
+
+func TestBrokerDropsIdleRooms(t *testing.T) {
+	b := NewBroker(BrokerConfig{HistorySize: 10, IdleTimeout: time.Minute})
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	// a room without history goes away with its last member
+	quiet, _ := b.Join("quiet", "alice")
+	quiet.Leave()
+	if b.Rooms() != 0 {
+		t.Fatalf("rooms = %d after the only member of a room without history left; want 0", b.Rooms())
+	}
+
+	alice, _ := b.Join("go", "alice")
+	b.Publish("go", "alice", "hello")
+	alice.Leave()
+
+	// within IdleTimeout the history is still replayed
+	now = now.Add(30 * time.Second)
+	bob, _ := b.Join("go", "bob")
+	if m := <-bob.C; m.Text != "hello" || !m.Replayed {
+		t.Errorf("bob got %+v; want the history replayed", m)
+	}
+	bob.Leave()
+
+	// publishing into rooms nobody joins must not keep them forever either
+	for _, name := range []string{"spam-1", "spam-2", "spam-3"} {
+		if _, err := b.Publish(name, "mallory", "x"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b.Rooms() != 4 {
+		t.Fatalf("rooms = %d; want go and the three spam rooms", b.Rooms())
+	}
+
+	now = now.Add(2 * time.Minute)
+	b.Join("new", "carol")
+	if b.Rooms() != 1 {
+		t.Errorf("rooms = %d after IdleTimeout; want only the new room", b.Rooms())
+	}
+}
+
+func TestBrokerCapsRooms(t *testing.T) {
+	b := NewBroker(BrokerConfig{HistorySize: 10, MaxRooms: 2})
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	b.Publish("old", "alice", "x")
+	now = now.Add(time.Second)
+	b.Publish("newer", "alice", "x")
+	now = now.Add(time.Second)
+
+	// at the cap the longest idle empty room makes way
+	if _, err := b.Publish("third", "alice", "x"); err != nil {
+		t.Fatal(err)
+	}
+	if b.Rooms() != 2 {
+		t.Errorf("rooms = %d; want the cap of 2", b.Rooms())
+	}
+	sub, _ := b.Join("old", "bob")
+	if len(sub.C) != 1 {
+		t.Errorf("old still had %d queued messages; want only bob's join, its history was dropped", len(sub.C))
+	}
+
+	// every room has members now, nothing can be dropped
+	b.Join("third", "carol")
+	if _, err := b.Join("fourth", "dave"); !errors.Is(err, ErrTooManyRooms) {
+		t.Errorf("Join = %v; want ErrTooManyRooms", err)
+	}
+	if _, err := b.Publish("fourth", "dave", "x"); !errors.Is(err, ErrTooManyRooms) {
+		t.Errorf("Publish = %v; want ErrTooManyRooms", err)
+	}
+	if _, err := b.Publish("third", "carol", "still fine"); err != nil {
+		t.Errorf("Publish to an existing room at the cap: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"pkg/mtls"
+)
+
+/*
+gencerts writes a throwaway CA with a server certificate and client certificates for local mTLS.
+
+	go run ./cmd/gencerts -out "../gRPC/e. gRPC Server/certs" -clients client
+
+- ca.pem is the trust anchor both sides load, the CA key is never written so rerunning replaces everything
+- server.pem / server-key.pem are valid for -hosts
+- <name>.pem / <name>-key.pem for every name in -clients, the name is the identity servers see
+*/
+func main() {
+	out := flag.String("out", "certs", "output directory")
+	hosts := flag.String("hosts", "localhost,127.0.0.1", "comma separated server host names and IPs")
+	clients := flag.String("clients", "client", "comma separated client certificate names")
+	validity := flag.Duration("validity", 10*365*24*time.Hour, "certificate lifetime")
+	flag.Parse()
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		log.Fatalln(err)
+	}
+
+	ca, err := mtls.NewCA("go-learning-repo dev CA", *validity)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if err := os.WriteFile(filepath.Join(*out, "ca.pem"), ca.CertPEM, 0o644); err != nil {
+		log.Fatalln(err)
+	}
+
+	server, err := ca.IssueServer("server", *validity, strings.Split(*hosts, ",")...)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if err := server.WriteFiles(*out, "server"); err != nil {
+		log.Fatalln(err)
+	}
+
+	for _, name := range strings.Split(*clients, ",") {
+		client, err := ca.IssueClient(name, *validity)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if err := client.WriteFiles(*out, name); err != nil {
+			log.Fatalln(err)
+		}
+	}
+	log.Println("Wrote certificates to", *out)
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"pkg/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+/*
+mint-token signs a JWT with a local key so the servers in this repo can be called during development.
+
+	go run ./cmd/mint-token -key ../REST-API/keys/dev.hmac -sub u1 -roles customer -aud restapi
+
+- *.hmac keys sign HS256
+- *.pem keys must hold a PKCS#8 "PRIVATE KEY" (RSA signs RS256, Ed25519 signs EdDSA)
+The kid header is the key file name without extension, matching auth.LoadKeySet.
+
+API keys are only stored hashed, -api-key prints the "hash" value to put in an apikeys.json entry:
+
+	go run ./cmd/mint-token -api-key "$(openssl rand -hex 24)"
+
+Signing keys and API keys are generated locally, nothing usable is checked in:
+
+	go run ./cmd/mint-token -new-hmac ../REST-API/keys/dev.hmac
+	go run ./cmd/mint-token -new-api-key ../REST-API/apikeys.json -id dev-admin -sub admin -roles admin
+
+- -new-hmac writes 32 random bytes, hex encoded, and refuses to overwrite an existing key
+- -new-api-key adds an entry with a random key to the file, creating it if needed, and prints the plaintext once
+*/
+func main() {
+	keyFile := flag.String("key", "", "signing key file (.hmac or .pem)")
+	subject := flag.String("sub", "", "subject claim")
+	roles := flag.String("roles", "", "comma separated roles")
+	audience := flag.String("aud", "", "audience claim")
+	issuer := flag.String("iss", "", "issuer claim")
+	ttl := flag.Duration("ttl", time.Hour, "token lifetime")
+	apiKey := flag.String("api-key", "", "print the apikeys.json hash of this plaintext API key instead of minting a JWT")
+	newHMAC := flag.String("new-hmac", "", "write a random HS256 signing key to this file instead of minting a JWT")
+	newAPIKey := flag.String("new-api-key", "", "add a random API key for -id, -sub and -roles to this apikeys.json and print it")
+	id := flag.String("id", "", "id of the -new-api-key entry")
+	flag.Parse()
+
+	switch {
+	case *apiKey != "":
+		fmt.Println(auth.HashAPIKey(*apiKey))
+		return
+	case *newHMAC != "":
+		if err := writeHMACKey(*newHMAC); err != nil {
+			log.Fatalln("Error writing key:", err)
+		}
+		return
+	case *newAPIKey != "":
+		if *id == "" || *subject == "" {
+			log.Fatalln("-new-api-key needs -id and -sub")
+		}
+		var roleList []string
+		if *roles != "" {
+			roleList = strings.Split(*roles, ",")
+		}
+		plaintext, err := addAPIKey(*newAPIKey, auth.APIKey{ID: *id, Subject: *subject, Roles: roleList})
+		if err != nil {
+			log.Fatalln("Error adding API key:", err)
+		}
+		fmt.Println(plaintext)
+		return
+	}
+
+	if *keyFile == "" || *subject == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	method, key, err := loadSigningKey(*keyFile)
+	if err != nil {
+		log.Fatalln("Error loading key:", err)
+	}
+
+	now := time.Now()
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   *subject,
+			Issuer:    *issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(*ttl)),
+		},
+	}
+	if *audience != "" {
+		claims.Audience = jwt.ClaimStrings{*audience}
+	}
+	if *roles != "" {
+		claims.Roles = strings.Split(*roles, ",")
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = strings.TrimSuffix(filepath.Base(*keyFile), filepath.Ext(*keyFile))
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		log.Fatalln("Error signing token:", err)
+	}
+	fmt.Println(signed)
+}
+
+// writeHMACKey creates path with a random secret, twice the 32 bytes auth.LoadKeySet requires once hex encoded
+func writeHMACKey(path string) error {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(file, hex.EncodeToString(secret)); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// addAPIKey appends key with the hash of a new random plaintext to the apikeys.json at path and returns the plaintext
+func addAPIKey(path string, key auth.APIKey) (string, error) {
+	var keys []auth.APIKey
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &keys); err != nil {
+			return "", fmt.Errorf("%s: %w", path, err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return "", err
+	}
+	for _, existing := range keys {
+		if existing.ID == key.ID {
+			return "", fmt.Errorf("%s already has a key %q", path, key.ID)
+		}
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	plaintext := hex.EncodeToString(secret)
+	key.Hash = auth.HashAPIKey(plaintext)
+	keys = append(keys, key)
+
+	data, err = json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return plaintext, os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+func loadSigningKey(path string) (jwt.SigningMethod, any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if filepath.Ext(path) == ".hmac" {
+		return jwt.SigningMethodHS256, bytes.TrimSpace(data), nil
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, nil, fmt.Errorf("%s: expected a PKCS#8 PRIVATE KEY block", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, key, nil
+	case ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA, key, nil
+	default:
+		return nil, nil, fmt.Errorf("%s: unsupported private key type %T", path, key)
+	}
+}
@@ -0,0 +1,133 @@
+package compression
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+/*
+Client compresses requests after a Policy and enforces Limits, one Client per connection.
+- Requests are only compressed with encodings the server advertised in AcceptHeader, calls before its first response go uncompressed
+- A grpc.UseCompressor option of the call itself wins over the policy
+- Unary requests are compressed by method and size, stream messages by method only
+*/
+type Client struct {
+	policy Policy
+	limits Limits
+
+	mu       sync.Mutex
+	accepted []string
+}
+
+func NewClient(policy Policy, limits Limits) *Client {
+	return &Client{policy: policy, limits: limits}
+}
+
+// DialOptions installs the interceptors and the receive and send limits of every call
+func (c *Client) DialOptions() []grpc.DialOption {
+	var callOptions []grpc.CallOption
+	if c.limits.MaxRecvBytes > 0 {
+		callOptions = append(callOptions, grpc.MaxCallRecvMsgSize(c.limits.MaxRecvBytes))
+	}
+	if c.limits.MaxSendBytes > 0 {
+		callOptions = append(callOptions, grpc.MaxCallSendMsgSize(c.limits.MaxSendBytes))
+	}
+	return []grpc.DialOption{
+		grpc.WithDefaultCallOptions(callOptions...),
+		grpc.WithChainUnaryInterceptor(c.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(c.StreamClientInterceptor()),
+	}
+}
+
+// Accepted lists the encodings the server advertised last
+func (c *Client) Accepted() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.accepted
+}
+
+func (c *Client) learn(header metadata.MD) {
+	if values := header.Get(AcceptHeader); len(values) > 0 {
+		c.mu.Lock()
+		c.accepted = parseAccept(values)
+		c.mu.Unlock()
+	}
+}
+
+func (c *Client) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := c.limits.checkSend(method, "request", req); err != nil {
+			return err
+		}
+		if !hasCompressor(opts) {
+			opts = append(opts, grpc.UseCompressor(c.policy.compressor(method, size(req), c.Accepted())))
+		}
+		// A second grpc.Header option would hide the caller's from interceptors that only keep one, like resilience
+		header := new(metadata.MD)
+		if i := slices.IndexFunc(opts, isHeaderOption); i >= 0 {
+			header = opts[i].(grpc.HeaderCallOption).HeaderAddr
+		} else {
+			opts = append(opts, grpc.Header(header))
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		c.learn(*header)
+		return err
+	}
+}
+
+func (c *Client) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if !hasCompressor(opts) {
+			opts = append(opts, grpc.UseCompressor(c.policy.compressor(method, math.MaxInt, c.Accepted())))
+		}
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &clientStream{ClientStream: stream, client: c, method: method}, nil
+	}
+}
+
+func hasCompressor(opts []grpc.CallOption) bool {
+	return slices.ContainsFunc(opts, func(opt grpc.CallOption) bool {
+		_, ok := opt.(grpc.CompressorCallOption)
+		return ok
+	})
+}
+
+func isHeaderOption(opt grpc.CallOption) bool {
+	_, ok := opt.(grpc.HeaderCallOption)
+	return ok
+}
+
+// clientStream checks the send limit of each message and learns the accepted encodings from the first response
+type clientStream struct {
+	grpc.ClientStream
+	client  *Client
+	method  string
+	learned bool
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	if err := s.client.limits.checkSend(s.method, "request", m); err != nil {
+		return err
+	}
+	return s.ClientStream.SendMsg(m)
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if !s.learned {
+		// The header arrived with the first message or the status, Header doesn't block anymore
+		s.learned = true
+		if header, headerErr := s.Header(); headerErr == nil {
+			s.client.learn(header)
+		}
+	}
+	return err
+}
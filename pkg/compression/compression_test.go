@@ -0,0 +1,115 @@
+package compression
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"pkg/grpctest"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding/gzip"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// payloadServer answers with response_size zero bytes, they compress to almost nothing
+type payloadServer struct {
+	testpb.UnimplementedTestServiceServer
+}
+
+func (payloadServer) UnaryCall(ctx context.Context, req *testpb.SimpleRequest) (*testpb.SimpleResponse, error) {
+	return &testpb.SimpleResponse{Payload: &testpb.Payload{Body: make([]byte, req.GetResponseSize())}}, nil
+}
+
+func newTestClient(t *testing.T, server *Server, client *Client) (testpb.TestServiceClient, *Meter) {
+	t.Helper()
+
+	meter := &Meter{}
+	srv := grpctest.Start(t, func(s *grpc.Server) {
+		testpb.RegisterTestServiceServer(s, payloadServer{})
+	}, grpctest.WithServerOptions(server.ServerOptions()...), grpctest.WithDialOptions(append(client.DialOptions(), grpc.WithStatsHandler(meter))...))
+	return grpctest.NewClient(srv, testpb.NewTestServiceClient), meter
+}
+
+// call makes one call and returns the bytes it sent and received
+func call(t *testing.T, client testpb.TestServiceClient, meter *Meter, req *testpb.SimpleRequest) (int64, int64) {
+	t.Helper()
+
+	sent, received := meter.Sent(), meter.Received()
+	if _, err := client.UnaryCall(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	return meter.Sent() - sent, meter.Received() - received
+}
+
+func payload(n int) *testpb.SimpleRequest {
+	return &testpb.SimpleRequest{Payload: &testpb.Payload{Body: make([]byte, n)}}
+}
+
+func TestClientCompressesOnceServerAdvertised(t *testing.T) {
+	policyClient := NewClient(DefaultPolicy, Limits{})
+	client, meter := newTestClient(t, NewServer(DefaultPolicy, Limits{}), policyClient)
+
+	req := payload(8 << 10)
+	if sent, _ := call(t, client, meter, req); sent < int64(proto.Size(req)) {
+		t.Errorf("first request took %d bytes; want it uncompressed before the server advertised gzip", sent)
+	}
+	if got := policyClient.Accepted(); len(got) != 1 || got[0] != gzip.Name {
+		t.Fatalf("Accepted() = %v; want [gzip]", got)
+	}
+	if sent, _ := call(t, client, meter, req); sent > 1<<10 {
+		t.Errorf("second request took %d bytes; want it gzipped", sent)
+	}
+}
+
+func TestPolicyBySizeAndMethod(t *testing.T) {
+	client, meter := newTestClient(t, NewServer(DefaultPolicy, Limits{}), NewClient(DefaultPolicy, Limits{}))
+	call(t, client, meter, payload(0))
+
+	// 5 bytes of gRPC framing in front of each message
+	small := &testpb.SimpleRequest{Payload: &testpb.Payload{Body: make([]byte, 100)}, ResponseSize: 100}
+	if sent, received := call(t, client, meter, small); sent != int64(proto.Size(small))+5 || received > 120 {
+		t.Errorf("small call took %d bytes sent, %d received; want both uncompressed", sent, received)
+	}
+	if _, received := call(t, client, meter, &testpb.SimpleRequest{ResponseSize: 64 << 10}); received > 1<<10 {
+		t.Errorf("large response took %d bytes; want it gzipped", received)
+	}
+
+	// A method rule without compressor overrides the default
+	noUnary := Policy{Default: DefaultPolicy.Default, Methods: map[string]Rule{testpb.TestService_UnaryCall_FullMethodName: {}}}
+	client, meter = newTestClient(t, NewServer(noUnary, Limits{}), NewClient(noUnary, Limits{}))
+	call(t, client, meter, payload(0))
+	if _, received := call(t, client, meter, &testpb.SimpleRequest{ResponseSize: 64 << 10}); received < 64<<10 {
+		t.Errorf("response took %d bytes; want it uncompressed for UnaryCall", received)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	client, meter := newTestClient(t, NewServer(Policy{}, Limits{MaxRecvBytes: 4 << 10, MaxSendBytes: 4 << 10}), NewClient(Policy{}, Limits{MaxSendBytes: 2 << 10}))
+	call(t, client, meter, payload(1<<10))
+
+	tests := []struct {
+		name string
+		req  *testpb.SimpleRequest
+		want string
+	}{
+		{"client send limit", payload(3 << 10), "/grpc.testing.TestService/UnaryCall request is 3078 bytes, over the limit of 2048 bytes"},
+		{"server send limit", &testpb.SimpleRequest{ResponseSize: 8 << 10}, "/grpc.testing.TestService/UnaryCall response is 8198 bytes, over the limit of 4096 bytes"},
+	}
+	for _, tt := range tests {
+		_, err := client.UnaryCall(context.Background(), tt.req)
+		if status.Code(err) != codes.ResourceExhausted || status.Convert(err).Message() != tt.want {
+			t.Errorf("%s: err = %v; want ResourceExhausted %q", tt.name, err, tt.want)
+		}
+	}
+
+	// The server's receive limit is grpc's own
+	client, _ = newTestClient(t, NewServer(Policy{}, Limits{MaxRecvBytes: 4 << 10}), NewClient(Policy{}, Limits{}))
+	_, err := client.UnaryCall(context.Background(), payload(8<<10))
+	if status.Code(err) != codes.ResourceExhausted || !strings.Contains(err.Error(), "larger than max") {
+		t.Errorf("err = %v; want ResourceExhausted from the server's receive limit", err)
+	}
+}
@@ -0,0 +1,37 @@
+package compression
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+/*
+Limits caps the size of single messages in bytes, zero keeps grpc's defaults of 4 MiB received and no limit sent.
+- Messages over MaxSendBytes fail with ResourceExhausted naming method and sizes before anything is sent
+- Messages over MaxRecvBytes are rejected by grpc itself with ResourceExhausted "received message larger than max"
+*/
+type Limits struct {
+	MaxRecvBytes int
+	MaxSendBytes int
+}
+
+// checkSend fails msg, the request or response of fullMethod, if it is over MaxSendBytes
+func (l Limits) checkSend(fullMethod, kind string, msg any) error {
+	m, ok := msg.(proto.Message)
+	if l.MaxSendBytes <= 0 || !ok {
+		return nil
+	}
+	if size := proto.Size(m); size > l.MaxSendBytes {
+		return status.Errorf(codes.ResourceExhausted, "%s %s is %d bytes, over the limit of %d bytes", fullMethod, kind, size, l.MaxSendBytes)
+	}
+	return nil
+}
+
+// size is the uncompressed size of msg on the wire
+func size(msg any) int {
+	if m, ok := msg.(proto.Message); ok {
+		return proto.Size(m)
+	}
+	return 0
+}
@@ -0,0 +1,38 @@
+package compression
+
+import (
+	"context"
+	"sync/atomic"
+
+	"google.golang.org/grpc/stats"
+)
+
+// Meter is a stats.Handler adding up the bytes of the messages sent and received, compressed and framed as on the wire
+type Meter struct {
+	sent, received atomic.Int64
+}
+
+// Sent is the number of message bytes written so far
+func (m *Meter) Sent() int64 {
+	return m.sent.Load()
+}
+
+// Received is the number of message bytes read so far
+func (m *Meter) Received() int64 {
+	return m.received.Load()
+}
+
+func (m *Meter) HandleRPC(_ context.Context, s stats.RPCStats) {
+	switch payload := s.(type) {
+	case *stats.OutPayload:
+		m.sent.Add(int64(payload.WireLength))
+	case *stats.InPayload:
+		m.received.Add(int64(payload.WireLength))
+	}
+}
+
+func (m *Meter) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context { return ctx }
+
+func (m *Meter) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context { return ctx }
+
+func (m *Meter) HandleConn(context.Context, stats.ConnStats) {}
@@ -0,0 +1,78 @@
+package compression
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+// AcceptHeader lists the encodings a peer decompresses, comma separated, as in the gRPC HTTP/2 protocol
+const AcceptHeader = "grpc-accept-encoding"
+
+// Rule decides whether a message is compressed
+type Rule struct {
+	Compressor string // a registered compressor like gzip.Name, empty sends messages uncompressed
+	MinBytes   int    // smaller messages stay uncompressed, compressing a few bytes costs CPU and usually grows them
+}
+
+/*
+Policy picks the compressor of each call.
+- Methods is keyed by full method name ("/calculator.Calculate/Add") or service name ("calculator.Calculate"), the most specific wins
+- Calls of methods missing in Methods follow Default, the zero Policy never compresses
+- Streams can't know their message sizes up front, they compress all messages if the rule has a compressor
+*/
+type Policy struct {
+	Default Rule
+	Methods map[string]Rule
+}
+
+// DefaultPolicy gzips messages of 1 KiB and more
+var DefaultPolicy = Policy{Default: Rule{Compressor: gzip.Name, MinBytes: 1 << 10}}
+
+func (p Policy) rule(fullMethod string) Rule {
+	if rule, ok := p.Methods[fullMethod]; ok {
+		return rule
+	}
+	service, _, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if rule, ok := p.Methods[service]; ok {
+		return rule
+	}
+	return p.Default
+}
+
+// compressor names the compressor for a message of size bytes, encoding.Identity if it stays uncompressed or the peer can't decompress it
+func (p Policy) compressor(fullMethod string, size int, accepted []string) string {
+	rule := p.rule(fullMethod)
+	if rule.Compressor == "" || rule.Compressor == encoding.Identity || size < rule.MinBytes || !slices.Contains(accepted, rule.Compressor) {
+		return encoding.Identity
+	}
+	return rule.Compressor
+}
+
+// compressors lists the compressors p uses, the encodings a server with p has to accept
+func (p Policy) compressors() []string {
+	var names []string
+	for _, rule := range append([]Rule{p.Default}, slices.Collect(maps.Values(p.Methods))...) {
+		if rule.Compressor != "" && rule.Compressor != encoding.Identity && !slices.Contains(names, rule.Compressor) {
+			names = append(names, rule.Compressor)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// parseAccept splits an AcceptHeader value
+func parseAccept(values []string) []string {
+	var names []string
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,88 @@
+package compression
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+/*
+Server compresses responses after a Policy and enforces Limits.
+- Every response header advertises the policy's compressors in AcceptHeader, grpc-go servers don't send it on their own
+- Responses are only compressed with encodings the client accepts, otherwise grpc would answer in the request's encoding
+- Unary responses are compressed by size, except for handlers that send their header themselves, which fixes the compressor before the response exists
+- Importing this package registers gzip
+*/
+type Server struct {
+	policy Policy
+	limits Limits
+	accept metadata.MD
+}
+
+func NewServer(policy Policy, limits Limits) *Server {
+	s := &Server{policy: policy, limits: limits}
+	if names := policy.compressors(); len(names) > 0 {
+		s.accept = metadata.Pairs(AcceptHeader, strings.Join(names, ","))
+	}
+	return s
+}
+
+// ServerOptions installs the interceptors and the receive and send limits
+func (s *Server) ServerOptions() []grpc.ServerOption {
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(s.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(s.StreamServerInterceptor()),
+	}
+	if s.limits.MaxRecvBytes > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(s.limits.MaxRecvBytes))
+	}
+	if s.limits.MaxSendBytes > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(s.limits.MaxSendBytes))
+	}
+	return opts
+}
+
+func (s *Server) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		accepted, _ := grpc.ClientSupportedCompressors(ctx)
+		grpc.SetHeader(ctx, s.accept)
+		// Picked again once the size is known, unless the handler sent the header with it by then
+		grpc.SetSendCompressor(ctx, s.policy.compressor(info.FullMethod, 0, accepted))
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+		if err := s.limits.checkSend(info.FullMethod, "response", resp); err != nil {
+			return nil, err
+		}
+		grpc.SetSendCompressor(ctx, s.policy.compressor(info.FullMethod, size(resp), accepted))
+		return resp, nil
+	}
+}
+
+func (s *Server) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		accepted, _ := grpc.ClientSupportedCompressors(ss.Context())
+		ss.SetHeader(s.accept)
+		grpc.SetSendCompressor(ss.Context(), s.policy.compressor(info.FullMethod, math.MaxInt, accepted))
+		return handler(srv, &serverStream{ServerStream: ss, limits: s.limits, method: info.FullMethod})
+	}
+}
+
+// serverStream checks the send limit of each response
+type serverStream struct {
+	grpc.ServerStream
+	limits Limits
+	method string
+}
+
+func (s *serverStream) SendMsg(m any) error {
+	if err := s.limits.checkSend(s.method, "response", m); err != nil {
+		return err
+	}
+	return s.ServerStream.SendMsg(m)
+}
@@ -0,0 +1,59 @@
+package grpctest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails" // resolves the standard error details inside statuses
+)
+
+var update = flag.Bool("update-golden", false, "rewrite the testdata/*.golden files of grpctest.Golden")
+
+/*
+Golden compares msgs, as indented protojson, with testdata/<name>.golden.
+- Streams pass every message in order, errors pass status.Convert(err).Proto()
+- go test -update-golden writes the files instead, review them before committing
+- Fields that change per call, timestamps and IDs, have to be cleared before
+*/
+func Golden(t testing.TB, name string, msgs ...proto.Message) {
+	t.Helper()
+
+	var got bytes.Buffer
+	for _, msg := range msgs {
+		data, err := protojson.Marshal(msg)
+		if err != nil {
+			t.Fatalf("golden %s: %v", name, err)
+		}
+		// protojson randomizes its whitespace, json.Indent makes it stable
+		if err := json.Indent(&got, data, "", "  "); err != nil {
+			t.Fatalf("golden %s: %v", name, err)
+		}
+		got.WriteByte('\n')
+	}
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("golden %s: %v, run go test -update-golden to create it", name, err)
+	}
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Errorf("%s differs from %s:\n%s\nwant\n%s", name, path, got.Bytes(), want)
+	}
+}
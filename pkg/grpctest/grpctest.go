@@ -0,0 +1,133 @@
+package grpctest
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+
+	"pkg/mtls"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// target is the address clients dial, with TLS it is also the server name the certificate has to cover
+const target = "bufnet"
+
+// Server is a gRPC server on an in-memory listener, stopped when the test ends
+type Server struct {
+	*grpc.Server
+	conn *grpc.ClientConn
+}
+
+type config struct {
+	serverOptions []grpc.ServerOption
+	dialOptions   []grpc.DialOption
+	creds         credentials.TransportCredentials
+	serverCreds   credentials.TransportCredentials
+}
+
+type Option func(*config)
+
+// WithServerOptions passes options like interceptors to grpc.NewServer
+func WithServerOptions(opts ...grpc.ServerOption) Option {
+	return func(c *config) { c.serverOptions = append(c.serverOptions, opts...) }
+}
+
+// WithDialOptions adds options like client interceptors or per-RPC credentials to the client connection
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(c *config) { c.dialOptions = append(c.dialOptions, opts...) }
+}
+
+// WithTLS serves with server and dials with client, client has to trust a certificate for "bufnet"
+func WithTLS(server, client *tls.Config) Option {
+	return func(c *config) {
+		c.serverCreds = credentials.NewTLS(server)
+		c.creds = credentials.NewTLS(client)
+	}
+}
+
+/*
+WithMutualTLS issues certificates from a throwaway mtls.CA, the server requires a client certificate.
+- clientName is the common name handlers see through mtls.PeerIdentity
+- A CA that can't be created fails the test
+*/
+func WithMutualTLS(t testing.TB, clientName string) Option {
+	t.Helper()
+
+	ca, err := mtls.NewCA("grpctest CA", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverCert, err := ca.IssueServer(target, time.Hour, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCert, err := ca.IssueClient(clientName, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return WithTLS(
+		&tls.Config{
+			Certificates: []tls.Certificate{serverCert.TLS},
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			ClientCAs:    ca.Pool(),
+			MinVersion:   tls.VersionTLS13,
+		},
+		&tls.Config{
+			Certificates: []tls.Certificate{clientCert.TLS},
+			RootCAs:      ca.Pool(),
+			ServerName:   target,
+			MinVersion:   tls.VersionTLS13,
+		},
+	)
+}
+
+/*
+Start serves the services register adds on a bufconn listener and dials it.
+- No port is opened, the connection lives as long as the test
+- Without WithTLS both sides are insecure
+*/
+func Start(t testing.TB, register func(*grpc.Server), opts ...Option) *Server {
+	t.Helper()
+
+	c := config{creds: insecure.NewCredentials()}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	serverOptions := c.serverOptions
+	if c.serverCreds != nil {
+		serverOptions = append([]grpc.ServerOption{grpc.Creds(c.serverCreds)}, serverOptions...)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer(serverOptions...)
+	register(grpcServer)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	dialOptions := append([]grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(c.creds),
+	}, c.dialOptions...)
+	conn, err := grpc.NewClient("passthrough:///"+target, dialOptions...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &Server{Server: grpcServer, conn: conn}
+}
+
+// Conn is the client connection to the server
+func (s *Server) Conn() *grpc.ClientConn {
+	return s.conn
+}
+
+// NewClient makes a typed client with a generated constructor, like grpctest.NewClient(srv, pb.NewGreeterClient)
+func NewClient[C any](s *Server, newClient func(grpc.ClientConnInterface) C) C {
+	return newClient(s.conn)
+}
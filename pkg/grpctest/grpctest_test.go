@@ -0,0 +1,66 @@
+package grpctest
+
+import (
+	"context"
+	"testing"
+
+	"pkg/mtls"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type identityHealth struct {
+	healthpb.UnimplementedHealthServer
+}
+
+// Check sends the caller's certificate common name back in the x-identity header
+func (h *identityHealth) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	id, ok := mtls.PeerIdentity(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "no client certificate")
+	}
+	grpc.SetHeader(ctx, metadata.Pairs("x-identity", id.CommonName))
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func TestStartServesRegisteredServices(t *testing.T) {
+	var intercepted []string
+	srv := Start(t, func(s *grpc.Server) {
+		healthpb.RegisterHealthServer(s, health.NewServer())
+	}, WithServerOptions(grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		intercepted = append(intercepted, info.FullMethod)
+		return handler(ctx, req)
+	})))
+	client := NewClient(srv, healthpb.NewHealthClient)
+
+	res, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"})
+	Golden(t, "health_check", res, status.Convert(err).Proto())
+
+	if len(intercepted) != 2 || intercepted[0] != healthpb.Health_Check_FullMethodName {
+		t.Errorf("intercepted %v; want both calls", intercepted)
+	}
+}
+
+func TestWithMutualTLS(t *testing.T) {
+	srv := Start(t, func(s *grpc.Server) {
+		healthpb.RegisterHealthServer(s, &identityHealth{})
+	}, WithMutualTLS(t, "gateway"))
+
+	var header metadata.MD
+	_, err := NewClient(srv, healthpb.NewHealthClient).Check(context.Background(), &healthpb.HealthCheckRequest{}, grpc.Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get("x-identity"); len(got) != 1 || got[0] != "gateway" {
+		t.Errorf("x-identity = %v; want gateway", got)
+	}
+}
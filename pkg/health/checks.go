@@ -0,0 +1,32 @@
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"time"
+)
+
+// CertificateCheck fails once the certificate in certFile is expired or within minValidity of expiring.
+func CertificateCheck(certFile, keyFile string, minValidity time.Duration) CheckFunc {
+	return func(ctx context.Context) error {
+		pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		cert, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		switch {
+		case now.Before(cert.NotBefore):
+			return fmt.Errorf("certificate %s not valid before %s", certFile, cert.NotBefore.Format(time.RFC3339))
+		case now.Add(minValidity).After(cert.NotAfter):
+			return fmt.Errorf("certificate %s expires %s", certFile, cert.NotAfter.Format(time.RFC3339))
+		}
+		return nil
+	}
+}
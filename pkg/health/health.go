@@ -0,0 +1,153 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Methods are the full method names of the health service, list them as public methods on servers with auth.
+var Methods = []string{
+	healthpb.Health_Check_FullMethodName,
+	healthpb.Health_List_FullMethodName,
+	healthpb.Health_Watch_FullMethodName,
+}
+
+// CheckFunc reports whether a dependency (a store, a certificate, ...) is usable, nil means ready.
+type CheckFunc func(ctx context.Context) error
+
+/*
+Server is the grpc.health.v1 service of a server plus the readiness checks deciding its statuses.
+- Every service starts NOT_SERVING and becomes SERVING once all checks pass, a failing check flips them back
+- "" is the overall status, as the health checking protocol expects
+- Drain marks everything NOT_SERVING for good, call it before GracefulStop so load balancers stop sending work
+*/
+type Server struct {
+	hs       *grpchealth.Server
+	services []string
+
+	mu       sync.Mutex
+	checks   map[string]CheckFunc
+	results  map[string]error
+	ready    bool
+	draining bool
+}
+
+func NewServer(services ...string) *Server {
+	s := &Server{
+		hs:       grpchealth.NewServer(),
+		services: append([]string{""}, services...),
+		checks:   make(map[string]CheckFunc),
+		results:  make(map[string]error),
+	}
+	s.setStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+	return s
+}
+
+// AddCheck adds a named readiness check, it is run on the next Check.
+func (s *Server) AddCheck(name string, check CheckFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checks[name] = check
+}
+
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	healthpb.RegisterHealthServer(registrar, s.hs)
+}
+
+// Check runs every readiness check once and updates the serving statuses, it returns whether the server is ready.
+func (s *Server) Check(ctx context.Context) bool {
+	s.mu.Lock()
+	checks := make(map[string]CheckFunc, len(s.checks))
+	for name, check := range s.checks {
+		checks[name] = check
+	}
+	s.mu.Unlock()
+
+	results := make(map[string]error, len(checks))
+	ready := true
+	for name, check := range checks {
+		results[name] = check(ctx)
+		if results[name] != nil {
+			ready = false
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results = results
+	s.ready = ready
+	if s.draining {
+		return false
+	}
+	if ready {
+		s.setStatus(healthpb.HealthCheckResponse_SERVING)
+	} else {
+		s.setStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	return ready
+}
+
+// Run checks right away and then every interval until ctx is done.
+func (s *Server) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		checkCtx, cancel := context.WithTimeout(ctx, interval)
+		s.Check(checkCtx)
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Drain reports NOT_SERVING for every service from now on, later checks no longer change that.
+func (s *Server) Drain() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.draining = true
+	s.hs.Shutdown()
+}
+
+// Status is the readiness of the server with the outcome of each check, as served on /readyz.
+type Status struct {
+	Ready    bool              `json:"ready"`
+	Draining bool              `json:"draining,omitempty"`
+	Checks   map[string]string `json:"checks"` // check name to "ok" or its error
+}
+
+func (s *Server) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := Status{Ready: s.ready && !s.draining, Draining: s.draining, Checks: make(map[string]string, len(s.results))}
+	names := make([]string, 0, len(s.results))
+	for name := range s.results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		st.Checks[name] = "ok"
+		if err := s.results[name]; err != nil {
+			st.Checks[name] = err.Error()
+		}
+	}
+	return st
+}
+
+// setStatus must be called with mu held
+func (s *Server) setStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	for _, service := range s.services {
+		s.hs.SetServingStatus(service, status)
+	}
+}
@@ -0,0 +1,63 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pkg/grpctest"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func newHealthClient(t *testing.T, hs *Server) healthpb.HealthClient {
+	t.Helper()
+	srv := grpctest.Start(t, func(s *grpc.Server) { hs.Register(s) })
+	return grpctest.NewClient(srv, healthpb.NewHealthClient)
+}
+
+func TestServingStatusFollowsChecksAndDrain(t *testing.T) {
+	hs := NewServer("calculator.Calculator")
+	client := newHealthClient(t, hs)
+	ctx := context.Background()
+
+	statusOf := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		t.Helper()
+		res, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.GetStatus()
+	}
+	readyz := func() int {
+		rec := httptest.NewRecorder()
+		hs.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return rec.Code
+	}
+
+	if got := statusOf("calculator.Calculator"); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("before any check: %s; want NOT_SERVING", got)
+	}
+
+	storeErr := errors.New("store unreachable")
+	hs.AddCheck("store", func(context.Context) error { return storeErr })
+	hs.Check(ctx)
+	if got := statusOf(""); got != healthpb.HealthCheckResponse_NOT_SERVING || readyz() != http.StatusServiceUnavailable {
+		t.Errorf("failing check: %s, /readyz %d; want NOT_SERVING and 503", got, readyz())
+	}
+
+	storeErr = nil
+	hs.Check(ctx)
+	if got := statusOf("calculator.Calculator"); got != healthpb.HealthCheckResponse_SERVING || readyz() != http.StatusOK {
+		t.Errorf("passing check: %s, /readyz %d; want SERVING and 200", got, readyz())
+	}
+
+	hs.Drain()
+	hs.Check(ctx)
+	if got := statusOf("calculator.Calculator"); got != healthpb.HealthCheckResponse_NOT_SERVING || readyz() != http.StatusServiceUnavailable {
+		t.Errorf("draining: %s, /readyz %d; want NOT_SERVING and 503", got, readyz())
+	}
+}
@@ -0,0 +1,44 @@
+package health
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+)
+
+// ReflectionMode is how a server exposes server reflection.
+type ReflectionMode string
+
+const (
+	ReflectionOff ReflectionMode = "off"
+	// ReflectionPublic lets anyone list and describe the services, for local development only
+	ReflectionPublic ReflectionMode = "public"
+	// ReflectionAuthenticated registers reflection behind the server's auth interceptors, only valid on servers that have them
+	ReflectionAuthenticated ReflectionMode = "authenticated"
+)
+
+// ReflectionMethods are the full method names of both reflection versions, for rbac rules.
+var ReflectionMethods = []string{
+	reflectionpb.ServerReflection_ServerReflectionInfo_FullMethodName,
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+}
+
+// ParseReflectionMode reads a mode from configuration, "" is ReflectionOff.
+func ParseReflectionMode(s string) (ReflectionMode, error) {
+	switch mode := ReflectionMode(strings.ToLower(s)); mode {
+	case "":
+		return ReflectionOff, nil
+	case ReflectionOff, ReflectionPublic, ReflectionAuthenticated:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown reflection mode %q, want off, public or authenticated", s)
+}
+
+// RegisterReflection registers server reflection unless mode is ReflectionOff.
+func RegisterReflection(s reflection.GRPCServer, mode ReflectionMode) {
+	if mode != ReflectionOff {
+		reflection.Register(s)
+	}
+}
@@ -0,0 +1,29 @@
+package health
+
+import (
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+/*
+Shutdown drains grpcServer gracefully.
+- Health turns NOT_SERVING first, then delay gives health-checking load balancers time to notice
+- GracefulStop waits for in-flight calls, after timeout the remaining ones are cut off with Stop
+*/
+func (s *Server) Shutdown(grpcServer *grpc.Server, delay, timeout time.Duration) {
+	s.Drain()
+	time.Sleep(delay)
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		grpcServer.Stop()
+	}
+}
@@ -0,0 +1,33 @@
+package interceptors
+
+import (
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+/*
+ServeDebug serves expvar's /debug/vars, where Metrics.Publish registers, on addr until the listener fails.
+expvar includes the command line and memory stats, so addr must be a loopback address such as
+"localhost:6060" or "127.0.0.1:6060", never ":6060".
+*/
+func ServeDebug(addr string) error {
+	if err := checkLoopback(addr); err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	return http.ListenAndServe(addr, mux)
+}
+
+func checkLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("interceptors: debug address %q: %w", addr, err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return fmt.Errorf("interceptors: debug address %q is not a loopback address", addr)
+	}
+	return nil
+}
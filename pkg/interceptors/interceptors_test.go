@@ -0,0 +1,162 @@
+package interceptors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"pkg/grpctest"
+	"pkg/mdkit"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// callFunc is the body of the single unary method of the test service
+type callFunc func(ctx context.Context) error
+
+var testService = grpc.ServiceDesc{
+	ServiceName: "test.Interceptors",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Call",
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			in := new(emptypb.Empty)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, _ any) (any, error) {
+				return &emptypb.Empty{}, srv.(callFunc)(ctx)
+			}
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Interceptors/Call"}, handler)
+		},
+	}},
+}
+
+func newTestConn(t *testing.T, cfg Config, fn callFunc) *grpc.ClientConn {
+	t.Helper()
+	srv := grpctest.Start(t, func(s *grpc.Server) { s.RegisterService(&testService, fn) },
+		grpctest.WithServerOptions(ServerOptions(cfg)...))
+	return srv.Conn()
+}
+
+func call(ctx context.Context, conn *grpc.ClientConn, opts ...grpc.CallOption) error {
+	return conn.Invoke(ctx, "/test.Interceptors/Call", &emptypb.Empty{}, &emptypb.Empty{}, opts...)
+}
+
+func TestRecoveryLoggingAndMetrics(t *testing.T) {
+	var logs bytes.Buffer
+	metrics := NewMetrics()
+	conn := newTestConn(t, Config{Logger: slog.New(slog.NewJSONHandler(&logs, nil)), Metrics: metrics}, func(ctx context.Context) error {
+		panic("boom")
+	})
+
+	err := call(context.Background(), conn)
+	if status.Code(err) != codes.Internal || status.Convert(err).Message() != "internal error" {
+		t.Fatalf("err = %v; want Internal without panic details", err)
+	}
+
+	var line struct {
+		Msg       string `json:"msg"`
+		Level     string `json:"level"`
+		Code      string `json:"code"`
+		RequestID string `json:"request_id"`
+	}
+	for l := range bytes.Lines(logs.Bytes()) {
+		if json.Unmarshal(l, &line) == nil && line.Msg == "grpc call" {
+			break
+		}
+	}
+	if line.Level != "ERROR" || line.Code != "Internal" || line.RequestID == "" {
+		t.Errorf("call log = %+v; want an ERROR line with code Internal and a request ID\n%s", line, logs.String())
+	}
+
+	snapshot := metrics.Snapshot()["/test.Interceptors/Call"]
+	if snapshot.Count != 1 || snapshot.Codes["Internal"] != 1 || snapshot.Buckets["+Inf"] != 1 {
+		t.Errorf("metrics = %+v; want one Internal call", snapshot)
+	}
+}
+
+func TestRequestIDPropagation(t *testing.T) {
+	var seen string
+	conn := newTestConn(t, Config{Logger: slog.New(slog.DiscardHandler)}, func(ctx context.Context) error {
+		seen = RequestIDFromContext(ctx)
+		return nil
+	})
+
+	var header metadata.MD
+	ctx := mdkit.RequestID.AppendOutgoing(context.Background(), "req-123")
+	if err := call(ctx, conn, grpc.Header(&header)); err != nil {
+		t.Fatal(err)
+	}
+	if seen != "req-123" || header.Get(mdkit.RequestID.Name())[0] != "req-123" {
+		t.Errorf("handler saw %q, response header %v; want req-123 in both", seen, header.Get(mdkit.RequestID.Name()))
+	}
+
+	if err := call(context.Background(), conn, grpc.Header(&header)); err != nil {
+		t.Fatal(err)
+	}
+	if seen == "" || seen == "req-123" || header.Get(mdkit.RequestID.Name())[0] != seen {
+		t.Errorf("handler saw %q, response header %v; want a fresh ID echoed back", seen, header.Get(mdkit.RequestID.Name()))
+	}
+}
+
+func TestDefaultDeadline(t *testing.T) {
+	var remaining time.Duration
+	conn := newTestConn(t, Config{Logger: slog.New(slog.DiscardHandler), UnaryTimeout: time.Second}, func(ctx context.Context) error {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			return status.Error(codes.FailedPrecondition, "no deadline")
+		}
+		remaining = time.Until(deadline)
+		return nil
+	})
+
+	if err := call(context.Background(), conn); err != nil || remaining > time.Second || remaining < 500*time.Millisecond {
+		t.Errorf("remaining = %s, err = %v; want the 1s default", remaining, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := call(ctx, conn); err != nil || remaining < 50*time.Second {
+		t.Errorf("remaining = %s, err = %v; want the client's 1m deadline kept", remaining, err)
+	}
+}
+
+func TestStandardTrailers(t *testing.T) {
+	conn := newTestConn(t, Config{Logger: slog.New(slog.DiscardHandler)}, func(ctx context.Context) error {
+		time.Sleep(5 * time.Millisecond)
+		return status.Error(codes.NotFound, "gone")
+	})
+
+	var trailer metadata.MD
+	ctx := mdkit.RequestID.AppendOutgoing(context.Background(), "req-456")
+	if err := call(ctx, conn, grpc.Trailer(&trailer)); status.Code(err) != codes.NotFound {
+		t.Fatalf("err = %v; want NotFound", err)
+	}
+	if id, err := mdkit.RequestID.Get(trailer); err != nil || id != "req-456" {
+		t.Errorf("request ID trailer = %q, %v; want req-456", id, err)
+	}
+	if took, err := mdkit.ServerTiming.Get(trailer); err != nil || took < 5*time.Millisecond || took > time.Second {
+		t.Errorf("server timing trailer = %v, %v; want the 5ms the handler slept", took, err)
+	}
+}
+
+func TestServeDebugOnlyOnLoopback(t *testing.T) {
+	for _, addr := range []string{":6060", "0.0.0.0:6060", "[::]:6060", "10.0.0.1:6060", "example.com:6060", "6060"} {
+		if err := ServeDebug(addr); err == nil {
+			t.Errorf("ServeDebug(%q) served on a non-loopback address", addr)
+		}
+	}
+	for _, addr := range []string{"localhost:6060", "127.0.0.1:6060", "[::1]:6060"} {
+		if err := checkLoopback(addr); err != nil {
+			t.Errorf("%s: %v", addr, err)
+		}
+	}
+}
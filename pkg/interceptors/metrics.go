@@ -0,0 +1,130 @@
+package interceptors
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultBuckets are the latency histogram upper bounds, calls slower than the last one land in the +Inf bucket.
+var DefaultBuckets = []time.Duration{
+	5 * time.Millisecond, 10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2500 * time.Millisecond, 5 * time.Second, 10 * time.Second,
+}
+
+/*
+Metrics keeps per-method latency histograms and status code counts.
+- Snapshot returns them as plain data, Publish exposes them through expvar (/debug/vars)
+- Histogram buckets are cumulative like Prometheus' le buckets, so a bucket counts every call at or below its bound
+*/
+type Metrics struct {
+	buckets []time.Duration
+
+	mu      sync.Mutex
+	methods map[string]*methodMetrics
+}
+
+type methodMetrics struct {
+	count   uint64
+	sum     time.Duration
+	buckets []uint64 // one per bound plus +Inf, not cumulative until Snapshot
+	codes   map[string]uint64
+}
+
+type MethodSnapshot struct {
+	Count      uint64            `json:"count"`
+	SumSeconds float64           `json:"sum_seconds"`
+	Buckets    map[string]uint64 `json:"buckets"` // upper bound ("+Inf" for the last) to cumulative count
+	Codes      map[string]uint64 `json:"codes"`
+}
+
+// NewMetrics uses DefaultBuckets when no bucket bounds are given, bounds must be ascending.
+func NewMetrics(buckets ...time.Duration) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	return &Metrics{buckets: buckets, methods: make(map[string]*methodMetrics)}
+}
+
+func (m *Metrics) Observe(method string, code string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mm, ok := m.methods[method]
+	if !ok {
+		mm = &methodMetrics{buckets: make([]uint64, len(m.buckets)+1), codes: make(map[string]uint64)}
+		m.methods[method] = mm
+	}
+	mm.count++
+	mm.sum += d
+	mm.codes[code]++
+
+	i := 0
+	for i < len(m.buckets) && d > m.buckets[i] {
+		i++
+	}
+	mm.buckets[i]++
+}
+
+func (m *Metrics) Snapshot() map[string]MethodSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make(map[string]MethodSnapshot, len(m.methods))
+	for method, mm := range m.methods {
+		s := MethodSnapshot{
+			Count:      mm.count,
+			SumSeconds: mm.sum.Seconds(),
+			Buckets:    make(map[string]uint64, len(mm.buckets)),
+			Codes:      make(map[string]uint64, len(mm.codes)),
+		}
+		var cumulative uint64
+		for i, n := range mm.buckets {
+			cumulative += n
+			bound := "+Inf"
+			if i < len(m.buckets) {
+				bound = m.buckets[i].String()
+			}
+			s.Buckets[bound] = cumulative
+		}
+		for code, n := range mm.codes {
+			s.Codes[code] = n
+		}
+		out[method] = s
+	}
+	return out
+}
+
+// Publish registers the metrics as an expvar variable, expvar panics when the name is already taken.
+func (m *Metrics) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() any { return m.Snapshot() }))
+}
+
+func (m *Metrics) String() string {
+	b, _ := json.Marshal(m.Snapshot())
+	return string(b)
+}
+
+func UnaryMetrics(m *Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.Observe(info.FullMethod, status.Code(err).String(), time.Since(start))
+		return resp, err
+	}
+}
+
+func StreamMetrics(m *Metrics) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		m.Observe(info.FullMethod, status.Code(err).String(), time.Since(start))
+		return err
+	}
+}
@@ -0,0 +1,85 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"pkg/mdkit"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type requestIDKey struct{}
+
+func NewRequestIDContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID of the call, "" outside of the RequestID interceptors.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func NewRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+/*
+UnaryRequestID puts the caller's x-request-id, or a fresh one, into the context.
+- The ID is echoed back in the response header so clients can quote it in bug reports
+- Outgoing calls made with the handler's context carry it on when the client uses UnaryClientRequestID
+*/
+func UnaryRequestID() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(requestIDContext(ctx), req)
+	}
+}
+
+func StreamRequestID() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := requestIDContext(ss.Context())
+		return handler(srv, withContext(ss, ctx))
+	}
+}
+
+func requestIDContext(ctx context.Context) context.Context {
+	id, err := mdkit.RequestID.Incoming(ctx)
+	if err != nil || id == "" || len(id) > 128 {
+		id = NewRequestID()
+	}
+
+	// Only fails without a server transport stream (e.g. a handler called directly), the ID still lands in ctx
+	mdkit.RequestID.SetHeader(ctx, id)
+	return NewRequestIDContext(ctx, id)
+}
+
+// UnaryClientRequestID forwards the request ID of ctx to the called server, keeping one ID across hops.
+func UnaryClientRequestID() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingRequestID(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+func StreamClientRequestID() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingRequestID(ctx), desc, cc, method, opts...)
+	}
+}
+
+func outgoingRequestID(ctx context.Context) context.Context {
+	id := RequestIDFromContext(ctx)
+	if id == "" {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		if _, err := mdkit.RequestID.Get(md); err == nil {
+			return ctx
+		}
+	}
+	return mdkit.RequestID.AppendOutgoing(ctx, id)
+}
@@ -0,0 +1,57 @@
+package interceptors
+
+import (
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+type Config struct {
+	Logger *slog.Logger // slog.Default() when nil
+	// Metrics is left out of the chain when nil
+	Metrics *Metrics
+	// UnaryTimeout is the deadline of unary calls whose client set none, 0 leaves them unbounded
+	UnaryTimeout time.Duration
+	// StreamTimeout is the same for streams, keep it 0 on servers with long-lived streams
+	StreamTimeout time.Duration
+	// Validate enforces protoc-gen-validate rules on every request and streamed message
+	Validate bool
+}
+
+/*
+ServerOptions chains the suite in the order
+- request ID, so every later interceptor and the handler see it
+- standard trailers, the request ID and the server timing of the whole chain below
+- logging and metrics, so they record the status the client actually gets
+- recovery, turning panics below it into codes.Internal before logging sees them
+- default deadline
+- validation when enabled, closest to the handler
+
+More interceptors (auth, rbac, ...) can follow with further grpc.ChainUnaryInterceptor / grpc.ChainStreamInterceptor
+options, gRPC appends every chain option in order so they run inside the suite.
+*/
+func ServerOptions(cfg Config) []grpc.ServerOption {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	unary := []grpc.UnaryServerInterceptor{UnaryRequestID(), UnaryTrailers(), UnaryLogging(logger)}
+	stream := []grpc.StreamServerInterceptor{StreamRequestID(), StreamTrailers(), StreamLogging(logger)}
+	if cfg.Metrics != nil {
+		unary = append(unary, UnaryMetrics(cfg.Metrics))
+		stream = append(stream, StreamMetrics(cfg.Metrics))
+	}
+	unary = append(unary, UnaryRecovery(logger), UnaryDefaultDeadline(cfg.UnaryTimeout))
+	stream = append(stream, StreamRecovery(logger), StreamDefaultDeadline(cfg.StreamTimeout))
+	if cfg.Validate {
+		unary = append(unary, UnaryValidate())
+		stream = append(stream, StreamValidate())
+	}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+}
@@ -0,0 +1,38 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"pkg/mdkit"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryTrailers adds the standard trailers of mdkit to every response, the request ID and how long the server took
+func UnaryTrailers() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		grpc.SetTrailer(ctx, standardTrailers(ctx, start))
+		return resp, err
+	}
+}
+
+func StreamTrailers() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		ss.SetTrailer(standardTrailers(ss.Context(), start))
+		return err
+	}
+}
+
+func standardTrailers(ctx context.Context, start time.Time) metadata.MD {
+	trailer := mdkit.ServerTiming.Pairs(time.Since(start))
+	if id := RequestIDFromContext(ctx); id != "" {
+		mdkit.RequestID.Set(trailer, id)
+	}
+	return trailer
+}
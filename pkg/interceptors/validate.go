@@ -0,0 +1,112 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The method sets protoc-gen-validate generates, matched structurally so this package doesn't depend on it
+type (
+	allValidator interface{ ValidateAll() error }
+	validator    interface{ Validate() error }
+	multiError   interface{ AllErrors() []error }
+	fieldError   interface {
+		Field() string
+		Reason() string
+		Cause() error
+	}
+)
+
+/*
+UnaryValidate rejects requests whose protoc-gen-validate rules fail with InvalidArgument.
+- ValidateAll is preferred so the errdetails.BadRequest detail lists every violation, Validate is the fallback
+- Violations inside embedded messages are reported with dotted paths of the Go field names, such as "Address.City"
+- Messages without generated validators pass through untouched
+*/
+func UnaryValidate() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := ValidateMessage(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamValidate validates every message the client streams, as it is received.
+func StreamValidate() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingStream{ServerStream: ss})
+	}
+}
+
+type validatingStream struct {
+	grpc.ServerStream
+}
+
+func (s *validatingStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return ValidateMessage(m)
+}
+
+// ValidateMessage runs the generated validation of m, returning nil or an InvalidArgument status with a BadRequest detail.
+func ValidateMessage(m any) error {
+	var err error
+	switch v := m.(type) {
+	case allValidator:
+		err = v.ValidateAll()
+	case validator:
+		err = v.Validate()
+	}
+	if err == nil {
+		return nil
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	collectViolations(badRequest, "", err)
+
+	st := status.New(codes.InvalidArgument, "invalid request: "+err.Error())
+	if detailed, detailErr := st.WithDetails(badRequest); detailErr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+func collectViolations(badRequest *errdetails.BadRequest, prefix string, err error) {
+	var multi multiError
+	if errors.As(err, &multi) {
+		for _, e := range multi.AllErrors() {
+			collectViolations(badRequest, prefix, e)
+		}
+		return
+	}
+
+	var field fieldError
+	if !errors.As(err, &field) {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       prefix,
+			Description: err.Error(),
+		})
+		return
+	}
+
+	path := field.Field()
+	if prefix != "" {
+		path = prefix + "." + path
+	}
+	// An embedded message failing carries that message's own validation errors as its cause
+	if cause := field.Cause(); cause != nil && (errors.As(cause, &multi) || errors.As(cause, new(fieldError))) {
+		collectViolations(badRequest, path, cause)
+		return
+	}
+	badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+		Field:       path,
+		Description: field.Reason(),
+	})
+}
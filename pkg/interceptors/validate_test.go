@@ -0,0 +1,64 @@
+package interceptors
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Shaped like protoc-gen-validate output for an Order with an embedded Address
+type testValidationError struct {
+	field, reason string
+	cause         error
+}
+
+func (e testValidationError) Error() string  { return e.field + ": " + e.reason }
+func (e testValidationError) Field() string  { return e.field }
+func (e testValidationError) Reason() string { return e.reason }
+func (e testValidationError) Cause() error   { return e.cause }
+
+type testMultiError []error
+
+func (m testMultiError) Error() string      { return errors.Join(m...).Error() }
+func (m testMultiError) AllErrors() []error { return m }
+
+type testOrder struct{ err error }
+
+func (o testOrder) ValidateAll() error { return o.err }
+
+func TestValidateMessageFlattensEmbeddedViolations(t *testing.T) {
+	address := testMultiError{
+		testValidationError{field: "City", reason: "value length must be at least 1 runes"},
+		testValidationError{field: "Zip", reason: "value does not match regex pattern"},
+	}
+	order := testOrder{err: testMultiError{
+		testValidationError{field: "Quantity", reason: "value must be greater than 0"},
+		testValidationError{field: "Address", reason: "embedded message failed validation", cause: address},
+	}}
+
+	err := ValidateMessage(order)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("err = %v; want InvalidArgument", err)
+	}
+
+	badRequest := status.Convert(err).Details()[0].(*errdetails.BadRequest)
+	var fields []string
+	for _, v := range badRequest.GetFieldViolations() {
+		fields = append(fields, v.GetField())
+	}
+	want := []string{"Quantity", "Address.City", "Address.Zip"}
+	if !slices.Equal(fields, want) {
+		t.Errorf("violation fields = %v; want %v", fields, want)
+	}
+
+	if err := ValidateMessage(testOrder{}); err != nil {
+		t.Errorf("valid message: %v", err)
+	}
+	if err := ValidateMessage(struct{}{}); err != nil {
+		t.Errorf("message without validator: %v", err)
+	}
+}
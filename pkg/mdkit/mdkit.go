@@ -0,0 +1,105 @@
+package mdkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// ErrMissing is returned for keys the metadata doesn't have
+var ErrMissing = errors.New("mdkit: key missing")
+
+// Key is a metadata key with the type of its values, declare it once and share it between server and clients
+type Key[T any] struct {
+	name   string
+	encode func(T) string
+	decode func(string) (T, error)
+}
+
+/*
+NewKey declares a key whose values encode and decode convert.
+- name is lowercased the way grpc stores it
+- Values of keys ending in -bin may be any bytes, grpc base64 encodes them on the wire, all others have to be printable ASCII
+*/
+func NewKey[T any](name string, encode func(T) string, decode func(string) (T, error)) Key[T] {
+	return Key[T]{name: strings.ToLower(name), encode: encode, decode: decode}
+}
+
+func StringKey(name string) Key[string] {
+	return NewKey(name, func(s string) string { return s }, func(s string) (string, error) { return s, nil })
+}
+
+func IntKey(name string) Key[int64] {
+	return NewKey(name, func(n int64) string { return strconv.FormatInt(n, 10) }, func(s string) (int64, error) {
+		return strconv.ParseInt(s, 10, 64)
+	})
+}
+
+// DurationKey writes values as time.Duration.String does, like "1.5s"
+func DurationKey(name string) Key[time.Duration] {
+	return NewKey(name, time.Duration.String, time.ParseDuration)
+}
+
+// MustBinaryKey panics unless name ends in -bin, the suffix tells grpc the value is binary.
+// Meant for package-level keys, where a bad name is a programming error
+func MustBinaryKey(name string) Key[[]byte] {
+	if !strings.HasSuffix(strings.ToLower(name), "-bin") {
+		panic(fmt.Sprintf("mdkit: binary key %q has to end in -bin", name))
+	}
+	return NewKey(name, func(b []byte) string { return string(b) }, func(s string) ([]byte, error) { return []byte(s), nil })
+}
+
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+// Pairs is metadata holding only v under k
+func (k Key[T]) Pairs(v T) metadata.MD {
+	return metadata.Pairs(k.name, k.encode(v))
+}
+
+// Set replaces the values of k in md with v
+func (k Key[T]) Set(md metadata.MD, v T) {
+	md.Set(k.name, k.encode(v))
+}
+
+// Get decodes the first value of k in md, a header or trailer from grpc.Header or grpc.Trailer on clients
+func (k Key[T]) Get(md metadata.MD) (T, error) {
+	var zero T
+	values := md.Get(k.name)
+	if len(values) == 0 {
+		return zero, fmt.Errorf("%w: %s", ErrMissing, k.name)
+	}
+	v, err := k.decode(values[0])
+	if err != nil {
+		return zero, fmt.Errorf("mdkit: %s: %w", k.name, err)
+	}
+	return v, nil
+}
+
+// Incoming decodes k from the request metadata inside a handler
+func (k Key[T]) Incoming(ctx context.Context) (T, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return k.Get(md)
+}
+
+// AppendOutgoing adds v to the request metadata of calls made with the returned context
+func (k Key[T]) AppendOutgoing(ctx context.Context, v T) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, k.name, k.encode(v))
+}
+
+// SetHeader adds v to the response header of a unary handler, see grpc.SetHeader
+func (k Key[T]) SetHeader(ctx context.Context, v T) error {
+	return grpc.SetHeader(ctx, k.Pairs(v))
+}
+
+// SetTrailer adds v to the response trailer of a unary handler, see grpc.SetTrailer
+func (k Key[T]) SetTrailer(ctx context.Context, v T) error {
+	return grpc.SetTrailer(ctx, k.Pairs(v))
+}
@@ -0,0 +1,69 @@
+package mdkit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestKeysRoundTrip(t *testing.T) {
+	md := metadata.MD{}
+	IntKey("X-Count").Set(md, -42)
+	DurationKey("retry-after").Set(md, 1500*time.Millisecond)
+	MustBinaryKey("token-bin").Set(md, []byte{0, 0xff, '\n'})
+	ServerTiming.Set(md, 12345*time.Microsecond)
+
+	if got := md.Get("x-count"); len(got) != 1 || got[0] != "-42" {
+		t.Errorf("x-count = %v; want the lowercased key holding -42", got)
+	}
+	if got := md.Get("server-timing"); len(got) != 1 || got[0] != "total;dur=12.345" {
+		t.Errorf("server-timing = %v; want total;dur=12.345", got)
+	}
+
+	if n, err := IntKey("x-count").Get(md); err != nil || n != -42 {
+		t.Errorf("x-count = %d, %v; want -42", n, err)
+	}
+	if d, err := DurationKey("retry-after").Get(md); err != nil || d != 1500*time.Millisecond {
+		t.Errorf("retry-after = %v, %v; want 1.5s", d, err)
+	}
+	if b, err := MustBinaryKey("token-bin").Get(md); err != nil || !bytes.Equal(b, []byte{0, 0xff, '\n'}) {
+		t.Errorf("token-bin = %v, %v; want the bytes unchanged", b, err)
+	}
+	if d, err := ServerTiming.Get(md); err != nil || d != 12345*time.Microsecond {
+		t.Errorf("server-timing = %v, %v; want 12.345ms", d, err)
+	}
+}
+
+func TestGetErrors(t *testing.T) {
+	md := metadata.Pairs("x-count", "many")
+	if _, err := IntKey("x-count").Get(md); err == nil || errors.Is(err, ErrMissing) {
+		t.Errorf("err = %v; want a decode error", err)
+	}
+	if _, err := IntKey("x-other").Get(md); !errors.Is(err, ErrMissing) {
+		t.Errorf("err = %v; want ErrMissing", err)
+	}
+	if _, err := StringKey("x-count").Incoming(context.Background()); !errors.Is(err, ErrMissing) {
+		t.Errorf("err = %v; want ErrMissing outside of a call", err)
+	}
+}
+
+func TestOutgoingToIncoming(t *testing.T) {
+	key := IntKey("x-count")
+	out, _ := metadata.FromOutgoingContext(key.AppendOutgoing(context.Background(), 7))
+	if n, err := key.Incoming(metadata.NewIncomingContext(context.Background(), out)); err != nil || n != 7 {
+		t.Errorf("Incoming() = %d, %v; want 7", n, err)
+	}
+}
+
+func TestMustBinaryKeyNeedsSuffix(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MustBinaryKey(token) didn't panic")
+		}
+	}()
+	MustBinaryKey("token")
+}
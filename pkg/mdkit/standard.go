@@ -0,0 +1,40 @@
+package mdkit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The standard keys the interceptors suite sets on every response
+var (
+	// RequestID is the call's x-request-id, in the header and again in the trailer
+	RequestID = StringKey("x-request-id")
+	// ServerTiming is the time the server spent on a call, written like the HTTP Server-Timing header ("total;dur=12.345" in ms)
+	ServerTiming = NewKey("server-timing", formatServerTiming, parseServerTiming)
+)
+
+// The keys of pkg/resilience's retries and hedging
+var (
+	// Attempt tells the server which attempt of a call it is serving, starting at 1
+	Attempt = IntKey("x-attempt")
+	// Attempts is the number of attempts a call took, in the trailer the client reads with grpc.Trailer
+	Attempts = IntKey("x-attempts")
+)
+
+func formatServerTiming(d time.Duration) string {
+	return "total;dur=" + strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
+
+func parseServerTiming(s string) (time.Duration, error) {
+	ms, ok := strings.CutPrefix(s, "total;dur=")
+	if !ok {
+		return 0, fmt.Errorf("server timing %q has no total;dur=", s)
+	}
+	f, err := strconv.ParseFloat(ms, 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(f * float64(time.Millisecond)), nil
+}
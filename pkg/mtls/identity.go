@@ -0,0 +1,42 @@
+package mtls
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// Identity is who the client certificate of a call says the caller is.
+type Identity struct {
+	CommonName  string
+	DNSNames    []string
+	URIs        []string // e.g. SPIFFE IDs
+	Fingerprint string   // hex sha256 of the certificate
+}
+
+// PeerIdentity reads the verified client certificate of a gRPC call from peer.FromContext, false without one.
+func PeerIdentity(ctx context.Context) (Identity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return Identity{}, false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return Identity{}, false
+	}
+
+	cert := info.State.VerifiedChains[0][0]
+	sum := sha256.Sum256(cert.Raw)
+	id := Identity{
+		CommonName:  cert.Subject.CommonName,
+		DNSNames:    cert.DNSNames,
+		Fingerprint: hex.EncodeToString(sum[:]),
+	}
+	for _, uri := range cert.URIs {
+		id.URIs = append(id.URIs, uri.String())
+	}
+	return id, true
+}
@@ -0,0 +1,134 @@
+package mtls
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type identityHealth struct {
+	healthpb.UnimplementedHealthServer
+}
+
+// Check sends the caller's certificate common name back in the x-identity header
+func (h *identityHealth) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	id, ok := PeerIdentity(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "no client certificate")
+	}
+	grpc.SetHeader(ctx, metadata.Pairs("x-identity", id.CommonName))
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+// writeCA issues a server certificate and a "gateway" client certificate from a fresh CA into a temp dir
+func writeCA(t *testing.T) (string, *CA) {
+	t.Helper()
+
+	dir := t.TempDir()
+	ca, err := NewCA("test CA", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ca.pem"), ca.CertPEM, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	server, err := ca.IssueServer("server", time.Hour, "localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := ca.IssueClient("gateway", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.WriteFiles(dir, "server"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.WriteFiles(dir, "gateway"); err != nil {
+		t.Fatal(err)
+	}
+	return dir, ca
+}
+
+func startServer(t *testing.T, dir string) string {
+	t.Helper()
+
+	cfg, err := ServerConfig(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"), filepath.Join(dir, "ca.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer(grpc.Creds(credentials.NewTLS(cfg)))
+	healthpb.RegisterHealthServer(srv, &identityHealth{})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String()
+}
+
+func check(t *testing.T, addr string, creds credentials.TransportCredentials) (string, error) {
+	t.Helper()
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	var header metadata.MD
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}, grpc.Header(&header))
+	if err != nil {
+		return "", err
+	}
+	return header.Get("x-identity")[0], nil
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir, _ := writeCA(t)
+	addr := startServer(t, dir)
+
+	clientCfg, err := ClientConfig(filepath.Join(dir, "gateway.pem"), filepath.Join(dir, "gateway-key.pem"), filepath.Join(dir, "ca.pem"), "localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity, err := check(t, addr, credentials.NewTLS(clientCfg)); err != nil || identity != "gateway" {
+		t.Errorf("call with client certificate: identity %q, %v; want the server to see gateway", identity, err)
+	}
+
+	// No client certificate: the handshake fails before any handler runs
+	noCert := clientCfg.Clone()
+	noCert.Certificates = nil
+	if _, err := check(t, addr, credentials.NewTLS(noCert)); status.Code(err) != codes.Unavailable {
+		t.Errorf("call without client certificate: %v; want Unavailable", err)
+	}
+
+	// A certificate from another CA is rejected too
+	otherDir, _ := writeCA(t)
+	otherCfg, err := ClientConfig(filepath.Join(otherDir, "gateway.pem"), filepath.Join(otherDir, "gateway-key.pem"), filepath.Join(dir, "ca.pem"), "localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := check(t, addr, credentials.NewTLS(otherCfg)); status.Code(err) != codes.Unavailable {
+		t.Errorf("call with a foreign client certificate: %v; want Unavailable", err)
+	}
+
+	// The server name is verified, the certificate is only valid for localhost
+	wrongName := clientCfg.Clone()
+	wrongName.ServerName = "calculator.example.com"
+	if _, err := check(t, addr, credentials.NewTLS(wrongName)); status.Code(err) != codes.Unavailable {
+		t.Errorf("call expecting another server name: %v; want Unavailable", err)
+	}
+}
@@ -0,0 +1,31 @@
+package rbac
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+)
+
+type DecisionLogger interface {
+	LogDecision(d Decision)
+}
+
+// JSONLogger writes one JSON object per decision, ready for grep or jq.
+type JSONLogger struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func NewJSONLogger(w io.Writer) *JSONLogger {
+	return &JSONLogger{enc: json.NewEncoder(w)}
+}
+
+func (l *JSONLogger) LogDecision(d Decision) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.enc.Encode(d); err != nil {
+		log.Println("rbac: writing decision log:", err)
+	}
+}
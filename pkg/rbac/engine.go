@@ -0,0 +1,152 @@
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"pkg/auth"
+)
+
+// Resource is what an action is performed on. OwnerID is only needed for ":own" permissions.
+type Resource struct {
+	Type    string `json:"type"`
+	ID      string `json:"id,omitempty"`
+	OwnerID string `json:"owner_id,omitempty"`
+}
+
+type Decision struct {
+	Time     time.Time `json:"time"`
+	Subject  string    `json:"subject"`
+	Roles    []string  `json:"roles"`
+	Action   string    `json:"action"`
+	Resource Resource  `json:"resource"`
+	Allowed  bool      `json:"allowed"`
+	Rule     string    `json:"rule,omitempty"` // permission that granted access
+	Reason   string    `json:"reason"`
+}
+
+// Engine answers can(principal, action, resource) against a policy file that can be reloaded while serving.
+type Engine struct {
+	path string
+	log  DecisionLogger
+
+	mu      sync.RWMutex
+	roles   compiled
+	modTime time.Time
+}
+
+// NewEngine loads the policy at path. Decisions go to logger, which may be nil.
+func NewEngine(path string, logger DecisionLogger) (*Engine, error) {
+	e := &Engine{path: path, log: logger}
+	if err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Reload re-reads the policy file. On error the previous policy stays in force.
+func (e *Engine) Reload() error {
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return err
+	}
+	policy, err := LoadPolicy(e.path)
+	if err != nil {
+		return err
+	}
+	roles, err := policy.compile()
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.roles = roles
+	e.modTime = info.ModTime()
+	e.mu.Unlock()
+	return nil
+}
+
+// WatchFile polls the policy file every interval and reloads it when it changes, until ctx is done.
+func (e *Engine) WatchFile(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(e.path)
+			if err != nil {
+				log.Println("rbac: stat policy:", err)
+				continue
+			}
+
+			e.mu.RLock()
+			changed := !info.ModTime().Equal(e.modTime)
+			e.mu.RUnlock()
+			if !changed {
+				continue
+			}
+
+			if err := e.Reload(); err != nil {
+				log.Println("rbac: reload failed, keeping previous policy:", err)
+				continue
+			}
+			log.Println("rbac: policy reloaded from", e.path)
+		}
+	}
+}
+
+// Can reports whether p may perform action on res.
+func (e *Engine) Can(p *auth.Principal, action string, res Resource) bool {
+	return e.Decide(p, action, res).Allowed
+}
+
+// Decide is Can with the reasoning, every decision is also sent to the decision log.
+func (e *Engine) Decide(p *auth.Principal, action string, res Resource) Decision {
+	d := Decision{Time: time.Now(), Action: action, Resource: res}
+	if p == nil {
+		d.Reason = "no principal"
+		e.record(d)
+		return d
+	}
+	d.Subject, d.Roles = p.Subject, p.Roles
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	ownershipMissed := false
+	for _, role := range p.Roles {
+		for _, perm := range e.roles[role] {
+			if !perm.matches(res.Type, action) {
+				continue
+			}
+			if perm.own && (res.OwnerID == "" || res.OwnerID != p.Subject) {
+				ownershipMissed = true
+				continue
+			}
+			d.Allowed, d.Rule = true, perm.raw
+			d.Reason = fmt.Sprintf("granted by role %q", role)
+			e.record(d)
+			return d
+		}
+	}
+
+	d.Reason = "no matching permission"
+	if ownershipMissed {
+		d.Reason = "permission only covers the caller's own resources"
+	}
+	e.record(d)
+	return d
+}
+
+func (e *Engine) record(d Decision) {
+	if e.log != nil {
+		e.log.LogDecision(d)
+	}
+}
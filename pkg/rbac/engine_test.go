@@ -0,0 +1,131 @@
+package rbac
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"pkg/auth"
+)
+
+const testPolicy = `
+roles:
+  guest:
+    permissions: ["users:read:own"]
+  customer:
+    inherits: [guest]
+    permissions: ["orders:read:own", "orders:create:own"]
+  admin:
+    permissions: ["*:*"]
+`
+
+type recordingLogger struct {
+	decisions []Decision
+}
+
+func (l *recordingLogger) LogDecision(d Decision) {
+	l.decisions = append(l.decisions, d)
+}
+
+func TestDecide(t *testing.T) {
+	logger := &recordingLogger{}
+	e, err := NewEngine(writePolicy(t, "policy.yaml", testPolicy), logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	customer := &auth.Principal{Subject: "u1", Roles: []string{"customer"}}
+	admin := &auth.Principal{Subject: "root", Roles: []string{"admin"}}
+
+	tests := []struct {
+		name    string
+		p       *auth.Principal
+		action  string
+		res     Resource
+		allowed bool
+		rule    string
+		reason  string
+	}{
+		{"own order", customer, "read", Resource{Type: "orders", ID: "o1", OwnerID: "u1"}, true, "orders:read:own", `granted by role "customer"`},
+		{"other's order", customer, "read", Resource{Type: "orders", ID: "o2", OwnerID: "u2"}, false, "", "permission only covers the caller's own resources"},
+		{"unowned resource", customer, "read", Resource{Type: "orders"}, false, "", "permission only covers the caller's own resources"},
+		{"inherited", customer, "read", Resource{Type: "users", ID: "u1", OwnerID: "u1"}, true, "users:read:own", `granted by role "customer"`},
+		{"no permission", customer, "delete", Resource{Type: "orders", OwnerID: "u1"}, false, "", "no matching permission"},
+		{"wildcards", admin, "delete", Resource{Type: "orders", OwnerID: "u2"}, true, "*:*", `granted by role "admin"`},
+		{"unknown role", &auth.Principal{Subject: "u1", Roles: []string{"ghost"}}, "read", Resource{Type: "users", OwnerID: "u1"}, false, "", "no matching permission"},
+		{"no principal", nil, "read", Resource{Type: "users"}, false, "", "no principal"},
+	}
+	for _, tt := range tests {
+		d := e.Decide(tt.p, tt.action, tt.res)
+		if d.Allowed != tt.allowed || d.Rule != tt.rule || d.Reason != tt.reason {
+			t.Errorf("%s: decision = %+v; want allowed=%v rule=%q reason=%q", tt.name, d, tt.allowed, tt.rule, tt.reason)
+		}
+	}
+	if len(logger.decisions) != len(tests) {
+		t.Errorf("logged %d decisions; want %d", len(logger.decisions), len(tests))
+	}
+}
+
+func TestReloadKeepsPolicyOnBadFile(t *testing.T) {
+	path := writePolicy(t, "policy.yaml", testPolicy)
+	e, err := NewEngine(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	customer := &auth.Principal{Subject: "u1", Roles: []string{"customer"}}
+	own := Resource{Type: "orders", OwnerID: "u1"}
+
+	for _, bad := range []string{"roles: [", "roles:\n  a:\n    inherits: [a]\n"} {
+		if err := os.WriteFile(path, []byte(bad), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := e.Reload(); err == nil {
+			t.Errorf("Reload of %q succeeded", bad)
+		}
+		if !e.Can(customer, "read", own) {
+			t.Errorf("after a failed reload of %q the previous policy is gone", bad)
+		}
+	}
+}
+
+func TestWatchFile(t *testing.T) {
+	path := writePolicy(t, "policy.yaml", testPolicy)
+	e, err := NewEngine(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go e.WatchFile(ctx, 5*time.Millisecond)
+
+	customer := &auth.Principal{Subject: "u1", Roles: []string{"customer"}}
+	own := Resource{Type: "orders", OwnerID: "u1"}
+
+	// modification times are bumped explicitly so the change is seen on filesystems with coarse timestamps
+	modTime := time.Now()
+	update := func(data string) {
+		t.Helper()
+		modTime = modTime.Add(time.Second)
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	update("roles: [")
+	time.Sleep(50 * time.Millisecond)
+	if !e.Can(customer, "read", own) {
+		t.Fatal("a broken policy file replaced the previous policy")
+	}
+
+	update("roles:\n  customer:\n    permissions: []\n")
+	deadline := time.Now().Add(2 * time.Second)
+	for e.Can(customer, "read", own) {
+		if time.Now().After(deadline) {
+			t.Fatal("the fixed policy file was never reloaded")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,69 @@
+package rbac
+
+import (
+	"context"
+
+	"pkg/auth"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+/*
+MethodRule maps a gRPC method onto an action on a resource type.
+Resource, when set, derives the resource (id and owner) from the request so ":own" permissions
+can apply, it is only available for unary calls.
+*/
+type MethodRule struct {
+	Action       string
+	ResourceType string
+	Resource     func(req any) Resource
+}
+
+/*
+UnaryServerInterceptor enforces rules keyed by full method name, run it after the auth interceptor.
+Methods without a rule are denied unless listed in public.
+*/
+func UnaryServerInterceptor(e *Engine, rules map[string]MethodRule, public ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := authorize(ctx, e, rules, public, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func StreamServerInterceptor(e *Engine, rules map[string]MethodRule, public ...string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(ss.Context(), e, rules, public, info.FullMethod, nil); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func authorize(ctx context.Context, e *Engine, rules map[string]MethodRule, public []string, method string, req any) error {
+	for _, m := range public {
+		if m == method {
+			return nil
+		}
+	}
+
+	rule, ok := rules[method]
+	if !ok {
+		return status.Errorf(codes.PermissionDenied, "no access rule for %s", method)
+	}
+
+	res := Resource{Type: rule.ResourceType}
+	if rule.Resource != nil && req != nil {
+		res = rule.Resource(req)
+		res.Type = rule.ResourceType
+	}
+
+	p, _ := auth.FromContext(ctx)
+	if !e.Can(p, rule.Action, res) {
+		return status.Error(codes.PermissionDenied, "permission denied")
+	}
+	return nil
+}
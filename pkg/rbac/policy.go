@@ -0,0 +1,122 @@
+package rbac
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+/*
+Policy is the file format, YAML or JSON:
+
+	roles:
+	  admin:
+	    permissions: ["users:*", "orders:*"]
+	  customer:
+	    inherits: [guest]
+	    permissions: ["users:read:own", "orders:read:own", "orders:create:own"]
+
+A permission is <resource>:<action>[:own].
+- "*" matches any resource type or any action
+- ":own" only grants the action when the resource belongs to the caller (Resource.OwnerID == Principal.Subject)
+*/
+type Policy struct {
+	Roles map[string]Role `json:"roles" yaml:"roles"`
+}
+
+type Role struct {
+	Inherits    []string `json:"inherits,omitempty" yaml:"inherits,omitempty"`
+	Permissions []string `json:"permissions" yaml:"permissions"`
+}
+
+type permission struct {
+	raw      string
+	resource string
+	action   string
+	own      bool
+}
+
+func (p permission) matches(resource, action string) bool {
+	return (p.resource == "*" || p.resource == resource) && (p.action == "*" || p.action == action)
+}
+
+// compiled maps every role to its permissions with inheritance already flattened.
+type compiled map[string][]permission
+
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{}
+	if filepath.Ext(path) == ".json" {
+		err = json.Unmarshal(data, policy)
+	} else {
+		err = yaml.Unmarshal(data, policy)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rbac: %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+func (p *Policy) compile() (compiled, error) {
+	c := make(compiled, len(p.Roles))
+	for name := range p.Roles {
+		perms, err := p.flatten(name, nil)
+		if err != nil {
+			return nil, err
+		}
+		c[name] = perms
+	}
+	return c, nil
+}
+
+func (p *Policy) flatten(name string, visiting []string) ([]permission, error) {
+	for _, v := range visiting {
+		if v == name {
+			return nil, fmt.Errorf("rbac: role inheritance cycle %s -> %s", strings.Join(visiting, " -> "), name)
+		}
+	}
+	role, ok := p.Roles[name]
+	if !ok {
+		return nil, fmt.Errorf("rbac: unknown role %q", name)
+	}
+
+	var perms []permission
+	for _, raw := range role.Permissions {
+		perm, err := parsePermission(raw)
+		if err != nil {
+			return nil, fmt.Errorf("rbac: role %q: %w", name, err)
+		}
+		perms = append(perms, perm)
+	}
+	for _, parent := range role.Inherits {
+		inherited, err := p.flatten(parent, append(visiting, name))
+		if err != nil {
+			return nil, err
+		}
+		perms = append(perms, inherited...)
+	}
+	return perms, nil
+}
+
+func parsePermission(raw string) (permission, error) {
+	parts := strings.Split(raw, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return permission{}, fmt.Errorf("malformed permission %q, want resource:action[:own]", raw)
+	}
+	perm := permission{raw: raw, resource: parts[0], action: parts[1]}
+	if len(parts) == 3 {
+		if parts[2] != "own" {
+			return permission{}, fmt.Errorf("malformed permission %q, only :own is allowed as qualifier", raw)
+		}
+		perm.own = true
+	}
+	return perm, nil
+}
@@ -0,0 +1,78 @@
+package rbac
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePolicy writes data to a policy file in a temp dir, name picks the format
+func writePolicy(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCompileFlattensInheritance(t *testing.T) {
+	policy := &Policy{Roles: map[string]Role{
+		"guest":    {Permissions: []string{"users:read:own"}},
+		"customer": {Inherits: []string{"guest"}, Permissions: []string{"orders:read:own"}},
+		"support":  {Inherits: []string{"customer", "guest"}, Permissions: []string{"orders:read"}},
+	}}
+	c, err := policy.compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, perm := range c["support"] {
+		got = append(got, perm.raw)
+	}
+	if want := "orders:read orders:read:own users:read:own users:read:own"; strings.Join(got, " ") != want {
+		t.Errorf("support = %v; want %s", got, want)
+	}
+}
+
+func TestCompileRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles map[string]Role
+		err   string
+	}{
+		{"self cycle", map[string]Role{"a": {Inherits: []string{"a"}}}, "cycle a -> a"},
+		{"cycle", map[string]Role{
+			"a": {Inherits: []string{"b"}},
+			"b": {Inherits: []string{"c"}},
+			"c": {Inherits: []string{"a"}},
+		}, "inheritance cycle"},
+		{"unknown parent", map[string]Role{"a": {Inherits: []string{"ghost"}}}, `unknown role "ghost"`},
+		{"no action", map[string]Role{"a": {Permissions: []string{"orders"}}}, "malformed permission"},
+		{"bad qualifier", map[string]Role{"a": {Permissions: []string{"orders:read:all"}}}, "only :own"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&Policy{Roles: tt.roles}).compile()
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("err = %v; want it to mention %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestLoadPolicyFormats(t *testing.T) {
+	yamlPath := writePolicy(t, "policy.yaml", "roles:\n  admin:\n    permissions: [\"users:*\"]\n")
+	jsonPath := writePolicy(t, "policy.json", `{"roles": {"admin": {"permissions": ["users:*"]}}}`)
+	for _, path := range []string{yamlPath, jsonPath} {
+		policy, err := LoadPolicy(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perms := policy.Roles["admin"].Permissions; len(perms) != 1 || perms[0] != "users:*" {
+			t.Errorf("%s: admin = %v", filepath.Base(path), perms)
+		}
+	}
+}
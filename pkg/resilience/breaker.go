@@ -0,0 +1,122 @@
+package resilience
+
+import (
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+type BreakerConfig struct {
+	// FailureThreshold consecutive failures open the breaker, 0 disables circuit breaking
+	FailureThreshold int
+	// OpenTimeout is how long an open breaker fails calls fast before letting a probe through
+	OpenTimeout time.Duration
+	// FailureCodes count as failures, DefaultFailureCodes when empty. Other codes are the caller's fault, not the server's
+	FailureCodes []codes.Code
+}
+
+var DefaultFailureCodes = []codes.Code{codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown, codes.ResourceExhausted}
+
+type BreakerState int
+
+const (
+	StateClosed BreakerState = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+/*
+breaker is the circuit breaker of one method.
+- closed: calls go through, FailureThreshold consecutive failures open it
+- open: calls fail fast with Unavailable until OpenTimeout passed
+- half-open: a single probe call goes through, its success closes the breaker and its failure opens it again
+*/
+type breaker struct {
+	cfg BreakerConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// allow reports whether an attempt may be made now
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.cfg.OpenTimeout {
+			return false
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		return true
+	case StateHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+func (b *breaker) record(code codes.Code) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// A cancelled call (e.g. a losing hedge) says nothing about the server, it only frees the probe slot
+	if code == codes.Canceled {
+		b.probing = false
+		return
+	}
+
+	failed := false
+	for _, c := range b.failureCodes() {
+		if c == code {
+			failed = true
+		}
+	}
+
+	if !failed {
+		b.state = StateClosed
+		b.failures = 0
+		b.probing = false
+		return
+	}
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.cfg.FailureThreshold {
+		b.state = StateOpen
+		b.openedAt = b.now()
+		b.probing = false
+	}
+}
+
+func (b *breaker) failureCodes() []codes.Code {
+	if len(b.cfg.FailureCodes) > 0 {
+		return b.cfg.FailureCodes
+	}
+	return DefaultFailureCodes
+}
+
+func (b *breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
@@ -0,0 +1,341 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"pkg/mdkit"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxAttempts is gRPC's own upper bound, larger configured values are capped to it
+const maxAttempts = 5
+
+// retryPushback is the server's retry pushback from the gRPC retry design, milliseconds or negative for "don't retry"
+var retryPushback = mdkit.IntKey("grpc-retry-pushback-ms")
+
+var errCircuitOpen = errors.New("circuit breaker open")
+
+/*
+Client holds the retry, hedging and circuit breaker state shared by every call of a connection.
+- Unary calls follow the retryPolicy or hedgingPolicy of their method in the service config
+- Every attempt, hedges included, goes through the per-method circuit breaker, an open breaker fails fast with Unavailable
+- Streams only get the circuit breaker, a half-consumed stream can't be replayed
+*/
+type Client struct {
+	config  *ServiceConfig
+	rawJSON string
+	breaker BreakerConfig
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+func New(serviceConfig string, breakerConfig BreakerConfig) (*Client, error) {
+	sc, err := ParseServiceConfig(serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{config: sc, rawJSON: serviceConfig, breaker: breakerConfig, breakers: make(map[string]*breaker)}, nil
+}
+
+// DialOptions installs the interceptors and the service config, with grpc-go's own retries off so attempts aren't multiplied.
+func (c *Client) DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithDefaultServiceConfig(c.rawJSON),
+		grpc.WithDisableRetry(),
+		grpc.WithChainUnaryInterceptor(c.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(c.StreamClientInterceptor()),
+	}
+}
+
+// BreakerState is the circuit breaker state of a full method name.
+func (c *Client) BreakerState(method string) BreakerState {
+	if b := c.breakerFor(method); b != nil {
+		return b.State()
+	}
+	return StateClosed
+}
+
+func (c *Client) breakerFor(method string) *breaker {
+	if c.breaker.FailureThreshold <= 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b, ok := c.breakers[method]
+	if !ok {
+		b = &breaker{cfg: c.breaker, now: time.Now}
+		c.breakers[method] = b
+	}
+	return b
+}
+
+type attemptsOption struct {
+	grpc.EmptyCallOption
+	n *int
+}
+
+// Attempts stores the number of attempts a call took in n once it returns.
+func Attempts(n *int) grpc.CallOption {
+	return attemptsOption{n: n}
+}
+
+// call is one unary call with the caller's header/trailer options split off, each attempt gets its own
+type call struct {
+	method       string
+	req          any
+	cc           *grpc.ClientConn
+	invoker      grpc.UnaryInvoker
+	opts         []grpc.CallOption
+	breaker      *breaker
+	header       *metadata.MD
+	trailer      *metadata.MD
+	attemptsAddr *int
+}
+
+type attemptResult struct {
+	reply   any
+	header  metadata.MD
+	trailer metadata.MD
+	err     error
+}
+
+func (c *Client) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		cl := &call{method: method, req: req, cc: cc, invoker: invoker, breaker: c.breakerFor(method)}
+		for _, opt := range opts {
+			switch o := opt.(type) {
+			case grpc.HeaderCallOption:
+				cl.header = o.HeaderAddr
+			case grpc.TrailerCallOption:
+				cl.trailer = o.TrailerAddr
+			case attemptsOption:
+				cl.attemptsAddr = o.n
+			default:
+				cl.opts = append(cl.opts, opt)
+			}
+		}
+
+		var policy *MethodConfig
+		if c.config != nil {
+			policy = c.config.lookup(method)
+		}
+
+		var attempts int
+		var res attemptResult
+		msg, isProto := reply.(proto.Message)
+		if policy != nil && policy.Hedging != nil && isProto {
+			attempts, res = cl.hedge(ctx, policy.Hedging, msg)
+		} else {
+			var retry *RetryPolicy
+			if policy != nil {
+				retry = policy.Retry
+			}
+			attempts, res = cl.retry(ctx, retry, reply)
+		}
+
+		if cl.header != nil {
+			*cl.header = res.header
+		}
+		if cl.trailer != nil {
+			*cl.trailer = metadata.Join(res.trailer, mdkit.Attempts.Pairs(int64(attempts)))
+		}
+		if cl.attemptsAddr != nil {
+			*cl.attemptsAddr = attempts
+		}
+		return res.err
+	}
+}
+
+func (cl *call) attempt(ctx context.Context, n int, reply any) attemptResult {
+	if cl.breaker != nil && !cl.breaker.allow() {
+		return attemptResult{err: status.Errorf(codes.Unavailable, "%v for %s", errCircuitOpen, cl.method)}
+	}
+
+	res := attemptResult{reply: reply}
+	ctx = mdkit.Attempt.AppendOutgoing(ctx, int64(n))
+	opts := append(cl.opts[:len(cl.opts):len(cl.opts)], grpc.Header(&res.header), grpc.Trailer(&res.trailer))
+	res.err = cl.invoker(ctx, cl.method, cl.req, reply, cl.cc, opts...)
+
+	if cl.breaker != nil {
+		cl.breaker.record(status.Code(res.err))
+	}
+	return res
+}
+
+func (cl *call) retry(ctx context.Context, policy *RetryPolicy, reply any) (int, attemptResult) {
+	for n := 1; ; n++ {
+		res := cl.attempt(ctx, n, reply)
+		if res.err == nil || policy == nil || n >= min(policy.MaxAttempts, maxAttempts) {
+			return n, res
+		}
+		if isCircuitOpen(res.err) || !containsCode(policy.RetryableStatusCodes, status.Code(res.err)) {
+			return n, res
+		}
+
+		wait := backoff(policy, n)
+		if pushback, ok := serverPushback(res.trailer); ok {
+			if pushback < 0 {
+				return n, res
+			}
+			wait = pushback
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return n, res
+		case <-timer.C:
+		}
+	}
+}
+
+/*
+hedge sends the call again every HedgingDelay until one attempt succeeds or MaxAttempts are in flight.
+- A non-fatal failure sends the next hedge right away, any other failure ends the call with it
+- Once a call is decided the remaining attempts are cancelled
+*/
+func (cl *call) hedge(ctx context.Context, policy *HedgingPolicy, reply proto.Message) (int, attemptResult) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	limit := min(policy.MaxAttempts, maxAttempts)
+	results := make(chan attemptResult, limit)
+	launched, pending := 0, 0
+	launch := func() {
+		launched++
+		pending++
+		n := launched
+		attemptReply := proto.Clone(reply)
+		proto.Reset(attemptReply)
+		go func() { results <- cl.attempt(ctx, n, attemptReply) }()
+	}
+
+	launch()
+	timer := time.NewTimer(time.Duration(policy.HedgingDelay))
+	defer timer.Stop()
+
+	var last attemptResult
+	for {
+		select {
+		case <-timer.C:
+			if launched < limit {
+				launch()
+				timer.Reset(time.Duration(policy.HedgingDelay))
+			}
+
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				proto.Reset(reply)
+				proto.Merge(reply, res.reply.(proto.Message))
+				return launched, res
+			}
+			last = res
+			if isCircuitOpen(res.err) || !containsCode(policy.NonFatalStatusCodes, status.Code(res.err)) {
+				return launched, res
+			}
+			if launched < limit {
+				launch()
+				timer.Reset(time.Duration(policy.HedgingDelay))
+			} else if pending == 0 {
+				return launched, last
+			}
+		}
+	}
+}
+
+// backoff is the randomized exponential backoff of the gRPC retry design, before attempt n+1
+func backoff(policy *RetryPolicy, n int) time.Duration {
+	ceiling := float64(policy.InitialBackoff) * math.Pow(policy.BackoffMultiplier, float64(n-1))
+	ceiling = min(ceiling, float64(policy.MaxBackoff))
+	return time.Duration(rand.Float64() * ceiling)
+}
+
+func serverPushback(trailer metadata.MD) (time.Duration, bool) {
+	ms, err := retryPushback.Get(trailer)
+	if errors.Is(err, mdkit.ErrMissing) {
+		return 0, false
+	}
+	if err != nil {
+		return -1, true
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+func isCircuitOpen(err error) bool {
+	st, ok := status.FromError(err)
+	return ok && st.Code() == codes.Unavailable && strings.HasPrefix(st.Message(), errCircuitOpen.Error())
+}
+
+func (c *Client) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		b := c.breakerFor(method)
+		if b == nil {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+		if !b.allow() {
+			return nil, status.Errorf(codes.Unavailable, "%v for %s", errCircuitOpen, method)
+		}
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			b.record(status.Code(err))
+			return nil, err
+		}
+
+		s := &breakerStream{ClientStream: cs, breaker: b, serverStreams: desc.ServerStreams}
+		// A stream the caller gives up on still has to report back, or a half-open probe would hold its slot forever.
+		// cs.Context() is not used here: it also ends when the stream finishes, racing the real status out of RecvMsg.
+		s.stop = context.AfterFunc(ctx, func() { s.finish(status.FromContextError(ctx.Err()).Code()) })
+		return s, nil
+	}
+}
+
+/*
+breakerStream records the final status of a stream exactly once, whichever comes first of
+- the first error out of RecvMsg, io.EOF counting as OK
+- the response of a stream without server streaming, e.g. a successful CloseAndRecv
+- the caller's context ending, so abandoned streams count as cancelled or timed out
+*/
+type breakerStream struct {
+	grpc.ClientStream
+	breaker       *breaker
+	serverStreams bool
+	once          sync.Once
+	stop          func() bool
+}
+
+func (s *breakerStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == io.EOF:
+		s.finish(codes.OK)
+	case err != nil:
+		s.finish(status.Code(err))
+	case !s.serverStreams:
+		s.finish(codes.OK)
+	default:
+		return nil
+	}
+	s.stop()
+	return err
+}
+
+func (s *breakerStream) finish(code codes.Code) {
+	s.once.Do(func() { s.breaker.record(code) })
+}
@@ -0,0 +1,295 @@
+package resilience
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"pkg/grpctest"
+	"pkg/mdkit"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// faultServer is a health service whose Check answers follow a script, one fault per call it receives
+type faultServer struct {
+	healthpb.UnimplementedHealthServer
+
+	mu       sync.Mutex
+	script   []fault
+	calls    int
+	attempts []string // x-attempt of every call received
+}
+
+type fault struct {
+	delay    time.Duration
+	code     codes.Code
+	pushback string
+}
+
+func (f *faultServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	f.mu.Lock()
+	var next fault
+	if f.calls < len(f.script) {
+		next = f.script[f.calls]
+	}
+	f.calls++
+	md, _ := metadata.FromIncomingContext(ctx)
+	f.attempts = append(f.attempts, md.Get(mdkit.Attempt.Name())...)
+	f.mu.Unlock()
+
+	select {
+	case <-time.After(next.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if next.pushback != "" {
+		grpc.SetTrailer(ctx, metadata.Pairs(retryPushback.Name(), next.pushback))
+	}
+	if next.code != codes.OK {
+		return nil, status.Error(next.code, "injected fault")
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (f *faultServer) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+const retryConfig = `{"methodConfig": [{
+	"name": [{"service": "grpc.health.v1.Health"}],
+	"retryPolicy": {
+		"maxAttempts": 4,
+		"initialBackoff": "0.01s",
+		"maxBackoff": "0.05s",
+		"backoffMultiplier": 2,
+		"retryableStatusCodes": ["UNAVAILABLE"]
+	}
+}]}`
+
+const hedgingConfig = `{"methodConfig": [{
+	"name": [{"service": "grpc.health.v1.Health", "method": "Check"}],
+	"hedgingPolicy": {
+		"maxAttempts": 3,
+		"hedgingDelay": "0.05s",
+		"nonFatalStatusCodes": ["UNAVAILABLE"]
+	}
+}]}`
+
+func newFaultClient(t *testing.T, serviceConfig string, breaker BreakerConfig, script ...fault) (healthpb.HealthClient, *faultServer, *Client) {
+	t.Helper()
+
+	fs := &faultServer{script: script}
+	client, err := New(serviceConfig, breaker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpctest.Start(t, func(s *grpc.Server) { healthpb.RegisterHealthServer(s, fs) },
+		grpctest.WithDialOptions(client.DialOptions()...))
+	return grpctest.NewClient(srv, healthpb.NewHealthClient), fs, client
+}
+
+func TestRetryUntilSuccess(t *testing.T) {
+	health, fs, _ := newFaultClient(t, retryConfig, BreakerConfig{},
+		fault{code: codes.Unavailable}, fault{code: codes.Unavailable}, fault{})
+
+	var attempts int
+	var trailer metadata.MD
+	_, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{}, Attempts(&attempts), grpc.Trailer(&trailer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := mdkit.Attempts.Get(trailer); attempts != 3 || n != 3 {
+		t.Errorf("attempts = %d, trailer %d, %v; want 3", attempts, n, err)
+	}
+	if got := fs.attempts; len(got) != 3 || got[0] != "1" || got[2] != "3" {
+		t.Errorf("server saw attempts %v; want 1, 2, 3", got)
+	}
+}
+
+func TestRetryStops(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   []fault
+		want     codes.Code
+		attempts int
+	}{
+		{"non-retryable code", []fault{{code: codes.InvalidArgument}, {}}, codes.InvalidArgument, 1},
+		{"max attempts", []fault{{code: codes.Unavailable}, {code: codes.Unavailable}, {code: codes.Unavailable}, {code: codes.Unavailable}, {}}, codes.Unavailable, 4},
+		{"server pushback", []fault{{code: codes.Unavailable, pushback: "-1"}, {}}, codes.Unavailable, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health, _, _ := newFaultClient(t, retryConfig, BreakerConfig{}, tt.script...)
+
+			var attempts int
+			_, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{}, Attempts(&attempts))
+			if status.Code(err) != tt.want || attempts != tt.attempts {
+				t.Errorf("err = %v after %d attempts; want %s after %d", err, attempts, tt.want, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestHedgingBeatsSlowAttempt(t *testing.T) {
+	health, _, _ := newFaultClient(t, hedgingConfig, BreakerConfig{}, fault{delay: 2 * time.Second}, fault{})
+
+	var attempts int
+	var header metadata.MD
+	start := time.Now()
+	res, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{}, Attempts(&attempts), grpc.Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("hedged call took %s; want the second attempt's answer", elapsed)
+	}
+	if attempts != 2 || res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("attempts = %d, status %s; want SERVING after 2", attempts, res.GetStatus())
+	}
+}
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	breaker := BreakerConfig{FailureThreshold: 3, OpenTimeout: 100 * time.Millisecond}
+	failures := []fault{{code: codes.Unavailable}, {code: codes.Unavailable}, {code: codes.Unavailable}}
+	health, fs, client := newFaultClient(t, `{}`, breaker, failures...)
+	method := healthpb.Health_Check_FullMethodName
+
+	for range 3 {
+		health.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	}
+	if client.BreakerState(method) != StateOpen {
+		t.Fatalf("breaker %s after 3 failures; want open", client.BreakerState(method))
+	}
+
+	_, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if status.Code(err) != codes.Unavailable || fs.callCount() != 3 {
+		t.Errorf("open breaker: err = %v, server calls %d; want Unavailable without reaching the server", err, fs.callCount())
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if _, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("half-open probe: %v", err)
+	}
+	if client.BreakerState(method) != StateClosed {
+		t.Errorf("breaker %s after a successful probe; want closed", client.BreakerState(method))
+	}
+}
+
+func TestParseServiceConfig(t *testing.T) {
+	sc, err := ParseServiceConfig(retryConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	retry := sc.lookup("/grpc.health.v1.Health/Check").Retry
+	if retry.MaxAttempts != 4 || time.Duration(retry.InitialBackoff) != 10*time.Millisecond || codes.Code(retry.RetryableStatusCodes[0]) != codes.Unavailable {
+		t.Errorf("retry policy = %+v", retry)
+	}
+	if sc.lookup("/other.Service/Method") != nil {
+		t.Error("policy for grpc.health.v1.Health applied to another service")
+	}
+
+	for _, bad := range []string{
+		`{"methodConfig": [{"name": [{}], "retryPolicy": {"maxAttempts": 1}}]}`,
+		`{"methodConfig": [{"name": [{}], "retryPolicy": {"maxAttempts": 2, "initialBackoff": "1", "maxBackoff": "1s", "backoffMultiplier": 2, "retryableStatusCodes": ["UNAVAILABLE"]}}]}`,
+	} {
+		if _, err := ParseServiceConfig(bad); err == nil {
+			t.Errorf("ParseServiceConfig(%s) accepted an invalid policy", bad)
+		}
+	}
+	if got, _ := Code(codes.DeadlineExceeded).MarshalJSON(); string(got) != `"DEADLINE_EXCEEDED"` {
+		t.Errorf("DeadlineExceeded marshals to %s", got)
+	}
+}
+
+// fakeStream is a client stream whose RecvMsg returns err, nil ends a stream without server streaming
+type fakeStream struct {
+	grpc.ClientStream
+	err error
+}
+
+func (s *fakeStream) RecvMsg(m any) error {
+	return s.err
+}
+
+// halfOpenBreaker returns a client whose breaker for method lets the next call through as the half-open probe
+func halfOpenBreaker(t *testing.T, method string) *Client {
+	t.Helper()
+	client, err := New(`{}`, BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := client.breakerFor(method)
+	now := time.Now()
+	b.now = func() time.Time { return now }
+	b.record(codes.Unavailable)
+	now = now.Add(2 * time.Minute)
+	return client
+}
+
+func TestStreamProbeIsAlwaysRecorded(t *testing.T) {
+	const method = "/test.Service/Stream"
+	open := func(client *Client, ctx context.Context, desc *grpc.StreamDesc, err error) (grpc.ClientStream, error) {
+		return client.StreamClientInterceptor()(ctx, desc, nil, method, func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &fakeStream{err: err}, nil
+		})
+	}
+
+	t.Run("successful CloseAndRecv", func(t *testing.T) {
+		client := halfOpenBreaker(t, method)
+		cs, err := open(client, context.Background(), &grpc.StreamDesc{ClientStreams: true}, nil)
+		if err != nil {
+			t.Fatalf("probe: %v", err)
+		}
+		if err := cs.RecvMsg(nil); err != nil {
+			t.Fatal(err)
+		}
+		if client.BreakerState(method) != StateClosed {
+			t.Errorf("breaker %s after the probe's response; want closed", client.BreakerState(method))
+		}
+	})
+
+	t.Run("abandoned server stream", func(t *testing.T) {
+		client := halfOpenBreaker(t, method)
+		ctx, cancel := context.WithCancel(context.Background())
+		if _, err := open(client, ctx, &grpc.StreamDesc{ServerStreams: true}, nil); err != nil {
+			t.Fatalf("probe: %v", err)
+		}
+		if _, err := open(client, context.Background(), &grpc.StreamDesc{ServerStreams: true}, nil); status.Code(err) != codes.Unavailable {
+			t.Fatalf("second call while probing: %v; want Unavailable", err)
+		}
+
+		cancel()
+		deadline := time.Now().Add(time.Second)
+		for {
+			_, err := open(client, context.Background(), &grpc.StreamDesc{ServerStreams: true}, nil)
+			if err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("the abandoned probe still holds the half-open slot: %v", err)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	t.Run("server stream ends", func(t *testing.T) {
+		client := halfOpenBreaker(t, method)
+		cs, err := open(client, context.Background(), &grpc.StreamDesc{ServerStreams: true}, io.EOF)
+		if err != nil {
+			t.Fatalf("probe: %v", err)
+		}
+		cs.RecvMsg(nil)
+		if client.BreakerState(method) != StateClosed {
+			t.Errorf("breaker %s after io.EOF; want closed", client.BreakerState(method))
+		}
+	})
+}
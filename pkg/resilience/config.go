@@ -0,0 +1,158 @@
+package resilience
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+/*
+ServiceConfig is the part of the gRPC service config (https://github.com/grpc/grpc/blob/master/doc/service_config.md)
+this package acts on: per-method retryPolicy and hedgingPolicy.
+grpc-go implements retries itself but not hedging, and it can't tell the caller how many attempts a call took, so the
+Interceptor runs both policies and the built-in retries are switched off (see DialOptions).
+*/
+type ServiceConfig struct {
+	MethodConfig []MethodConfig `json:"methodConfig"`
+}
+
+type MethodConfig struct {
+	Name    []MethodName   `json:"name"`
+	Retry   *RetryPolicy   `json:"retryPolicy,omitempty"`
+	Hedging *HedgingPolicy `json:"hedgingPolicy,omitempty"`
+}
+
+// MethodName matches every method of Service when Method is empty, and every call when both are.
+type MethodName struct {
+	Service string `json:"service,omitempty"`
+	Method  string `json:"method,omitempty"`
+}
+
+type RetryPolicy struct {
+	MaxAttempts          int      `json:"maxAttempts"`
+	InitialBackoff       Duration `json:"initialBackoff"`
+	MaxBackoff           Duration `json:"maxBackoff"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier"`
+	RetryableStatusCodes []Code   `json:"retryableStatusCodes"`
+}
+
+// HedgingPolicy sends up to MaxAttempts copies of a call HedgingDelay apart, the first success wins.
+type HedgingPolicy struct {
+	MaxAttempts         int      `json:"maxAttempts"`
+	HedgingDelay        Duration `json:"hedgingDelay"`
+	NonFatalStatusCodes []Code   `json:"nonFatalStatusCodes"`
+}
+
+// ParseServiceConfig reads a service config JSON document, validating the policies the way gRPC does.
+func ParseServiceConfig(js string) (*ServiceConfig, error) {
+	var sc ServiceConfig
+	if err := json.Unmarshal([]byte(js), &sc); err != nil {
+		return nil, fmt.Errorf("service config: %w", err)
+	}
+
+	for _, mc := range sc.MethodConfig {
+		if mc.Retry != nil && mc.Hedging != nil {
+			return nil, fmt.Errorf("service config: %v has both retryPolicy and hedgingPolicy", mc.Name)
+		}
+		if r := mc.Retry; r != nil {
+			if r.MaxAttempts < 2 || r.InitialBackoff <= 0 || r.MaxBackoff <= 0 || r.BackoffMultiplier <= 0 || len(r.RetryableStatusCodes) == 0 {
+				return nil, fmt.Errorf("service config: invalid retryPolicy for %v", mc.Name)
+			}
+		}
+		if h := mc.Hedging; h != nil && h.MaxAttempts < 2 {
+			return nil, fmt.Errorf("service config: invalid hedgingPolicy for %v", mc.Name)
+		}
+	}
+	return &sc, nil
+}
+
+// lookup finds the config of a full method name ("/pkg.Service/Method"), the most specific name wins.
+func (sc *ServiceConfig) lookup(fullMethod string) *MethodConfig {
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+
+	var serviceMatch, defaultMatch *MethodConfig
+	for i := range sc.MethodConfig {
+		mc := &sc.MethodConfig[i]
+		for _, name := range mc.Name {
+			switch {
+			case name.Service == service && name.Method == method:
+				return mc
+			case name.Service == service && name.Method == "":
+				serviceMatch = mc
+			case name.Service == "" && name.Method == "":
+				defaultMatch = mc
+			}
+		}
+	}
+	if serviceMatch != nil {
+		return serviceMatch
+	}
+	return defaultMatch
+}
+
+// Duration is a service config duration, seconds with an "s" suffix such as "0.1s".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	seconds, err := strconv.ParseFloat(strings.TrimSuffix(s, "s"), 64)
+	if err != nil || !strings.HasSuffix(s, "s") {
+		return fmt.Errorf("invalid duration %q", s)
+	}
+	*d = Duration(seconds * float64(time.Second))
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatFloat(time.Duration(d).Seconds(), 'f', -1, 64) + "s")
+}
+
+// Code is a status code written by name, "UNAVAILABLE", as in service configs.
+type Code codes.Code
+
+func (c *Code) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	var code codes.Code
+	if err := code.UnmarshalJSON([]byte(`"` + strings.ToUpper(s) + `"`)); err != nil {
+		return err
+	}
+	*c = Code(code)
+	return nil
+}
+
+func (c Code) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.ToUpper(codeName(codes.Code(c))))
+}
+
+// codeName turns codes.Code's CamelCase String() into the service config SNAKE_CASE name
+func codeName(c codes.Code) string {
+	var b strings.Builder
+	var prev rune
+	for _, r := range c.String() {
+		if prev >= 'a' && prev <= 'z' && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+		prev = r
+	}
+	return b.String()
+}
+
+func containsCode(list []Code, c codes.Code) bool {
+	for _, code := range list {
+		if codes.Code(code) == c {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"tools/openapi"
+	"tools/protoload"
+
+	"github.com/getkin/kin-openapi/openapi2"
+)
+
+/*
+openapi finishes the Swagger file of protoc-gen-openapiv2 for serving.
+
+	go run tools/cmd/openapi -I proto -in /tmp/main.swagger.json -out docs -title "Gateway API" main.proto
+
+- The protos are compiled again to read their validate rules, which become schema constraints (minLength, pattern, minimum...)
+- Versioned packages repeat service names, their shared operation IDs get the proto package in front
+- -out gets swagger.json (Swagger 2.0) and openapi.json (OpenAPI 3)
+*/
+func main() {
+	importPaths := flag.String("I", ".", "comma separated import paths of the protos")
+	in := flag.String("in", "", "Swagger file written by protoc-gen-openapiv2")
+	out := flag.String("out", "docs", "output directory")
+	title := flag.String("title", "", "API title, protoc-gen-openapiv2 uses the proto file name")
+	version := flag.String("version", "", "API version")
+	flag.Parse()
+
+	if *in == "" || flag.NArg() == 0 {
+		log.Fatalln("usage: openapi -I proto -in main.swagger.json [-out docs] file.proto...")
+	}
+
+	files, err := protoload.Compile(context.Background(), strings.Split(*importPaths, ","), flag.Args()...)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	var doc openapi2.T
+	if err := json.Unmarshal(data, &doc); err != nil {
+		log.Fatalln(err)
+	}
+	if *title != "" {
+		doc.Info.Title = *title
+	}
+	if *version != "" {
+		doc.Info.Version = *version
+	}
+
+	if err := openapi.ApplyValidateRules(&doc, files); err != nil {
+		log.Fatalln(err)
+	}
+	openapi.QualifyOperationIDs(&doc, files)
+	v3, err := openapi.ToV3(&doc)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		log.Fatalln(err)
+	}
+	writeJSON(filepath.Join(*out, "swagger.json"), &doc)
+	writeJSON(filepath.Join(*out, "openapi.json"), v3)
+	log.Println("Wrote swagger.json and openapi.json to", *out)
+}
+
+func writeJSON(path string, v any) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		log.Fatalln(err)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"tools/protobreak"
+	"tools/protoload"
+)
+
+/*
+protobreak fails when proto files changed in a way that breaks peers built from their previous version.
+
+	go run ./cmd/protobreak -I proto main.proto            # working tree against HEAD
+	go run ./cmd/protobreak -against main -I proto main.proto
+	go run ./cmd/protobreak -old ../old -I proto main.proto # a copy of the old tree instead of git
+	go run ./cmd/protobreak -all                           # every proto/ directory of the repository
+
+- The old version comes from the git revision -against, or from -old, a directory laid out like the working directory
+- -all takes each directory named proto as import path and compares all .proto files below it, files deleted since the revision included
+- Exits with 1 on any breaking change, see protobreak.Compare for the rules
+*/
+func main() {
+	importPaths := flag.String("I", ".", "comma separated import paths of the protos")
+	against := flag.String("against", "HEAD", "git revision holding the old protos")
+	oldDir := flag.String("old", "", "directory holding the old protos instead of a git revision")
+	all := flag.Bool("all", false, "check every proto directory of the git repository")
+	flag.Parse()
+
+	var changes []protobreak.Change
+	switch {
+	case *all:
+		root := strings.TrimSpace(git(".", "rev-parse", "--show-toplevel"))
+		verifyRevision(root, *against)
+		for _, dir := range protoDirs(root) {
+			changes = append(changes, checkDir(root, dir, *against)...)
+		}
+	case flag.NArg() > 0:
+		old := openDir(*oldDir)
+		if *oldDir == "" {
+			verifyRevision(".", *against)
+			old = openGit(".", *against, "./")
+		}
+		changes = check(old, nil, strings.Split(*importPaths, ","), existing(old, strings.Split(*importPaths, ","), flag.Args()), flag.Args())
+	default:
+		log.Fatalln("usage: protobreak [-against REV | -old DIR] [-I proto] file.proto... | protobreak -all [-against REV]")
+	}
+
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+	if len(changes) > 0 {
+		fmt.Printf("%d breaking changes\n", len(changes))
+		os.Exit(1)
+	}
+}
+
+// check compiles both versions, old only gets oldFiles since a new file has no old version
+func check(old, new protoload.Open, importPaths, oldFiles, newFiles []string) []protobreak.Change {
+	if len(oldFiles) == 0 {
+		return nil
+	}
+	ctx := context.Background()
+	oldSet, err := protoload.CompileFrom(ctx, old, importPaths, oldFiles...)
+	if err != nil {
+		log.Fatalln("old protos:", err)
+	}
+	newSet, err := protoload.CompileFrom(ctx, new, importPaths, newFiles...)
+	if err != nil {
+		log.Fatalln("new protos:", err)
+	}
+	return protobreak.Compare(oldSet, newSet)
+}
+
+// checkDir compares the protos below dir, a path relative to root, with their versions at revision
+func checkDir(root, dir, revision string) []protobreak.Change {
+	var newFiles []string
+	err := filepath.WalkDir(filepath.Join(root, dir), func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(p) != ".proto" {
+			return err
+		}
+		rel, err := filepath.Rel(filepath.Join(root, dir), p)
+		newFiles = append(newFiles, filepath.ToSlash(rel))
+		return err
+	})
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	var oldFiles []string
+	for _, file := range strings.Split(git(root, "ls-tree", "-r", "--name-only", revision, "--", dir+"/"), "\n") {
+		if rel, ok := strings.CutPrefix(file, dir+"/"); ok && path.Ext(rel) == ".proto" {
+			oldFiles = append(oldFiles, rel)
+		}
+	}
+
+	// Deleted files can't be compiled anymore, their declarations count as removed
+	changes := check(openGit(root, revision, ""), openDir(root), []string{dir}, oldFiles, newFiles)
+	for i := range changes {
+		changes[i].File = path.Join(dir, changes[i].File)
+	}
+	return changes
+}
+
+// protoDirs lists the directories named proto below root that hold .proto files, relative to root
+func protoDirs(root string) []string {
+	var dirs []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		if d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if d.Name() != "proto" {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		matches, _ := filepath.Glob(filepath.Join(p, "*.proto"))
+		if len(matches) > 0 {
+			dirs = append(dirs, filepath.ToSlash(rel))
+		}
+		return filepath.SkipDir
+	})
+	if err != nil {
+		log.Fatalln(err)
+	}
+	slices.Sort(dirs)
+	return dirs
+}
+
+// existing keeps the files old has a version of
+func existing(old protoload.Open, importPaths, files []string) []string {
+	var found []string
+	for _, file := range files {
+		for _, importPath := range importPaths {
+			if r, err := old(filepath.Join(importPath, file)); err == nil {
+				r.Close()
+				found = append(found, file)
+				break
+			}
+		}
+	}
+	return found
+}
+
+func openDir(dir string) protoload.Open {
+	return func(p string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(dir, p))
+	}
+}
+
+// openGit reads files at revision, prefix "./" makes their paths relative to dir instead of the repository root
+func openGit(dir, revision, prefix string) protoload.Open {
+	return func(p string) (io.ReadCloser, error) {
+		cmd := exec.Command("git", "show", revision+":"+prefix+filepath.ToSlash(p))
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			// The revision is verified up front, so git only fails on a path it doesn't have
+			return nil, fmt.Errorf("%s at %s: %w", p, revision, fs.ErrNotExist)
+		}
+		return io.NopCloser(bytes.NewReader(out)), nil
+	}
+}
+
+func verifyRevision(dir, revision string) {
+	git(dir, "rev-parse", "--verify", "--quiet", revision+"^{commit}")
+}
+
+func git(dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		log.Fatalf("git %s: %v", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out))
+}
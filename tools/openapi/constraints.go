@@ -0,0 +1,205 @@
+package openapi
+
+import (
+	"github.com/envoyproxy/protoc-gen-validate/validate"
+	"github.com/getkin/kin-openapi/openapi2"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// constraints are the JSON schema keywords a field's validate rules translate to
+type constraints struct {
+	minLength    uint64
+	maxLength    *uint64
+	pattern      string
+	format       string
+	enum         []any
+	min, max     *float64
+	exclusiveMin bool
+	exclusiveMax bool
+	minItems     uint64
+	maxItems     *uint64
+	uniqueItems  bool
+	items        *constraints // rules of the elements of a repeated field
+}
+
+/*
+fieldConstraints reads the validate rules of field, required reports a message field that must be set.
+- string: len, min_len, max_len, pattern, in and the email/hostname/ip/uri/uuid formats
+- 32-bit integers and floats: gt, gte, lt, lte and in, 64-bit integers are JSON strings so their bounds are left out
+- repeated: min_items, max_items, unique and the rules of the items
+*/
+func fieldConstraints(field protoreflect.FieldDescriptor) (c constraints, required bool) {
+	rules, _ := proto.GetExtension(field.Options(), validate.E_Rules).(*validate.FieldRules)
+	if rules == nil {
+		return c, false
+	}
+	return rulesConstraints(rules), rules.GetMessage().GetRequired()
+}
+
+func rulesConstraints(rules *validate.FieldRules) constraints {
+	var c constraints
+	switch {
+	case rules.GetString_() != nil:
+		r := rules.GetString_()
+		if r.Len != nil {
+			c.minLength, c.maxLength = r.GetLen(), r.Len
+		}
+		if r.MinLen != nil {
+			c.minLength = r.GetMinLen()
+		}
+		if r.MaxLen != nil {
+			c.maxLength = r.MaxLen
+		}
+		c.pattern = r.GetPattern()
+		for _, value := range r.GetIn() {
+			c.enum = append(c.enum, value)
+		}
+		switch {
+		case r.GetEmail():
+			c.format = "email"
+		case r.GetHostname():
+			c.format = "hostname"
+		case r.GetIpv4():
+			c.format = "ipv4"
+		case r.GetIpv6():
+			c.format = "ipv6"
+		case r.GetUri():
+			c.format = "uri"
+		case r.GetUriRef():
+			c.format = "uri-reference"
+		case r.GetUuid():
+			c.format = "uuid"
+		}
+	case rules.GetInt32() != nil:
+		r := rules.GetInt32()
+		setBounds(&c, r.Gt, r.Gte, r.Lt, r.Lte, r.GetIn())
+	case rules.GetUint32() != nil:
+		r := rules.GetUint32()
+		setBounds(&c, r.Gt, r.Gte, r.Lt, r.Lte, r.GetIn())
+	case rules.GetSint32() != nil:
+		r := rules.GetSint32()
+		setBounds(&c, r.Gt, r.Gte, r.Lt, r.Lte, r.GetIn())
+	case rules.GetFixed32() != nil:
+		r := rules.GetFixed32()
+		setBounds(&c, r.Gt, r.Gte, r.Lt, r.Lte, r.GetIn())
+	case rules.GetSfixed32() != nil:
+		r := rules.GetSfixed32()
+		setBounds(&c, r.Gt, r.Gte, r.Lt, r.Lte, r.GetIn())
+	case rules.GetFloat() != nil:
+		r := rules.GetFloat()
+		setBounds(&c, r.Gt, r.Gte, r.Lt, r.Lte, r.GetIn())
+	case rules.GetDouble() != nil:
+		r := rules.GetDouble()
+		setBounds(&c, r.Gt, r.Gte, r.Lt, r.Lte, r.GetIn())
+	case rules.GetRepeated() != nil:
+		r := rules.GetRepeated()
+		c.minItems = r.GetMinItems()
+		c.maxItems = r.MaxItems
+		c.uniqueItems = r.GetUnique()
+		if r.GetItems() != nil {
+			items := rulesConstraints(r.GetItems())
+			c.items = &items
+		}
+	}
+	return c
+}
+
+type number interface {
+	int32 | uint32 | float32 | float64
+}
+
+// setBounds takes the tighter of gt/gte and lt/lte, PGV reads lt below gt as "outside the range", which a schema can't say
+func setBounds[T number](c *constraints, gt, gte, lt, lte *T, in []T) {
+	for _, v := range in {
+		c.enum = append(c.enum, v)
+	}
+
+	lower, lowerExclusive := bound(gt, true)
+	if lower == nil {
+		lower, lowerExclusive = bound(gte, false)
+	}
+	upper, upperExclusive := bound(lt, true)
+	if upper == nil {
+		upper, upperExclusive = bound(lte, false)
+	}
+	if lower != nil && upper != nil && *upper < *lower {
+		return
+	}
+	c.min, c.exclusiveMin = lower, lowerExclusive
+	c.max, c.exclusiveMax = upper, upperExclusive
+}
+
+func bound[T number](value *T, exclusive bool) (*float64, bool) {
+	if value == nil {
+		return nil, false
+	}
+	f := float64(*value)
+	return &f, exclusive
+}
+
+func (c constraints) applySchema(s *openapi2.Schema) {
+	if c.minLength > 0 {
+		s.MinLength = c.minLength
+	}
+	if c.maxLength != nil {
+		s.MaxLength = c.maxLength
+	}
+	if c.pattern != "" {
+		s.Pattern = c.pattern
+	}
+	if c.format != "" {
+		s.Format = c.format
+	}
+	if len(c.enum) > 0 {
+		s.Enum = c.enum
+	}
+	if c.min != nil {
+		s.Min, s.ExclusiveMin = c.min, c.exclusiveMin
+	}
+	if c.max != nil {
+		s.Max, s.ExclusiveMax = c.max, c.exclusiveMax
+	}
+	if c.minItems > 0 {
+		s.MinItems = c.minItems
+	}
+	if c.maxItems != nil {
+		s.MaxItems = c.maxItems
+	}
+	if c.uniqueItems {
+		s.UniqueItems = true
+	}
+}
+
+func (c constraints) applyParameter(p *openapi2.Parameter) {
+	if c.minLength > 0 {
+		p.MinLength = c.minLength
+	}
+	if c.maxLength != nil {
+		p.MaxLength = c.maxLength
+	}
+	if c.pattern != "" {
+		p.Pattern = c.pattern
+	}
+	if c.format != "" {
+		p.Format = c.format
+	}
+	if len(c.enum) > 0 {
+		p.Enum = c.enum
+	}
+	if c.min != nil {
+		p.Minimum, p.ExclusiveMin = c.min, c.exclusiveMin
+	}
+	if c.max != nil {
+		p.Maximum, p.ExclusiveMax = c.max, c.exclusiveMax
+	}
+	if c.minItems > 0 {
+		p.MinItems = c.minItems
+	}
+	if c.maxItems != nil {
+		p.MaxItems = c.maxItems
+	}
+	if c.uniqueItems {
+		p.UniqueItems = true
+	}
+}
@@ -0,0 +1,237 @@
+package openapi
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+/*
+ApplyValidateRules copies protoc-gen-validate rules of the request messages into doc as schema constraints.
+- doc is the output of protoc-gen-openapiv2, see operations for how its operations are matched to methods
+- Body schemas and path and query parameters of each operation get the rules of the fields they map to, nested messages included
+- files must keep the custom options, protoload.Compile and protoc --include_imports --descriptor_set_out both do
+*/
+func ApplyValidateRules(doc *openapi2.T, files *protoregistry.Files) error {
+	seen := make(map[protoreflect.FullName]bool)
+	for _, o := range operations(doc, files) {
+		for _, param := range o.op.Parameters {
+			if err := applyParameter(doc, param, o.method, seen); err != nil {
+				return fmt.Errorf("openapi: %s %s: %w", o.verb, o.path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// QualifyOperationIDs prefixes operation IDs several methods share, like Greeter_Greet of main_api and main_api.v2, with their proto package
+func QualifyOperationIDs(doc *openapi2.T, files *protoregistry.Files) {
+	ops := operations(doc, files)
+	count := make(map[string]int)
+	for _, o := range ops {
+		count[o.op.OperationID]++
+	}
+	for _, o := range ops {
+		if count[o.op.OperationID] > 1 {
+			o.op.OperationID = string(o.method.Parent().ParentFile().Package()) + "." + o.op.OperationID
+		}
+	}
+}
+
+// ToV3 converts a Swagger 2.0 document to OpenAPI 3
+func ToV3(doc *openapi2.T) (*openapi3.T, error) {
+	return openapi2conv.ToV3(doc)
+}
+
+type operation struct {
+	verb, path string
+	op         *openapi2.Operation
+	method     protoreflect.MethodDescriptor
+}
+
+/*
+operations pairs the operations of doc with the methods they call.
+- An operation matches the method whose google.api.http rule (or one of its additional bindings) has its verb and path
+- Otherwise the default "Service_Method" operation ID decides, unless several methods share it
+*/
+func operations(doc *openapi2.T, files *protoregistry.Files) []operation {
+	routes := make(map[string]protoreflect.MethodDescriptor)
+	byID := make(map[string][]protoreflect.MethodDescriptor)
+	files.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		for i := range file.Services().Len() {
+			service := file.Services().Get(i)
+			for j := range service.Methods().Len() {
+				method := service.Methods().Get(j)
+				id := string(service.Name()) + "_" + string(method.Name())
+				byID[id] = append(byID[id], method)
+
+				rule, _ := proto.GetExtension(method.Options(), annotations.E_Http).(*annotations.HttpRule)
+				for _, binding := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+					if verb, path := route(binding); path != "" {
+						routes[verb+" "+path] = method
+					}
+				}
+			}
+		}
+		return true
+	})
+
+	var ops []operation
+	for _, path := range slices.Sorted(maps.Keys(doc.Paths)) {
+		for verb, op := range doc.Paths[path].Operations() {
+			method, ok := routes[verb+" "+path]
+			if !ok {
+				if candidates := byID[op.OperationID]; len(candidates) == 1 {
+					method, ok = candidates[0], true
+				}
+			}
+			if ok {
+				ops = append(ops, operation{verb: verb, path: path, op: op, method: method})
+			}
+		}
+	}
+	return ops
+}
+
+// route returns the verb and path template of rule the way Swagger writes them
+func route(rule *annotations.HttpRule) (string, string) {
+	switch pattern := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return http.MethodGet, pattern.Get
+	case *annotations.HttpRule_Put:
+		return http.MethodPut, pattern.Put
+	case *annotations.HttpRule_Post:
+		return http.MethodPost, pattern.Post
+	case *annotations.HttpRule_Delete:
+		return http.MethodDelete, pattern.Delete
+	case *annotations.HttpRule_Patch:
+		return http.MethodPatch, pattern.Patch
+	case *annotations.HttpRule_Custom:
+		return strings.ToUpper(pattern.Custom.GetKind()), pattern.Custom.GetPath()
+	}
+	return "", ""
+}
+
+func applyParameter(doc *openapi2.T, param *openapi2.Parameter, method protoreflect.MethodDescriptor, seen map[protoreflect.FullName]bool) error {
+	input := method.Input()
+
+	if param.In != "body" {
+		field := fieldByPath(input, param.Name)
+		if field == nil {
+			return fmt.Errorf("parameter %q is no field of %s", param.Name, input.FullName())
+		}
+		c, _ := fieldConstraints(field)
+		c.applyParameter(param)
+		return nil
+	}
+
+	if param.Schema == nil {
+		return nil
+	}
+	// The body is the whole request unless the http rule names one of its fields
+	rule, _ := proto.GetExtension(method.Options(), annotations.E_Http).(*annotations.HttpRule)
+	if body := rule.GetBody(); body != "" && body != "*" {
+		field := input.Fields().ByName(protoreflect.Name(body))
+		if field == nil || field.Message() == nil {
+			return nil
+		}
+		input = field.Message()
+	}
+	applyMessage(doc, param.Schema, input, seen)
+	return nil
+}
+
+// applyMessage adds the rules of msg's fields to the schema ref points to, definitions are only visited once
+func applyMessage(doc *openapi2.T, ref *openapi2.SchemaRef, msg protoreflect.MessageDescriptor, seen map[protoreflect.FullName]bool) {
+	schema := ref.Value
+	if ref.Ref != "" {
+		if seen[msg.FullName()] {
+			return
+		}
+		seen[msg.FullName()] = true
+		definition, ok := doc.Definitions[strings.TrimPrefix(ref.Ref, "#/definitions/")]
+		if !ok {
+			return
+		}
+		schema = definition.Value
+	}
+	if schema == nil {
+		return
+	}
+
+	for i := range msg.Fields().Len() {
+		field := msg.Fields().Get(i)
+		name := field.JSONName()
+		prop, ok := schema.Properties[name]
+		if !ok {
+			name = string(field.Name())
+			if prop, ok = schema.Properties[name]; !ok {
+				continue
+			}
+		}
+
+		c, required := fieldConstraints(field)
+		if required && !slices.Contains(schema.Required, name) {
+			schema.Required = append(schema.Required, name)
+		}
+		if field.IsMap() {
+			continue
+		}
+
+		target := prop
+		if field.IsList() {
+			if prop.Value != nil {
+				c.applySchema(prop.Value)
+			}
+			if target = itemsOf(prop); target == nil {
+				continue
+			}
+			items := c.items
+			c = constraints{}
+			if items != nil {
+				c = *items
+			}
+		}
+		if field.Message() != nil {
+			applyMessage(doc, target, field.Message(), seen)
+		} else if target.Value != nil {
+			c.applySchema(target.Value)
+		}
+	}
+}
+
+func itemsOf(prop *openapi2.SchemaRef) *openapi2.SchemaRef {
+	if prop.Value == nil {
+		return nil
+	}
+	return prop.Value.Items
+}
+
+// fieldByPath resolves a parameter name like "filter.min_price" or "filter.minPrice" to its field
+func fieldByPath(msg protoreflect.MessageDescriptor, path string) protoreflect.FieldDescriptor {
+	var field protoreflect.FieldDescriptor
+	for _, segment := range strings.Split(path, ".") {
+		if msg == nil {
+			return nil
+		}
+		field = msg.Fields().ByJSONName(segment)
+		if field == nil {
+			field = msg.Fields().ByName(protoreflect.Name(segment))
+		}
+		if field == nil {
+			return nil
+		}
+		msg = field.Message()
+	}
+	return field
+}
@@ -0,0 +1,220 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/envoyproxy/protoc-gen-validate/validate"
+	"github.com/getkin/kin-openapi/openapi2"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func field(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, rules *validate.FieldRules) *descriptorpb.FieldDescriptorProto {
+	f := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		JsonName: proto.String(jsonName(name)),
+		Number:   proto.Int32(number),
+		Type:     typ.Enum(),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Options:  &descriptorpb.FieldOptions{},
+	}
+	if rules != nil {
+		proto.SetExtension(f.Options, validate.E_Rules, rules)
+	}
+	return f
+}
+
+func jsonName(name string) string {
+	out := []byte{}
+	upper := false
+	for i := range len(name) {
+		switch {
+		case name[i] == '_':
+			upper = true
+		case upper:
+			out = append(out, name[i]-'a'+'A')
+			upper = false
+		default:
+			out = append(out, name[i])
+		}
+	}
+	return string(out)
+}
+
+func method(name, input string, rule *annotations.HttpRule) *descriptorpb.MethodDescriptorProto {
+	m := &descriptorpb.MethodDescriptorProto{
+		Name:       proto.String(name),
+		InputType:  proto.String(input),
+		OutputType: proto.String(input),
+		Options:    &descriptorpb.MethodOptions{},
+	}
+	proto.SetExtension(m.Options, annotations.E_Http, rule)
+	return m
+}
+
+// shopFile is a small shop.proto with validate rules on plain, nested and repeated fields, routed under /version
+func shopFile(pkg, version string) *descriptorpb.FileDescriptorProto {
+	tags := field("tags", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, &validate.FieldRules{Type: &validate.FieldRules_Repeated{Repeated: &validate.RepeatedRules{
+		MinItems: proto.Uint64(1),
+		Unique:   proto.Bool(true),
+		Items:    &validate.FieldRules{Type: &validate.FieldRules_String_{String_: &validate.StringRules{MaxLen: proto.Uint64(5)}}},
+	}}})
+	tags.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+
+	filter := field("filter", 2, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, &validate.FieldRules{Message: &validate.MessageRules{Required: proto.Bool(true)}})
+	filter.TypeName = proto.String("." + pkg + ".Filter")
+
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String(version + "/shop.proto"),
+		Package: proto.String(pkg),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Filter"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, &validate.FieldRules{Type: &validate.FieldRules_String_{String_: &validate.StringRules{
+						MinLen: proto.Uint64(2), MaxLen: proto.Uint64(10), Pattern: proto.String("^[a-z]+$"),
+					}}}),
+					tags,
+				},
+			},
+			{
+				Name: proto.String("ListRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("page_size", 1, descriptorpb.FieldDescriptorProto_TYPE_INT32, &validate.FieldRules{Type: &validate.FieldRules_Int32{Int32: &validate.Int32Rules{
+						Gt: proto.Int32(0), Lte: proto.Int32(100),
+					}}}),
+					filter,
+					field("email", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING, &validate.FieldRules{Type: &validate.FieldRules_String_{String_: &validate.StringRules{
+						WellKnown: &validate.StringRules_Email{Email: true},
+					}}}),
+				},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Shop"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				method("List", "."+pkg+".ListRequest", &annotations.HttpRule{Pattern: &annotations.HttpRule_Post{Post: "/" + version + "/list"}, Body: "*"}),
+				method("Search", "."+pkg+".ListRequest", &annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: "/" + version + "/search/{email}"}}),
+			},
+		}},
+	}
+}
+
+func shopFiles(t *testing.T, files ...*descriptorpb.FileDescriptorProto) *protoregistry.Files {
+	t.Helper()
+	registry, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: files})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return registry
+}
+
+// shopSwagger is the Swagger protoc-gen-openapiv2 writes for shop.proto
+const shopSwagger = `{
+  "swagger": "2.0",
+  "info": {"title": "shop.proto", "version": "version not set"},
+  "paths": {
+    "/v1/list": {"post": {
+      "operationId": "Shop_List",
+      "parameters": [{"name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/shopListRequest"}}],
+      "responses": {"200": {"description": "A successful response.", "schema": {"$ref": "#/definitions/shopListRequest"}}}
+    }},
+    "/v1/search/{email}": {"get": {
+      "operationId": "Shop_Search",
+      "parameters": [
+        {"name": "email", "in": "path", "required": true, "type": "string"},
+        {"name": "pageSize", "in": "query", "required": false, "type": "integer", "format": "int32"},
+        {"name": "filter.name", "in": "query", "required": false, "type": "string"}
+      ],
+      "responses": {"200": {"description": "A successful response.", "schema": {"$ref": "#/definitions/shopListRequest"}}}
+    }}
+  },
+  "definitions": {
+    "shopFilter": {"type": "object", "properties": {
+      "name": {"type": "string"},
+      "tags": {"type": "array", "items": {"type": "string"}}
+    }},
+    "shopListRequest": {"type": "object", "properties": {
+      "pageSize": {"type": "integer", "format": "int32"},
+      "filter": {"$ref": "#/definitions/shopFilter"},
+      "email": {"type": "string"}
+    }}
+  }
+}`
+
+func TestApplyValidateRules(t *testing.T) {
+	var doc openapi2.T
+	if err := json.Unmarshal([]byte(shopSwagger), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := ApplyValidateRules(&doc, shopFiles(t, shopFile("shop", "v1"))); err != nil {
+		t.Fatal(err)
+	}
+
+	request := doc.Definitions["shopListRequest"].Value
+	pageSize := request.Properties["pageSize"].Value
+	if pageSize.Min == nil || *pageSize.Min != 0 || !pageSize.ExclusiveMin || pageSize.Max == nil || *pageSize.Max != 100 || pageSize.ExclusiveMax {
+		t.Errorf("pageSize = min %v (exclusive %v), max %v (exclusive %v); want (0, 100]", pageSize.Min, pageSize.ExclusiveMin, pageSize.Max, pageSize.ExclusiveMax)
+	}
+	if got := request.Properties["email"].Value.Format; got != "email" {
+		t.Errorf("email format = %q; want email", got)
+	}
+	if len(request.Required) != 1 || request.Required[0] != "filter" {
+		t.Errorf("required = %v; want [filter]", request.Required)
+	}
+
+	filter := doc.Definitions["shopFilter"].Value
+	name := filter.Properties["name"].Value
+	if name.MinLength != 2 || name.MaxLength == nil || *name.MaxLength != 10 || name.Pattern != "^[a-z]+$" {
+		t.Errorf("filter name = minLength %d, maxLength %v, pattern %q", name.MinLength, name.MaxLength, name.Pattern)
+	}
+	tags := filter.Properties["tags"].Value
+	if tags.MinItems != 1 || !tags.UniqueItems || tags.Items.Value.MaxLength == nil || *tags.Items.Value.MaxLength != 5 {
+		t.Errorf("tags = minItems %d, unique %v, items %+v", tags.MinItems, tags.UniqueItems, tags.Items.Value)
+	}
+
+	params := doc.Paths["/v1/search/{email}"].Get.Parameters
+	if params[0].Format != "email" {
+		t.Errorf("path email format = %q; want email", params[0].Format)
+	}
+	if params[1].Maximum == nil || *params[1].Maximum != 100 {
+		t.Errorf("query pageSize maximum = %v; want 100", params[1].Maximum)
+	}
+	if params[2].MinLength != 2 || params[2].Pattern != "^[a-z]+$" {
+		t.Errorf("query filter.name = minLength %d, pattern %q", params[2].MinLength, params[2].Pattern)
+	}
+
+	v3, err := ToV3(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := v3.Components.Schemas["shopFilter"].Value.Properties["name"].Value
+	if schema.MinLength != 2 || schema.Pattern != "^[a-z]+$" {
+		t.Errorf("OpenAPI 3 filter name = minLength %d, pattern %q", schema.MinLength, schema.Pattern)
+	}
+}
+
+func TestQualifyOperationIDs(t *testing.T) {
+	var doc openapi2.T
+	if err := json.Unmarshal([]byte(shopSwagger), &doc); err != nil {
+		t.Fatal(err)
+	}
+	// protoc-gen-openapiv2 names the operations of both versions Shop_List
+	doc.Paths["/v2/list"] = doc.Paths["/v1/list"]
+	doc.Paths["/v1/list"] = &openapi2.PathItem{Post: &openapi2.Operation{OperationID: "Shop_List"}}
+
+	QualifyOperationIDs(&doc, shopFiles(t, shopFile("shop", "v1"), shopFile("shop.v2", "v2")))
+	for path, want := range map[string]string{"/v1/list": "shop.Shop_List", "/v2/list": "shop.v2.Shop_List"} {
+		if got := doc.Paths[path].Post.OperationID; got != want {
+			t.Errorf("%s operationId = %q; want %q", path, got, want)
+		}
+	}
+	if got := doc.Paths["/v1/search/{email}"].Get.OperationID; got != "Shop_Search" {
+		t.Errorf("operationId only in v1 = %q; want it unchanged", got)
+	}
+}
@@ -0,0 +1,183 @@
+package protobreak
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// Rule names the kind of a breaking change
+type Rule string
+
+const (
+	FieldRenumbered   Rule = "FIELD_RENUMBERED"
+	FieldTypeChanged  Rule = "FIELD_TYPE_CHANGED"
+	FieldRemoved      Rule = "FIELD_REMOVED"
+	EnumValueChanged  Rule = "ENUM_VALUE_CHANGED"
+	EnumValueRemoved  Rule = "ENUM_VALUE_REMOVED"
+	MethodRemoved     Rule = "METHOD_REMOVED"
+	MethodTypeChanged Rule = "METHOD_TYPE_CHANGED"
+	StreamingChanged  Rule = "STREAMING_CHANGED"
+)
+
+// Change is one edit that breaks peers still built from the old protos
+type Change struct {
+	Rule    Rule
+	Element protoreflect.FullName // the field, enum value or method
+	File    string                // path of the old file declaring Element
+	Message string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", c.File, c.Element, c.Message, c.Rule)
+}
+
+/*
+Compare reports the wire-incompatible changes from old to new, sorted by file and element.
+- Messages, enums and services are matched by full name, ones only in old or only in new are left alone, their fields and methods are what peers depend on
+- A field or enum value may be renamed or removed as long as its number is reserved, keeping a name on another number is renumbering
+- Field types have to stay exactly the same kind, message or enum, cardinality included, even where the wire format would tolerate a change (int32 to int64)
+- Methods may not disappear, change their request or response type or their client or server streaming
+*/
+func Compare(old, new *protoregistry.Files) []Change {
+	var changes []Change
+	old.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		c := comparison{new: new, file: file.Path()}
+		c.messages(file.Messages())
+		c.enums(file.Enums())
+		for i := range file.Services().Len() {
+			c.service(file.Services().Get(i))
+		}
+		changes = append(changes, c.changes...)
+		return true
+	})
+	slices.SortFunc(changes, func(a, b Change) int {
+		return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.Element, b.Element), cmp.Compare(a.Rule, b.Rule))
+	})
+	return changes
+}
+
+type comparison struct {
+	new     *protoregistry.Files
+	file    string
+	changes []Change
+}
+
+func (c *comparison) report(rule Rule, element protoreflect.FullName, format string, args ...any) {
+	c.changes = append(c.changes, Change{Rule: rule, Element: element, File: c.file, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *comparison) messages(messages protoreflect.MessageDescriptors) {
+	for i := range messages.Len() {
+		old := messages.Get(i)
+		c.messages(old.Messages())
+		c.enums(old.Enums())
+		if old.IsMapEntry() {
+			continue // compared as the type of their map field
+		}
+		desc, err := c.new.FindDescriptorByName(old.FullName())
+		if newMsg, ok := desc.(protoreflect.MessageDescriptor); err == nil && ok {
+			c.fields(old, newMsg)
+		}
+	}
+}
+
+func (c *comparison) fields(old, new protoreflect.MessageDescriptor) {
+	for i := range old.Fields().Len() {
+		field := old.Fields().Get(i)
+		if moved := new.Fields().ByName(field.Name()); moved != nil && moved.Number() != field.Number() {
+			c.report(FieldRenumbered, field.FullName(), "number changed from %d to %d", field.Number(), moved.Number())
+			continue
+		}
+		next := new.Fields().ByNumber(field.Number())
+		if next == nil {
+			if !new.ReservedRanges().Has(field.Number()) {
+				c.report(FieldRemoved, field.FullName(), "field %d removed without reserving its number", field.Number())
+			}
+			continue
+		}
+		if was, is := fieldType(field), fieldType(next); was != is {
+			c.report(FieldTypeChanged, field.FullName(), "field %d changed from %s to %s", field.Number(), was, is)
+		}
+	}
+}
+
+// fieldType spells out everything about a field's type the wire format depends on
+func fieldType(field protoreflect.FieldDescriptor) string {
+	switch {
+	case field.IsMap():
+		return fmt.Sprintf("map<%s, %s>", fieldType(field.MapKey()), fieldType(field.MapValue()))
+	case field.IsList():
+		return "repeated " + singularType(field)
+	}
+	return singularType(field)
+}
+
+func singularType(field protoreflect.FieldDescriptor) string {
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return string(field.Message().FullName())
+	case protoreflect.EnumKind:
+		return string(field.Enum().FullName())
+	}
+	return field.Kind().String()
+}
+
+func (c *comparison) enums(enums protoreflect.EnumDescriptors) {
+	for i := range enums.Len() {
+		old := enums.Get(i)
+		desc, err := c.new.FindDescriptorByName(old.FullName())
+		new, ok := desc.(protoreflect.EnumDescriptor)
+		if err != nil || !ok {
+			continue
+		}
+		for j := range old.Values().Len() {
+			value := old.Values().Get(j)
+			if moved := new.Values().ByName(value.Name()); moved != nil && moved.Number() != value.Number() {
+				c.report(EnumValueChanged, value.FullName(), "number changed from %d to %d", value.Number(), moved.Number())
+				continue
+			}
+			if new.Values().ByNumber(value.Number()) == nil && !new.ReservedRanges().Has(value.Number()) {
+				c.report(EnumValueRemoved, value.FullName(), "value %d removed without reserving its number", value.Number())
+			}
+		}
+	}
+}
+
+func (c *comparison) service(old protoreflect.ServiceDescriptor) {
+	desc, _ := c.new.FindDescriptorByName(old.FullName())
+	new, _ := desc.(protoreflect.ServiceDescriptor)
+	for i := range old.Methods().Len() {
+		method := old.Methods().Get(i)
+		var next protoreflect.MethodDescriptor
+		if new != nil {
+			next = new.Methods().ByName(method.Name())
+		}
+		if next == nil {
+			c.report(MethodRemoved, method.FullName(), "method removed")
+			continue
+		}
+		if method.Input().FullName() != next.Input().FullName() || method.Output().FullName() != next.Output().FullName() {
+			c.report(MethodTypeChanged, method.FullName(), "changed from (%s) returns (%s) to (%s) returns (%s)",
+				method.Input().FullName(), method.Output().FullName(), next.Input().FullName(), next.Output().FullName())
+		}
+		if was, is := streaming(method), streaming(next); was != is {
+			c.report(StreamingChanged, method.FullName(), "changed from %s to %s", was, is)
+		}
+	}
+}
+
+func streaming(method protoreflect.MethodDescriptor) string {
+	switch {
+	case method.IsStreamingClient() && method.IsStreamingServer():
+		return "bidirectional streaming"
+	case method.IsStreamingClient():
+		return "client streaming"
+	case method.IsStreamingServer():
+		return "server streaming"
+	}
+	return "unary"
+}
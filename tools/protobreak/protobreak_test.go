@@ -0,0 +1,141 @@
+package protobreak
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"tools/protoload"
+
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+func compile(t *testing.T, source string) *protoregistry.Files {
+	t.Helper()
+	open := func(path string) (io.ReadCloser, error) {
+		if path != "shop.proto" {
+			return nil, os.ErrNotExist
+		}
+		return io.NopCloser(strings.NewReader(source)), nil
+	}
+	files, err := protoload.CompileFrom(context.Background(), open, nil, "shop.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+const shopV1 = `syntax = "proto3";
+package shop;
+
+message Order {
+    string id = 1;
+    int32 quantity = 2;
+    string note = 3;
+    repeated string tags = 4;
+    Status status = 5;
+    map<string, int32> counts = 6;
+}
+
+enum Status {
+    STATUS_UNSPECIFIED = 0;
+    STATUS_OPEN = 1;
+    STATUS_CLOSED = 2;
+}
+
+service Orders {
+    rpc Get (Order) returns (Order);
+    rpc Watch (Order) returns (stream Order);
+    rpc Cancel (Order) returns (Order);
+}
+`
+
+func TestCompareCompatible(t *testing.T) {
+	// Renames, reserved removals and additions are fine on the wire
+	next := `syntax = "proto3";
+package shop;
+
+message Order {
+    reserved 3;
+    reserved "note";
+    string order_id = 1;
+    int32 quantity = 2;
+    repeated string tags = 4;
+    Status status = 5;
+    map<string, int32> counts = 6;
+    string currency = 7;
+}
+
+enum Status {
+    reserved 2;
+    STATUS_UNSPECIFIED = 0;
+    STATUS_ACTIVE = 1;
+    STATUS_REFUNDED = 3;
+}
+
+message Receipt {
+    string id = 1;
+}
+
+service Orders {
+    rpc Get (Order) returns (Order);
+    rpc Watch (Order) returns (stream Order);
+    rpc Cancel (Order) returns (Order);
+    rpc Pay (Order) returns (Receipt);
+}
+`
+	if changes := Compare(compile(t, shopV1), compile(t, next)); len(changes) != 0 {
+		t.Errorf("Compare() = %v; want no breaking changes", changes)
+	}
+}
+
+func TestCompareBreaking(t *testing.T) {
+	next := `syntax = "proto3";
+package shop;
+
+message Order {
+    string id = 1;
+    int64 quantity = 2;
+    string tags = 4;
+    Status status = 8;
+    map<string, int64> counts = 6;
+}
+
+enum Status {
+    STATUS_UNSPECIFIED = 0;
+    STATUS_OPEN = 3;
+}
+
+message Receipt {
+    string id = 1;
+}
+
+service Orders {
+    rpc Get (Order) returns (Receipt);
+    rpc Watch (stream Order) returns (stream Order);
+}
+`
+	want := []string{
+		"shop.proto: shop.Order.counts: field 6 changed from map<string, int32> to map<string, int64> (FIELD_TYPE_CHANGED)",
+		"shop.proto: shop.Order.note: field 3 removed without reserving its number (FIELD_REMOVED)",
+		"shop.proto: shop.Order.quantity: field 2 changed from int32 to int64 (FIELD_TYPE_CHANGED)",
+		"shop.proto: shop.Order.status: number changed from 5 to 8 (FIELD_RENUMBERED)",
+		"shop.proto: shop.Order.tags: field 4 changed from repeated string to string (FIELD_TYPE_CHANGED)",
+		"shop.proto: shop.Orders.Cancel: method removed (METHOD_REMOVED)",
+		"shop.proto: shop.Orders.Get: changed from (shop.Order) returns (shop.Order) to (shop.Order) returns (shop.Receipt) (METHOD_TYPE_CHANGED)",
+		"shop.proto: shop.Orders.Watch: changed from server streaming to bidirectional streaming (STREAMING_CHANGED)",
+		"shop.proto: shop.STATUS_CLOSED: value 2 removed without reserving its number (ENUM_VALUE_REMOVED)",
+		"shop.proto: shop.STATUS_OPEN: number changed from 1 to 3 (ENUM_VALUE_CHANGED)",
+	}
+
+	changes := Compare(compile(t, shopV1), compile(t, next))
+	var got []string
+	for _, change := range changes {
+		got = append(got, change.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Compare() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
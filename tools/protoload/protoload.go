@@ -0,0 +1,67 @@
+package protoload
+
+import (
+	"context"
+	"io"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+/*
+Compile parses .proto files the way protoc -I does, without protoc.
+- files are relative to one of importPaths, google/protobuf/*.proto are built in
+- The result holds files and everything they import
+- Custom options (validate rules, google.api.http, ...) are readable with proto.GetExtension as long as their Go package is linked in
+*/
+func Compile(ctx context.Context, importPaths []string, files ...string) (*protoregistry.Files, error) {
+	return CompileFrom(ctx, nil, importPaths, files...)
+}
+
+// Open reads the source of a proto file, the error for a missing one has to wrap fs.ErrNotExist
+type Open func(path string) (io.ReadCloser, error)
+
+// CompileFrom is Compile reading the sources through open instead of the file system, e.g. from an older git revision
+func CompileFrom(ctx context.Context, open Open, importPaths []string, files ...string) (*protoregistry.Files, error) {
+	compiler := protocompile.Compiler{
+		Resolver:       protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: importPaths, Accessor: open}),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+	compiled, err := compiler.Compile(ctx, files...)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	added := make(map[string]bool)
+	var add func(file protoreflect.FileDescriptor)
+	add = func(file protoreflect.FileDescriptor) {
+		if added[file.Path()] {
+			return
+		}
+		added[file.Path()] = true
+		for i := range file.Imports().Len() {
+			add(file.Imports().Get(i).FileDescriptor)
+		}
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(file))
+	}
+	for _, file := range compiled {
+		add(file)
+	}
+
+	// The compiler keeps options it has no Go type for as unknown fields, a round trip
+	// through the wire format resolves them against the registered extensions
+	data, err := proto.Marshal(set)
+	if err != nil {
+		return nil, err
+	}
+	resolved := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(data, resolved); err != nil {
+		return nil, err
+	}
+	return protodesc.NewFiles(resolved)
+}